@@ -1,17 +1,62 @@
 package main
 
 import (
+	"backend/internal/config"
+	"backend/internal/lifecycle"
 	"backend/internal/server"
+	"backend/internal/tracing"
 	"backend/loggers"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
 )
 
 func main() {
 
+	// load and validate the configuration, every problem is reported at once
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		loggers.Error.Fatalf("%v", err)
+	}
+	loggers.Setup(os.Stderr, loggers.Options{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+		Zone:   cfg.Logging.Zone,
+	})
+
 	// initialize the server
-	server := server.NewServer()
+	lc := lifecycle.New()
 
-	err := server.ListenAndServe()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		loggers.Error.Fatalf("Error starting server: %s", err)
+		loggers.Error.Fatalf("Error setting up tracing: %v", err)
+	}
+	// registered first so spans of the rest of the shutdown are still flushed
+	lc.OnShutdown("tracing", shutdownTracing)
+
+	server := server.NewServer(cfg, lc)
+
+	// bound before reporting ready, so readiness never precedes the port
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		loggers.Error.Fatalf("Error listening on %s: %v", server.Addr, err)
+	}
+	lc.Go("http server", func(ctx context.Context) error {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	// registered last so in-flight requests drain before workers and the database stop
+	lc.OnShutdown("http server", server.Shutdown)
+	lc.SetDrainDelay(cfg.ShutdownDrainDelay)
+	lc.MarkReady()
+
+	// block until SIGINT/SIGTERM, then shut down gracefully
+	if err := lc.Run(cfg.ShutdownTimeout); err != nil {
+		loggers.Error.Fatalf("Error running server: %v", err)
 	}
+	loggers.Info.Println("Server stopped")
 }
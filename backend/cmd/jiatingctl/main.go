@@ -0,0 +1,97 @@
+// Command jiatingctl runs operational tasks against the database and bucket
+// the API uses: managing admins, importing and exporting events, storage
+// maintenance, migrations and health checks. Run it without arguments for
+// the list of commands, -json prints results for scripts and failures as
+// {"error": "..."}, the exit code is 1 either way.
+package main
+
+import (
+	"backend/internal/config"
+	"backend/internal/ctl"
+	"backend/internal/database"
+	"backend/internal/s3service"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("jiatingctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, ctl.Usage) }
+	jsonOutput := flags.Bool("json", false, "print results as JSON")
+	configFile := flags.String("config", "", "env file to read configuration from (default .env)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var configArgs []string
+	if *configFile != "" {
+		configArgs = []string{"-config", *configFile}
+	}
+	cfg, err := config.Load(configArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jiatingctl: %v\n", err)
+		return 1
+	}
+	// only problems are logged, results go to stdout
+	loggers.Setup(os.Stderr, loggers.Options{Level: slog.LevelError, Format: cfg.Logging.Format, Zone: cfg.Logging.Zone})
+
+	app, closeDB, err := connect(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jiatingctl: %v\n", err)
+		return 1
+	}
+	defer closeDB()
+	app.Out = os.Stdout
+	app.JSON = *jsonOutput
+
+	err = app.Run(context.Background(), flags.Args())
+	switch {
+	case err == nil:
+		return 0
+	case ctl.IsUsageError(err):
+		fmt.Fprintf(os.Stderr, "jiatingctl: %v\n\n%s", err, ctl.Usage)
+		return 2
+	case errors.Is(err, ctl.ErrUnhealthy):
+		return 1
+	}
+	app.PrintError(err)
+	fmt.Fprintf(os.Stderr, "jiatingctl: %v\n", err)
+	return 1
+}
+
+// connect opens the database without migrating it, so migrate status shows
+// what the server would find, and the bucket the API serves
+func connect(cfg *config.Config) (*ctl.App, func() error, error) {
+	db, err := sql.Open("pgx", cfg.Database.DSN())
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening the database: %w", err)
+	}
+	objects, err := s3service.NewClient(cfg.S3)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("creating the S3 client: %w", err)
+	}
+	return &ctl.App{
+		Repos:   database.NewRepositories(db),
+		DB:      db,
+		Storage: s3service.NewService(cfg.S3),
+		Objects: objects,
+		Bucket:  cfg.S3.Bucket,
+	}, db.Close, nil
+}
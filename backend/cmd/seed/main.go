@@ -0,0 +1,108 @@
+// Command seed fills a development database and bucket with generated
+// admins, events with their authors and images, and photoshoot folders.
+//
+//	go run ./cmd/seed                      seed from the default options
+//	go run ./cmd/seed -wipe -seed 7        start over with another data set
+//	go run ./cmd/seed -out fixtures.json   only write the data set to a file
+//	go run ./cmd/seed -in fixtures.json    seed a data set written earlier
+//
+// The same -seed always generates the same data. Seeding only runs with
+// ENV=dev, point S3_ENDPOINT at a local S3-compatible server such as minio to
+// keep the photos off AWS.
+package main
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/s3service"
+	"backend/loggers"
+	"backend/misc"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	opts := misc.DefaultOptions()
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Int64Var(&opts.Seed, "seed", opts.Seed, "random seed, the same seed generates the same data")
+	flags.IntVar(&opts.Admins, "admins", opts.Admins, "admins to generate besides the founder")
+	flags.IntVar(&opts.Events, "events", opts.Events, "events to generate")
+	flags.IntVar(&opts.MaxImagesPerEvent, "images", opts.MaxImagesPerEvent, "most images per event")
+	flags.IntVar(&opts.Photoshoots, "photoshoots", opts.Photoshoots, "photoshoot folders to generate")
+	flags.IntVar(&opts.MaxPhotosPerShoot, "photos", opts.MaxPhotosPerShoot, "most photos per photoshoot")
+	wipe := flags.Bool("wipe", false, "delete all data and photoshoots before seeding")
+	in := flags.String("in", "", "seed the fixtures in this file instead of generating them")
+	out := flags.String("out", "", "write the generated fixtures to this file and exit")
+	configFile := flags.String("config", "", "env file to read configuration from (default .env)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fixtures := misc.Generate(opts)
+	if *in != "" {
+		var err error
+		if fixtures, err = misc.LoadFixtures(*in); err != nil {
+			return err
+		}
+	}
+	if *out != "" {
+		return fixtures.WriteFile(*out)
+	}
+
+	var configArgs []string
+	if *configFile != "" {
+		configArgs = []string{"-config", *configFile}
+	}
+	cfg, err := config.Load(configArgs)
+	if err != nil {
+		return err
+	}
+	loggers.Setup(os.Stderr, loggers.Options{Level: cfg.Logging.Level, Format: cfg.Logging.Format, Zone: cfg.Logging.Zone})
+	if cfg.Env != "dev" {
+		return errors.New("refusing to seed outside ENV=dev")
+	}
+	return seed(context.Background(), cfg, fixtures, *wipe)
+}
+
+func seed(ctx context.Context, cfg *config.Config, fixtures *misc.Fixtures, wipe bool) error {
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	storage, err := s3service.NewClient(cfg.S3)
+	if err != nil {
+		return err
+	}
+
+	if wipe {
+		if err := database.Wipe(ctx, db); err != nil {
+			return err
+		}
+		deleted, err := misc.WipePhotoshoots(ctx, storage, cfg.S3.Bucket)
+		if err != nil {
+			return fmt.Errorf("wiping photoshoots: %w", err)
+		}
+		loggers.Info.Printf("wiped the database and %d photos", deleted)
+	}
+
+	if err := fixtures.Apply(ctx, database.NewRepositories(db)); err != nil {
+		return err
+	}
+	if err := fixtures.Upload(ctx, storage, cfg.S3.Bucket); err != nil {
+		return err
+	}
+	loggers.Info.Printf("seeded %d admins, %d events and %d photos from seed %d",
+		len(fixtures.Admins), len(fixtures.Events), len(fixtures.Photoshoots), fixtures.Seed)
+	return nil
+}
@@ -1,30 +1,43 @@
 package auth
 
 import (
+	"backend/internal/config"
 	"backend/internal/database"
 	"backend/loggers"
+	"fmt"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/gorilla/sessions"
-	"github.com/joho/godotenv"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/openidConnect"
 )
 
-const (
-	key    = "random string for demo purposes only"
-	MaxAge = 86400 * 30 // 30 days
-	IsProd = false
-)
+const MaxAge = 86400 * 30 // 30 days
 
-// only need one clientID and clientSecret for now since we're only using Google
+// Providers lists the goth providers to enable: google, oidc and dev.
+// Callbacks are served at <CallbackBaseURL>/auth/<provider>/callback.
 type AuthConfig struct {
-	Store        *sessions.CookieStore
-	DB           database.Service
+	Store           *sessions.CookieStore
+	DB              database.Service
+	CallbackBaseURL string
+	// FrontendURL is where users land after signing in or out
+	FrontendURL string
+	Providers   []string
+
+	// google
 	ClientID     string
 	ClientSecret string
+
+	// generic OpenID Connect provider, endpoints are auto discovered
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCDiscoveryURL string
+
+	// signs invitation links, invitations are disabled while empty
+	InvitationSecret string
 }
 
 // SOLID: Interface Segregation Principle :)
@@ -34,65 +47,120 @@ type Service interface {
 	BeginAuthHandler() http.HandlerFunc
 	AuthMiddleware(next http.Handler) http.Handler
 	SessionInfoHandler() http.HandlerFunc
+	AcceptInvitationHandler() http.HandlerFunc
+	FounderMiddleware(next http.Handler) http.Handler
+	DevAuthorizeHandler() http.HandlerFunc
+	LinkIdentityHandler() http.HandlerFunc
+	CSRFTokenHandler() http.HandlerFunc
+	CSRFMiddleware(next http.Handler) http.Handler
 }
 
 type service struct {
 	store *sessions.CookieStore
 	db    database.Service
+
+	frontendURL      string
+	invitationSecret string
+
+	// provider unauthenticated users are sent to
+	loginProvider string
+	devLogin      bool
 }
 
 func NewAuth(config *AuthConfig) Service {
 	if config == nil {
 		loggers.Error.Fatal("Missing auth config")
 	}
+	if len(config.Providers) == 0 {
+		loggers.Error.Fatal("No OAuth provider enabled")
+	}
 
 	service := &service{
-		store: config.Store,
-		db:    config.DB,
+		store:            config.Store,
+		db:               config.DB,
+		frontendURL:      strings.TrimSuffix(config.FrontendURL, "/"),
+		invitationSecret: config.InvitationSecret,
+		loginProvider:    config.Providers[0],
 	}
 
-	setUpGoth(config.ClientID, config.ClientSecret)
-	return service
+	if err := setUpGoth(config); err != nil {
+		loggers.Error.Fatalf("Error setting up OAuth providers: %v", err)
+	}
+	for _, provider := range config.Providers {
+		if provider == devProviderName {
+			service.devLogin = true
+		}
+	}
 
-}
+	// setup gothic
+	gothic.Store = config.Store
 
-func setUpGoth(clientID, clientSecret string) {
-	goth.UseProviders(
-		google.New(
-			clientID, clientSecret, "http://localhost:3000/auth/google/callback",
-			"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile",
-		),
-	)
+	return service
 }
 
-func LoadAuthConfig(db database.Service) (*AuthConfig, error) {
-	err := godotenv.Load()
-	if err != nil {
-		loggers.Error.Fatal("Error loading .env file")
+func setUpGoth(config *AuthConfig) error {
+	callbackURL := func(provider string) string {
+		return strings.TrimSuffix(config.CallbackBaseURL, "/") + "/auth/" + provider + "/callback"
 	}
 
-	googleClientID := os.Getenv("GOOGLE_CLIENT_ID")
-	googleClientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	if googleClientID == "" || googleClientSecret == "" {
-		loggers.Error.Fatal("Missing Google Client ID or Client Secret")
+	var providers []goth.Provider
+	for _, name := range config.Providers {
+		switch name {
+		case "google":
+			providers = append(providers, google.New(
+				config.ClientID, config.ClientSecret, callbackURL("google"),
+				"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile",
+			))
+		case "oidc":
+			provider, err := openidConnect.New(
+				config.OIDCClientID, config.OIDCClientSecret, callbackURL("oidc"),
+				config.OIDCDiscoveryURL, "openid", "email", "profile",
+			)
+			if err != nil {
+				return fmt.Errorf("oidc provider: %w", err)
+			}
+			provider.SetName("oidc")
+			providers = append(providers, provider)
+		case devProviderName:
+			providers = append(providers, newDevProvider(callbackURL(devProviderName)))
+		default:
+			return fmt.Errorf("unknown provider %q", name)
+		}
 	}
 
-	// setup cookie store
-	store := sessions.NewCookieStore([]byte(key))
+	goth.UseProviders(providers...)
+	return nil
+}
+
+// NewAuthConfig builds the auth config from the validated app configuration
+func NewAuthConfig(cfg *config.Config, db database.Service) *AuthConfig {
+	// setup cookie store, signed with SESSION_SECRET
+	store := sessions.NewCookieStore([]byte(cfg.Auth.SessionSecret))
 	store.MaxAge(MaxAge)
 
 	// setup cookie options
 	store.Options.Path = "/"
 	store.Options.HttpOnly = true // HttpOnly should always be enabled
-	store.Options.Secure = IsProd
+	store.Options.Secure = cfg.Auth.CookieSecure
+	store.Options.SameSite = cfg.Auth.CookieSameSite
 
-	// setup gothic
-	gothic.Store = store
+	for _, provider := range cfg.Auth.Providers {
+		if provider == devProviderName {
+			loggers.Info.Println("Dev login enabled, anyone can sign in as any admin")
+		}
+	}
 
 	return &AuthConfig{
-		Store:        store,
-		DB:           db,
-		ClientID:     googleClientID,
-		ClientSecret: googleClientSecret,
-	}, nil
+		Store:            store,
+		DB:               db,
+		CallbackBaseURL:  cfg.Auth.CallbackBaseURL,
+		FrontendURL:      cfg.FrontendURL,
+		Providers:        cfg.Auth.Providers,
+		ClientID:         cfg.Auth.GoogleClientID,
+		ClientSecret:     cfg.Auth.GoogleClientSecret,
+		OIDCClientID:     cfg.Auth.OIDCClientID,
+		OIDCClientSecret: cfg.Auth.OIDCClientSecret,
+		OIDCDiscoveryURL: cfg.Auth.OIDCDiscoveryURL,
+		InvitationSecret: cfg.Auth.InvitationSecret,
+	}
 }
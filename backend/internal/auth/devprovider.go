@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+const devProviderName = "dev"
+
+// devProvider is a goth provider for local development and integration tests.
+// Its authorization page lets anyone pick the email to sign in with, so the
+// rest of the OAuth flow (state check, admin lookup, session) runs unchanged
+// without network access.
+type devProvider struct {
+	callbackURL  string
+	authorizeURL string
+}
+
+// devSession carries the email chosen on the authorization page
+type devSession struct {
+	AuthURL string
+	Email   string
+}
+
+func newDevProvider(callbackURL string) *devProvider {
+	return &devProvider{
+		callbackURL:  callbackURL,
+		authorizeURL: strings.TrimSuffix(callbackURL, "/callback") + "/authorize",
+	}
+}
+
+func (p *devProvider) Name() string        { return devProviderName }
+func (p *devProvider) SetName(name string) {}
+func (p *devProvider) Debug(debug bool)    {}
+
+func (p *devProvider) BeginAuth(state string) (goth.Session, error) {
+	return &devSession{AuthURL: p.authorizeURL + "?state=" + url.QueryEscape(state)}, nil
+}
+
+func (p *devProvider) UnmarshalSession(data string) (goth.Session, error) {
+	sess := &devSession{}
+	err := json.Unmarshal([]byte(data), sess)
+	return sess, err
+}
+
+func (p *devProvider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*devSession)
+	if sess.Email == "" {
+		return goth.User{}, errors.New("dev provider: no email chosen")
+	}
+	return goth.User{
+		Provider:    devProviderName,
+		UserID:      "dev:" + sess.Email,
+		Email:       sess.Email,
+		Name:        sess.Email,
+		AccessToken: "dev",
+		// trusted like a provider that verifies emails, it only runs in dev
+		RawData: map[string]interface{}{"email_verified": true},
+	}, nil
+}
+
+func (p *devProvider) RefreshTokenAvailable() bool { return false }
+
+func (p *devProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("dev provider: refresh tokens are not supported")
+}
+
+func (s *devSession) GetAuthURL() (string, error) { return s.AuthURL, nil }
+
+func (s *devSession) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// Authorize receives the email picked on the authorization page as the code
+func (s *devSession) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	s.Email = strings.ToLower(strings.TrimSpace(params.Get("code")))
+	if s.Email == "" {
+		return "", errors.New("dev provider: missing code")
+	}
+	return "dev", nil
+}
+
+// DevAuthorizeHandler stands in for the consent screen of the dev provider.
+// With an email query param it redirects straight to the callback, without
+// one it lists the admins to sign in as. It 404s unless the dev provider is enabled.
+func (s *service) DevAuthorizeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.devLogin {
+			http.NotFound(w, r)
+			return
+		}
+
+		state := r.URL.Query().Get("state")
+		if email := r.URL.Query().Get("email"); email != "" {
+			http.Redirect(w, r, "/auth/dev/callback?"+url.Values{"state": {state}, "code": {email}}.Encode(), http.StatusFound)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		admins, err := s.db.GetAllAdmins(ctx, 1, 100)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error getting admins: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		devAuthorizeTemplate.Execute(w, map[string]interface{}{"State": state, "Admins": admins})
+	}
+}
+
+var devAuthorizeTemplate = template.Must(template.New("dev-authorize").Parse(
+	`<!DOCTYPE html>
+<title>Dev login</title>
+<h1>Sign in as</h1>
+<ul>
+{{range .Admins}}<li><a href="?state={{$.State}}&amp;email={{.Email}}">{{.Name}} ({{.Email}})</a></li>
+{{end}}</ul>
+`))
@@ -1,13 +1,17 @@
 package auth
 
 import (
+	"backend/internal/models"
+	"backend/internal/tokens"
 	"backend/loggers"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 )
 
@@ -18,44 +22,81 @@ func (s *service) GetAuthCallbackHandler() http.HandlerFunc {
 		provider := chi.URLParam(r, "provider")
 
 		// add provider to the existing context
-		loggers.Debug.Printf("Adding provider %s to context", provider)
+		loggers.Debug.Ctx(r.Context()).Printf("Adding provider %s to context", provider)
 		newCtx := context.WithValue(r.Context(), "provider", provider)
 		r = r.WithContext(newCtx)
 
-		loggers.Debug.Println("Getting user from gothic...")
+		loggers.Debug.Ctx(r.Context()).Println("Getting user from gothic...")
 		user, err := gothic.CompleteUserAuth(w, r)
 		if err != nil {
-			loggers.Error.Printf("Error completing auth: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error completing auth: %v", err)
 			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
 			return
 		}
 
-		// check if authenticated user is an admin in the database
-		admin, err := s.db.GetAdmin(context.Background(), "email", user.Email)
+		// retrieve or create session
+		loggers.Debug.Ctx(r.Context()).Println("Retrieving session...")
+		session, err := s.store.Get(r, "session-name")
 		if err != nil {
-			if err == sql.ErrNoRows {
-				loggers.Debug.Printf("No admin found with email %v", user.Email)
-				// dandle non-admin user, redirect appropriately
-				http.Redirect(w, r, "/login-unauthorized", http.StatusSeeOther)
-				return
-			} else {
-				loggers.Error.Printf("Error getting admin: %v", err)
-				http.Redirect(w, r, "/login-error", http.StatusSeeOther)
+			loggers.Error.Ctx(r.Context()).Printf("Error retrieving session: %v", err)
+			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
+			return
+		}
+
+		// linking started by LinkIdentityHandler adds the account to the
+		// admin already signed in, the session itself is left as is
+		if adminID, ok := popLinkRequest(session.Values); ok {
+			err := s.db.LinkAdminIdentity(r.Context(), models.AdminIdentity{
+				AdminID: adminID, Provider: user.Provider, ProviderUserID: user.UserID, Email: user.Email,
+			})
+			session.Save(r, w)
+			if err != nil {
+				loggers.Debug.Ctx(r.Context()).Printf("Error linking %v account %v: %v", user.Provider, user.Email, err)
+				http.Redirect(w, r, "/link-error", http.StatusSeeOther)
 				return
 			}
+			http.Redirect(w, r, s.frontendURL+"/admin/dashboard", http.StatusFound)
+			return
 		}
 
-		loggers.Debug.Printf("Admin found: %v", admin)
+		// an invitation started by AcceptInvitationHandler creates the admin,
+		// otherwise the authenticated user must already be an admin in the database
+		var admin *models.Admin
+		if token, bind, ok := popInvitation(session.Values); ok {
+			admin, err = s.db.AcceptAdminInvitation(r.Context(), tokens.Hash(token), user.Email, bind)
+			if err != nil {
+				loggers.Debug.Ctx(r.Context()).Printf("Error accepting invitation for %v: %v", user.Email, err)
+				session.Save(r, w)
+				http.Redirect(w, r, "/invitation-invalid", http.StatusSeeOther)
+				return
+			}
+			loggers.Info.Ctx(r.Context()).Printf("Invitation accepted, admin %v created for %v", admin.ID, admin.Email)
 
-		// retrieve or create session
-		loggers.Debug.Println("Retrieving session...")
-		session, err := s.store.Get(r, "session-name")
-		if err != nil {
-			loggers.Error.Printf("Error retrieving session: %v", err)
-			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
-			return
+			// later logins resolve the admin by this account, not the email
+			if err := s.db.LinkAdminIdentity(r.Context(), models.AdminIdentity{
+				AdminID: admin.ID, Provider: user.Provider, ProviderUserID: user.UserID, Email: user.Email,
+			}); err != nil {
+				loggers.Error.Ctx(r.Context()).Printf("Error linking identity of new admin %v: %v", admin.ID, err)
+			}
+		} else {
+			// stable provider IDs first, a verified email as a fallback
+			admin, err = s.db.ResolveAdminLogin(r.Context(), user.Provider, user.UserID, user.Email, emailVerified(user))
+			if err != nil {
+				if err == sql.ErrNoRows || err.Error() == "admin not found" || err.Error() == "identity already linked to another admin" {
+					loggers.Debug.Ctx(r.Context()).Printf("No admin found for %v account %v", user.Provider, user.Email)
+					// dandle non-admin user, redirect appropriately
+					http.Redirect(w, r, "/login-unauthorized", http.StatusSeeOther)
+					return
+				} else {
+					loggers.Error.Ctx(r.Context()).Printf("Error getting admin: %v", err)
+					http.Redirect(w, r, "/login-error", http.StatusSeeOther)
+					return
+				}
+			}
 		}
 
+		loggers.Debug.Ctx(r.Context()).Printf("Admin found: %v", admin)
+
 		// store user and admin data in session
 
 		// google info
@@ -67,23 +108,26 @@ func (s *service) GetAuthCallbackHandler() http.HandlerFunc {
 		// postgres info
 		session.Values["adminID"] = admin.ID
 		session.Values["adminPosition"] = admin.Position
+		session.Values["issuedAt"] = time.Now().Unix()
+		// a new login gets a new csrf token
+		delete(session.Values, "csrfToken")
 
 		// save session
 		if err := session.Save(r, w); err != nil {
-			loggers.Error.Printf("Error saving session: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error saving session: %v", err)
 			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
 			return
 		}
 
 		// redirect to admin dashboard or another appropriate page
-		http.Redirect(w, r, "http://localhost:5173/admin/dashboard", http.StatusFound)
+		http.Redirect(w, r, s.frontendURL+"/admin/dashboard", http.StatusFound)
 	}
 }
 
 func (s *service) LogoutHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// clearing OAuth data
-		loggers.Debug.Println("Clearing OAuth data...")
+		loggers.Debug.Ctx(r.Context()).Println("Clearing OAuth data...")
 		provider := chi.URLParam(r, "provider")
 		r = r.WithContext(context.WithValue(r.Context(), "provider", provider))
 		gothic.Logout(w, r)
@@ -91,7 +135,7 @@ func (s *service) LogoutHandler() http.HandlerFunc {
 		// next, clear application session data
 		session, err := s.store.Get(r, "session-name")
 		if err == nil {
-			loggers.Debug.Println("Clearing application session data...")
+			loggers.Debug.Ctx(r.Context()).Println("Clearing application session data...")
 
 			// delete session data
 			session.Values["userID"] = nil
@@ -102,6 +146,8 @@ func (s *service) LogoutHandler() http.HandlerFunc {
 			// delete admin data
 			session.Values["adminID"] = nil
 			session.Values["adminPosition"] = nil
+			session.Values["issuedAt"] = nil
+			session.Values["csrfToken"] = nil
 
 			session.Options.MaxAge = -1
 			// save changes
@@ -109,7 +155,7 @@ func (s *service) LogoutHandler() http.HandlerFunc {
 		}
 
 		// redirect to homepage
-		http.Redirect(w, r, "http://localhost:5173", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, s.frontendURL, http.StatusTemporaryRedirect)
 	}
 }
 
@@ -149,3 +195,17 @@ func (s *service) SessionInfoHandler() http.HandlerFunc {
 		json.NewEncoder(w).Encode(userInfo)
 	}
 }
+
+// emailVerified reports whether the provider vouches for the email of user.
+// Google's userinfo calls the field verified_email, OpenID Connect email_verified.
+func emailVerified(user goth.User) bool {
+	for _, field := range []string{"email_verified", "verified_email"} {
+		switch v := user.RawData[field].(type) {
+		case bool:
+			return v
+		case string:
+			return v == "true"
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"backend/loggers"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const linkRequestLifetime = 10 * time.Minute
+
+// LinkIdentityHandler starts an OAuth flow whose account is linked to the
+// signed in admin instead of signing in, it must run after AuthMiddleware
+func (s *service) LinkIdentityHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		session, err := s.store.Get(r, "session-name")
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error retrieving session: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		session.Values["linkAdminID"] = adminID
+		session.Values["linkExpiresAt"] = time.Now().Add(linkRequestLifetime).Unix()
+		if err := session.Save(r, w); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error saving session: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/auth/"+chi.URLParam(r, "provider"), http.StatusSeeOther)
+	}
+}
+
+// popLinkRequest removes a pending link request from the session, an
+// abandoned request must not link whoever signs in next on this browser
+func popLinkRequest(values map[interface{}]interface{}) (adminID string, ok bool) {
+	adminID, ok = values["linkAdminID"].(string)
+	expiresAt, _ := values["linkExpiresAt"].(int64)
+	delete(values, "linkAdminID")
+	delete(values, "linkExpiresAt")
+	return adminID, ok && adminID != "" && time.Now().Unix() < expiresAt
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"backend/internal/tokens"
+	"backend/loggers"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const invitationPrefix = "invitation:"
+
+var errInvitationsDisabled = errors.New("INVITATION_SECRET is not set, invitations are disabled")
+
+// NewInvitationToken returns a signed random token for an admin invitation.
+// Only its hash is stored, the signature lets the accept link be rejected
+// before touching the database.
+func NewInvitationToken(secret string) (string, error) {
+	if secret == "" {
+		return "", errInvitationsDisabled
+	}
+	nonce, err := tokens.Random(32)
+	if err != nil {
+		return "", err
+	}
+	return tokens.Sign(secret, invitationPrefix+nonce), nil
+}
+
+func (s *service) verifyInvitationToken(token string) bool {
+	payload, err := tokens.Verify(s.invitationSecret, token)
+	return err == nil && strings.HasPrefix(payload, invitationPrefix)
+}
+
+// AcceptInvitationHandler is the link in the invitation email. The token is
+// kept in the session and redeemed by the OAuth callback once the invitee
+// has signed in, bind=true accepts with an account using another email.
+func (s *service) AcceptInvitationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if !s.verifyInvitationToken(token) {
+			http.Redirect(w, r, "/invitation-invalid", http.StatusSeeOther)
+			return
+		}
+
+		session, err := s.store.Get(r, "session-name")
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error retrieving session: %v", err)
+			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
+			return
+		}
+		session.Values["invitationToken"] = token
+		session.Values["invitationBind"] = r.URL.Query().Get("bind") == "true"
+		if err := session.Save(r, w); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error saving session: %v", err)
+			http.Redirect(w, r, "/login-error", http.StatusSeeOther)
+			return
+		}
+
+		http.Redirect(w, r, "/auth/"+s.loginProvider, http.StatusSeeOther)
+	}
+}
+
+// popInvitation removes a pending invitation from the session, the token is
+// single use so it must not survive a failed attempt either
+func popInvitation(values map[interface{}]interface{}) (token string, bind bool, ok bool) {
+	token, ok = values["invitationToken"].(string)
+	bind, _ = values["invitationBind"].(bool)
+	delete(values, "invitationToken")
+	delete(values, "invitationBind")
+	return token, bind, ok && token != ""
+}
+
+// FounderMiddleware only lets the permanent founder admin through, it must
+// run after AuthMiddleware
+func (s *service) FounderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		admin, err := s.db.GetAdmin(r.Context(), "id", adminID)
+		if err != nil {
+			if err.Error() == "admin not found" {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			loggers.Error.Ctx(r.Context()).Printf("Error getting admin: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if admin.Status != "permanent" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
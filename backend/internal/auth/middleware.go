@@ -2,30 +2,82 @@ package auth
 
 import (
 	"backend/loggers"
+	"context"
 	"net/http"
+	"strings"
+	"time"
 )
 
 func (s *service) AuthMiddleware(next http.Handler) http.Handler {
 	loggers.Debug.Println("AuthMiddleware called")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		loggers.Debug.Println("Retrieving session...")
+		// scripts authenticate with a personal access token instead of a session
+		if raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			s.authenticateBearer(w, r, next, strings.TrimSpace(raw))
+			return
+		}
+
+		loggers.Debug.Ctx(r.Context()).Println("Retrieving session...")
 		session, err := s.store.Get(r, "session-name")
 		if err != nil || session.Values["userID"] == nil {
-			loggers.Debug.Println("User not logged in")
-
-			// Check if the request is an AJAX request
-			if r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
-				loggers.Debug.Println("AJAX request detected, sending unauthorized status")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			// For non-AJAX requests, redirect to login
-			loggers.Debug.Println("Redirecting to login page...")
-			http.Redirect(w, r, "/auth/google", http.StatusSeeOther)
+			loggers.Debug.Ctx(r.Context()).Println("User not logged in")
+			s.notLoggedIn(w, r)
+			return
+		}
+
+		// sessions are revoked server side when the admin is deleted
+		adminID, _ := session.Values["adminID"].(string)
+		if adminID == "" {
+			s.notLoggedIn(w, r)
+			return
+		}
+		issuedAt, _ := session.Values["issuedAt"].(int64)
+		valid, err := s.db.AdminSessionValid(r.Context(), adminID, time.Unix(issuedAt, 0))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error checking session: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !valid {
+			loggers.Debug.Ctx(r.Context()).Printf("Session of admin %v has been revoked", adminID)
+			session.Options.MaxAge = -1
+			session.Save(r, w)
+			s.notLoggedIn(w, r)
 			return
 		}
-		// User is authenticated; proceed with the request
+
+		// User is authenticated; expose the admin to handlers and proceed with the request
+		loggers.SetAdminID(r.Context(), adminID)
+		r = r.WithContext(WithAdminID(r.Context(), adminID))
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (s *service) notLoggedIn(w http.ResponseWriter, r *http.Request) {
+	// Check if the request is an AJAX request
+	if r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+		loggers.Debug.Ctx(r.Context()).Println("AJAX request detected, sending unauthorized status")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// For non-AJAX requests, redirect to login
+	loggers.Debug.Ctx(r.Context()).Println("Redirecting to login page...")
+	http.Redirect(w, r, "/auth/"+s.loginProvider, http.StatusSeeOther)
+}
+
+type contextKey string
+
+const adminIDKey contextKey = "adminID"
+
+// WithAdminID marks ctx as authenticated as adminID the way AuthMiddleware
+// does, tests use it to serve handlers without the middleware
+func WithAdminID(ctx context.Context, adminID string) context.Context {
+	return context.WithValue(ctx, adminIDKey, adminID)
+}
+
+// AdminIDFromContext returns the ID of the admin authenticated by AuthMiddleware
+func AdminIDFromContext(ctx context.Context) (string, bool) {
+	adminID, ok := ctx.Value(adminIDKey).(string)
+	return adminID, ok && adminID != ""
+}
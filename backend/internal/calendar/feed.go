@@ -0,0 +1,27 @@
+package calendar
+
+import (
+	"backend/internal/tokens"
+	"strings"
+)
+
+const feedTokenPrefix = "calendar:"
+
+// FeedToken returns the token identifying an admin's private calendar feed.
+// Rotating the secret invalidates every issued feed URL.
+func FeedToken(secret, adminID string) string {
+	return tokens.Sign(secret, feedTokenPrefix+adminID)
+}
+
+// ParseFeedToken verifies a private feed token and returns the admin ID it was issued to.
+func ParseFeedToken(secret, token string) (string, error) {
+	payload, err := tokens.Verify(secret, token)
+	if err != nil {
+		return "", err
+	}
+	adminID, ok := strings.CutPrefix(payload, feedTokenPrefix)
+	if !ok || adminID == "" {
+		return "", tokens.ErrInvalidToken
+	}
+	return adminID, nil
+}
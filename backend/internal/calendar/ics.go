@@ -0,0 +1,98 @@
+package calendar
+
+/**
+Source: https://datatracker.ietf.org/doc/html/rfc5545
+*/
+
+import (
+	"backend/internal/models"
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	prodID      = "-//Jiating//Performance Calendar//EN"
+	uidDomain   = "jiating"
+	dateTimeFmt = "20060102T150405Z"
+	maxLineLen  = 75 // octets, excluding CRLF
+)
+
+// WriteICS writes the performances as an RFC 5545 VCALENDAR to w.
+// All times are written in UTC so no VTIMEZONE component is needed.
+func WriteICS(w io.Writer, name string, performances []models.Performance) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now().UTC().Format(dateTimeFmt)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	writeLine(bw, "X-WR-CALNAME:"+escapeText(name))
+
+	for _, p := range performances {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+p.ID+"@"+uidDomain)
+		writeLine(bw, "DTSTAMP:"+now)
+		writeLine(bw, "DTSTART:"+p.StartTime.UTC().Format(dateTimeFmt))
+		writeLine(bw, "DTEND:"+p.EndTime.UTC().Format(dateTimeFmt))
+		if !p.UpdatedAt.IsZero() {
+			writeLine(bw, "LAST-MODIFIED:"+p.UpdatedAt.UTC().Format(dateTimeFmt))
+		}
+		writeLine(bw, "SUMMARY:"+escapeText(p.Title))
+		if p.Location != "" {
+			writeLine(bw, "LOCATION:"+escapeText(p.Location))
+		}
+		if p.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(p.Description))
+		}
+		if p.IsPublic {
+			writeLine(bw, "CLASS:PUBLIC")
+		} else {
+			writeLine(bw, "CLASS:PRIVATE")
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText escapes a TEXT property value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeLine writes a content line terminated by CRLF, folding it so that no
+// physical line exceeds 75 octets (RFC 5545 section 3.1). Folds never split
+// a multi-byte UTF-8 sequence.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLen
+	for len(line) > limit {
+		cut := limit
+		// back up to the start of a UTF-8 sequence
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineLen - 1 // continuation lines start with a space
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,530 @@
+package config
+
+import (
+	"backend/internal/tokens"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config is everything the API reads from its environment. It is loaded once
+// at startup by Load and handed to each service, nothing else reads env vars.
+type Config struct {
+	// Env is dev for local development
+	Env  string
+	Port int
+	// ShutdownTimeout bounds draining requests and workers on SIGTERM
+	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long SIGTERM keeps serving while reporting
+	// not ready, before the listener closes
+	ShutdownDrainDelay time.Duration
+
+	// PublicBaseURL is where this API is reachable from the outside, used in links sent by email
+	PublicBaseURL string
+	// FrontendURL is where the React app is served, redirects after login land there
+	FrontendURL string
+	// CORSOrigins may call the API from a browser, defaults to FrontendURL
+	CORSOrigins []string
+
+	Logging   Logging
+	Tracing   Tracing
+	RateLimit RateLimit
+	Database  Database
+	Auth      Auth
+	Email     Email
+	S3        S3
+
+	// signing secrets, the features using them are disabled while empty
+	CalendarFeedSecret string
+	NewsletterSecret   string
+
+	// MetricsToken protects /metrics as a bearer token, empty leaves it open
+	MetricsToken string
+}
+
+type Logging struct {
+	Level slog.Level
+	// Format is json or logfmt
+	Format string
+	// Zone timestamps are written in
+	Zone *time.Location
+}
+
+type Tracing struct {
+	// Exporter is none, stdout or otlp
+	Exporter string
+	// OTLPEndpoint is the OTLP/HTTP collector, e.g. http://localhost:4318
+	OTLPEndpoint string
+	// SampleRatio of new traces to record, traces continued from a caller follow its decision
+	SampleRatio float64
+	ServiceName string
+}
+
+type RateLimit struct {
+	// Store is memory, or postgres to share limits between instances
+	Store string
+	// TrustedProxies are the networks of load balancers whose
+	// X-Forwarded-For header is believed, the client IP is read from it
+	TrustedProxies []netip.Prefix
+	// MaxKeys bounds the clients the memory store tracks, the least
+	// recently seen are evicted first
+	MaxKeys int
+	// Policies by name, each rate limited route picks one
+	Policies map[string]RateLimitPolicy
+}
+
+// RateLimitPolicy allows Requests per Window to each client, all of them
+// may be used in a burst
+type RateLimitPolicy struct {
+	Requests int
+	Window   time.Duration
+}
+
+// defaultRateLimits are overridden by name with RATE_LIMITS
+var defaultRateLimits = map[string]RateLimitPolicy{
+	"contact":         {Requests: 5, Window: 10 * time.Minute},
+	"subscribe":       {Requests: 5, Window: 10 * time.Minute},
+	"newsletter-send": {Requests: 10, Window: time.Hour},
+}
+
+type Database struct {
+	Host     string
+	Port     string
+	Name     string
+	Username string
+	Password string
+}
+
+// DSN is the connection string for the pgx driver
+func (d Database) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		url.QueryEscape(d.Username), url.QueryEscape(d.Password), d.Host, d.Port, d.Name)
+}
+
+type Auth struct {
+	// Providers lists the goth providers to enable: google, oidc and dev
+	Providers       []string
+	CallbackBaseURL string
+
+	GoogleClientID     string
+	GoogleClientSecret string
+
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCDiscoveryURL string
+
+	CookieSecure   bool
+	CookieSameSite http.SameSite
+	// SessionSecret signs the session cookie, anyone who knows it can
+	// forge a session for any admin
+	SessionSecret string
+
+	InvitationSecret string
+}
+
+// MinSessionSecretLength is the shortest SESSION_SECRET accepted outside dev
+const MinSessionSecretLength = 32
+
+type Email struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+}
+
+type S3 struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	// Endpoint points the client at an S3-compatible server instead of AWS,
+	// objects are then addressed by path rather than by bucket subdomain
+	Endpoint string
+}
+
+// Problems lists everything wrong with a configuration, so a deployment can
+// be fixed in one go instead of one restart per missing variable
+type Problems []string
+
+func (p Problems) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(p, "\n  - ")
+}
+
+// Load reads the configuration from, in order of precedence, command line
+// flags, environment variables and an env file. The env file is -config,
+// CONFIG_FILE or .env, only an explicitly named file has to exist.
+// A Problems error is returned when the result is invalid.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	logLevel := flags.String("log-level", "", "debug, info, warn or error, overrides LOG_LEVEL")
+	file := flags.String("config", "", "env file to read configuration from (default .env)")
+	port := flags.String("port", "", "port to listen on, overrides PORT")
+	env := flags.String("env", "", "environment, dev enables development only features, overrides ENV")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	l, err := newLookup(*file)
+	if err != nil {
+		return nil, err
+	}
+	l.override("PORT", *port)
+	l.override("ENV", *env)
+	l.override("LOG_LEVEL", *logLevel)
+
+	return l.config()
+}
+
+// lookup resolves a variable from the flags, the environment and then the env file
+type lookup struct {
+	flags    map[string]string
+	file     map[string]string
+	problems Problems
+}
+
+func newLookup(file string) (*lookup, error) {
+	l := &lookup{flags: map[string]string{}, file: map[string]string{}}
+
+	required := file != ""
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+		required = file != ""
+	}
+	if file == "" {
+		file = ".env"
+	}
+
+	values, err := godotenv.Read(file)
+	if err != nil {
+		if required || !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading config file %s: %w", file, err)
+		}
+		values = map[string]string{}
+	}
+	l.file = values
+	return l, nil
+}
+
+func (l *lookup) override(key, value string) {
+	if value != "" {
+		l.flags[key] = value
+	}
+}
+
+func (l *lookup) get(key, fallback string) string {
+	if value, ok := l.flags[key]; ok {
+		return value
+	}
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	if value, ok := l.file[key]; ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (l *lookup) problem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+func (l *lookup) required(key string) string {
+	value := l.get(key, "")
+	if value == "" {
+		l.problem("%s is required", key)
+	}
+	return value
+}
+
+func (l *lookup) int(key string, fallback int) int {
+	value := l.get(key, "")
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		l.problem("%s must be a port number, got %q", key, value)
+		return fallback
+	}
+	return n
+}
+
+func (l *lookup) duration(key string, fallback time.Duration) time.Duration {
+	value := l.get(key, "")
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		l.problem("%s must be a positive duration like 30s, got %q", key, value)
+		return fallback
+	}
+	return d
+}
+
+func (l *lookup) bool(key string) bool {
+	switch value := strings.ToLower(l.get(key, "")); value {
+	case "", "false", "0":
+		return false
+	case "true", "1":
+		return true
+	default:
+		l.problem("%s must be true or false, got %q", key, value)
+		return false
+	}
+}
+
+func (l *lookup) url(key, fallback string) string {
+	value := strings.TrimSuffix(l.get(key, fallback), "/")
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+		l.problem("%s must be an absolute URL, got %q", key, value)
+	}
+	return value
+}
+
+func (l *lookup) list(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(l.get(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (l *lookup) config() (*Config, error) {
+	cfg := &Config{
+		Env:             l.get("ENV", ""),
+		Port:            l.int("PORT", 8080),
+		ShutdownTimeout: l.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		PublicBaseURL:   l.url("PUBLIC_BASE_URL", "http://localhost:3000"),
+		FrontendURL:     l.url("FRONTEND_URL", "http://localhost:5173"),
+
+		Database: Database{
+			Host:     l.required("DB_HOST"),
+			Port:     l.get("DB_PORT", "5432"),
+			Name:     l.required("DB_DATABASE"),
+			Username: l.required("DB_USERNAME"),
+			Password: l.get("DB_PASSWORD", ""),
+		},
+
+		Auth: Auth{
+			CallbackBaseURL:    l.url("OAUTH_CALLBACK_BASE_URL", "http://localhost:3000"),
+			GoogleClientID:     l.get("GOOGLE_CLIENT_ID", ""),
+			GoogleClientSecret: l.get("GOOGLE_CLIENT_SECRET", ""),
+			OIDCClientID:       l.get("OIDC_CLIENT_ID", ""),
+			OIDCClientSecret:   l.get("OIDC_CLIENT_SECRET", ""),
+			OIDCDiscoveryURL:   l.get("OIDC_DISCOVERY_URL", ""),
+			CookieSecure:       l.bool("COOKIE_SECURE"),
+			SessionSecret:      l.get("SESSION_SECRET", ""),
+			InvitationSecret:   l.get("INVITATION_SECRET", ""),
+		},
+
+		Email: Email{
+			Host:     l.get("EMAIL_HOST", ""),
+			Port:     l.int("EMAIL_PORT", 587),
+			User:     l.get("EMAIL_USER", ""),
+			Password: l.get("EMAIL_PASSWORD", ""),
+		},
+
+		S3: S3{
+			Region:          l.get("AWS_REGION", ""),
+			AccessKeyID:     l.get("AWS_ACCESS_KEY_ID", ""),
+			SecretAccessKey: l.get("AWS_SECRET_ACCESS_KEY", ""),
+			Bucket:          l.get("S3_BUCKET_NAME", ""),
+		},
+
+		CalendarFeedSecret: l.get("CALENDAR_FEED_SECRET", ""),
+		NewsletterSecret:   l.get("NEWSLETTER_SECRET", ""),
+		MetricsToken:       l.get("METRICS_TOKEN", ""),
+	}
+
+	for _, origin := range l.list("CORS_ALLOWED_ORIGINS", cfg.FrontendURL) {
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" {
+			l.problem("CORS_ALLOWED_ORIGINS must list absolute URLs, got %q", origin)
+		}
+		cfg.CORSOrigins = append(cfg.CORSOrigins, strings.TrimSuffix(origin, "/"))
+	}
+
+	// nothing balances traffic to a dev server, stop right away there
+	drainDelay := 5 * time.Second
+	if cfg.Env == "dev" {
+		drainDelay = 0
+	}
+	cfg.ShutdownDrainDelay = l.duration("SHUTDOWN_DRAIN_DELAY", drainDelay)
+
+	l.validateLogging(cfg)
+	l.validateTracing(cfg)
+	l.validateRateLimit(cfg)
+	l.validateAuth(cfg)
+
+	if endpoint := l.get("S3_ENDPOINT", ""); endpoint != "" {
+		cfg.S3.Endpoint = l.url("S3_ENDPOINT", "")
+	}
+
+	if len(l.problems) > 0 {
+		return nil, l.problems
+	}
+	return cfg, nil
+}
+
+func (l *lookup) validateLogging(cfg *Config) {
+	level := l.get("LOG_LEVEL", "info")
+	if err := cfg.Logging.Level.UnmarshalText([]byte(level)); err != nil {
+		l.problem("LOG_LEVEL must be debug, info, warn or error, got %q", level)
+	}
+
+	switch cfg.Logging.Format = strings.ToLower(l.get("LOG_FORMAT", "logfmt")); cfg.Logging.Format {
+	case "json", "logfmt":
+	default:
+		l.problem("LOG_FORMAT must be json or logfmt, got %q", cfg.Logging.Format)
+	}
+
+	zone := l.get("LOG_ZONE", "UTC")
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		l.problem("LOG_ZONE must be a time zone like UTC or America/New_York, got %q", zone)
+		location = time.UTC
+	}
+	cfg.Logging.Zone = location
+}
+
+func (l *lookup) validateTracing(cfg *Config) {
+	tracing := &cfg.Tracing
+	tracing.ServiceName = l.get("OTEL_SERVICE_NAME", "jiating-api")
+
+	switch tracing.Exporter = strings.ToLower(l.get("TRACE_EXPORTER", "none")); tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		tracing.OTLPEndpoint = l.url("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	default:
+		l.problem("TRACE_EXPORTER must be none, stdout or otlp, got %q", tracing.Exporter)
+	}
+
+	ratio := l.get("TRACE_SAMPLE_RATIO", "1")
+	value, err := strconv.ParseFloat(ratio, 64)
+	if err != nil || value < 0 || value > 1 {
+		l.problem("TRACE_SAMPLE_RATIO must be between 0 and 1, got %q", ratio)
+		value = 1
+	}
+	tracing.SampleRatio = value
+}
+
+func (l *lookup) validateRateLimit(cfg *Config) {
+	limits := &cfg.RateLimit
+
+	switch limits.Store = strings.ToLower(l.get("RATE_LIMIT_STORE", "memory")); limits.Store {
+	case "memory", "postgres":
+	default:
+		l.problem("RATE_LIMIT_STORE must be memory or postgres, got %q", limits.Store)
+	}
+
+	maxKeys := l.get("RATE_LIMIT_MAX_KEYS", "10000")
+	n, err := strconv.Atoi(maxKeys)
+	if err != nil || n < 1 {
+		l.problem("RATE_LIMIT_MAX_KEYS must be a positive number, got %q", maxKeys)
+		n = 10000
+	}
+	limits.MaxKeys = n
+
+	for _, cidr := range l.list("RATE_LIMIT_TRUSTED_PROXIES", "") {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			// a single address is a /32 or /128
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				l.problem("RATE_LIMIT_TRUSTED_PROXIES must list IPs or CIDRs like 10.0.0.0/8, got %q", cidr)
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		limits.TrustedProxies = append(limits.TrustedProxies, prefix.Masked())
+	}
+
+	limits.Policies = make(map[string]RateLimitPolicy, len(defaultRateLimits))
+	for name, policy := range defaultRateLimits {
+		limits.Policies[name] = policy
+	}
+	// name=requests/window, e.g. contact=5/10m
+	for _, entry := range l.list("RATE_LIMITS", "") {
+		name, value, _ := strings.Cut(entry, "=")
+		if _, ok := defaultRateLimits[name]; !ok {
+			l.problem("RATE_LIMITS: unknown policy %q", name)
+			continue
+		}
+		requests, window, _ := strings.Cut(value, "/")
+		n, err := strconv.Atoi(requests)
+		d, durationErr := time.ParseDuration(window)
+		if err != nil || durationErr != nil || n < 1 || d <= 0 {
+			l.problem("RATE_LIMITS: %s must be requests/window like 5/10m, got %q", name, value)
+			continue
+		}
+		limits.Policies[name] = RateLimitPolicy{Requests: n, Window: d}
+	}
+}
+
+func (l *lookup) validateAuth(cfg *Config) {
+	auth := &cfg.Auth
+
+	for _, provider := range l.list("OAUTH_PROVIDERS", "google") {
+		switch provider {
+		case "google":
+			if auth.GoogleClientID == "" || auth.GoogleClientSecret == "" {
+				l.problem("the google provider requires GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET")
+			}
+		case "oidc":
+			if auth.OIDCClientID == "" || auth.OIDCClientSecret == "" || auth.OIDCDiscoveryURL == "" {
+				l.problem("the oidc provider requires OIDC_CLIENT_ID, OIDC_CLIENT_SECRET and OIDC_DISCOVERY_URL")
+			}
+		case "dev":
+			// signs in as any admin without a password, never allowed outside local development
+			if cfg.Env != "dev" {
+				l.problem("the dev provider requires ENV=dev")
+			}
+		default:
+			l.problem("OAUTH_PROVIDERS: unknown provider %q", provider)
+		}
+		auth.Providers = append(auth.Providers, provider)
+	}
+	if len(auth.Providers) == 0 {
+		l.problem("OAUTH_PROVIDERS must enable at least one provider")
+	}
+
+	switch {
+	case len(auth.SessionSecret) >= MinSessionSecretLength:
+	case cfg.Env != "dev":
+		l.problem("SESSION_SECRET must be at least %d characters", MinSessionSecretLength)
+	case auth.SessionSecret == "":
+		// sessions of a dev server don't outlive it
+		secret, err := tokens.Random(MinSessionSecretLength)
+		if err != nil {
+			l.problem("generating a dev SESSION_SECRET: %v", err)
+		}
+		auth.SessionSecret = secret
+	}
+
+	switch value := strings.ToLower(l.get("COOKIE_SAMESITE", "lax")); value {
+	case "lax":
+		auth.CookieSameSite = http.SameSiteLaxMode
+	case "strict":
+		auth.CookieSameSite = http.SameSiteStrictMode
+	case "none":
+		auth.CookieSameSite = http.SameSiteNoneMode
+		// browsers drop SameSite=None cookies that aren't Secure
+		if !auth.CookieSecure {
+			l.problem("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+		}
+	default:
+		l.problem("COOKIE_SAMESITE must be lax, strict or none, got %q", value)
+	}
+}
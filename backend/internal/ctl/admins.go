@@ -0,0 +1,183 @@
+package ctl
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"context"
+	"io"
+	"time"
+)
+
+func (a *App) admin(ctx context.Context, sub string, args []string) error {
+	switch sub {
+	case "list":
+		return a.listAdmins(ctx, args)
+	case "get":
+		args, err := parse(newFlags("admin get"), args, 1)
+		if err != nil {
+			return err
+		}
+		admin, err := a.Repos.Admins.GetAdmin(ctx, adminField(args[0]), args[0])
+		if err != nil {
+			return err
+		}
+		return a.printAdmins(admin, *admin)
+	case "create":
+		return a.createAdmin(ctx, args)
+	case "update":
+		return a.updateAdmin(ctx, args)
+	case "promote":
+		args, err := parse(newFlags("admin promote"), args, 2)
+		if err != nil {
+			return err
+		}
+		return a.changeAdmin(ctx, args[0], func(admin *models.Admin) { admin.Position = args[1] })
+	case "delete":
+		flags := newFlags("admin delete")
+		reassignTo := flags.String("reassign-to", "", "move the admin's events to this admin")
+		args, err := parse(flags, args, 1)
+		if err != nil {
+			return err
+		}
+		admin, err := a.Repos.Admins.DeleteAdmin(ctx, adminField(args[0]), args[0], *reassignTo)
+		if err != nil {
+			return err
+		}
+		return a.printAdmins(admin, *admin)
+	case "restore":
+		args, err := parse(newFlags("admin restore"), args, 1)
+		if err != nil {
+			return err
+		}
+		admin, err := a.Repos.Admins.RestoreAdmin(ctx, adminField(args[0]), args[0])
+		if err != nil {
+			return err
+		}
+		return a.printAdmins(admin, *admin)
+	case "reset-founder":
+		if _, err := parse(newFlags("admin reset-founder"), args, 0); err != nil {
+			return err
+		}
+		if err := a.needDB(); err != nil {
+			return err
+		}
+		founder, err := database.ResetFounder(ctx, a.DB)
+		if err != nil {
+			return err
+		}
+		return a.printAdmins(founder, *founder)
+	}
+	return usageError("unknown admin subcommand " + sub)
+}
+
+func (a *App) listAdmins(ctx context.Context, args []string) error {
+	flags := newFlags("admin list")
+	deleted := flags.String("deleted", "exclude", "exclude, include or only deleted admins")
+	page := flags.Int("page", 1, "page to list")
+	pageSize := flags.Int("page-size", 50, "admins per page")
+	if _, err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	list := a.Repos.Admins.GetAllAdmins
+	switch *deleted {
+	case "exclude":
+	case "include":
+		list = a.Repos.Admins.GetAllAdminsIncludingDeleted
+	case "only":
+		list = a.Repos.Admins.GetDeletedAdmins
+	default:
+		return usageError("-deleted must be exclude, include or only")
+	}
+	admins, err := list(ctx, *page, *pageSize)
+	if err != nil {
+		return err
+	}
+	if admins == nil {
+		admins = []models.Admin{}
+	}
+	return a.printAdmins(admins, admins...)
+}
+
+func (a *App) createAdmin(ctx context.Context, args []string) error {
+	flags := newFlags("admin create")
+	var admin models.Admin
+	flags.StringVar(&admin.Name, "name", "", "name of the admin")
+	flags.StringVar(&admin.Email, "email", "", "email the admin signs in with")
+	flags.StringVar(&admin.Position, "position", "", "position in the team")
+	flags.StringVar(&admin.Status, "status", "active", "active, inactive or hiatus")
+	if _, err := parse(flags, args, 0); err != nil {
+		return err
+	}
+	if admin.Name == "" || admin.Email == "" || admin.Position == "" {
+		return usageError("admin create requires -name, -email and -position")
+	}
+
+	id, err := a.Repos.Admins.CreateAdmin(ctx, admin)
+	if err != nil {
+		return err
+	}
+	created, err := a.Repos.Admins.GetAdmin(ctx, "id", id)
+	if err != nil {
+		return err
+	}
+	return a.printAdmins(created, *created)
+}
+
+// updateAdmin changes only the fields given as flags
+func (a *App) updateAdmin(ctx context.Context, args []string) error {
+	flags := newFlags("admin update")
+	name := flags.String("name", "", "new name")
+	email := flags.String("email", "", "new email")
+	position := flags.String("position", "", "new position")
+	status := flags.String("status", "", "new status: active, inactive or hiatus")
+	args, err := parse(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return a.changeAdmin(ctx, args[0], func(admin *models.Admin) {
+		if *name != "" {
+			admin.Name = *name
+		}
+		if *email != "" {
+			admin.Email = *email
+		}
+		if *position != "" {
+			admin.Position = *position
+		}
+		if *status != "" {
+			admin.Status = *status
+		}
+	})
+}
+
+func (a *App) changeAdmin(ctx context.Context, ref string, change func(*models.Admin)) error {
+	admin, err := a.Repos.Admins.GetAdmin(ctx, adminField(ref), ref)
+	if err != nil {
+		return err
+	}
+	change(admin)
+	if err := a.Repos.Admins.UpdateAdmin(ctx, *admin); err != nil {
+		return err
+	}
+	updated, err := a.Repos.Admins.GetAdmin(ctx, "id", admin.ID)
+	if err != nil {
+		return err
+	}
+	return a.printAdmins(updated, *updated)
+}
+
+// printAdmins prints v as JSON, or admins as a table
+func (a *App) printAdmins(v interface{}, admins ...models.Admin) error {
+	return a.print(v, func(w io.Writer) {
+		row(w, "ID", "EMAIL", "NAME", "POSITION", "STATUS", "DELETED")
+		for _, admin := range admins {
+			deleted := "-"
+			if admin.DeletedAt != nil {
+				deleted = admin.DeletedAt.Format(time.DateOnly)
+			}
+			row(w, admin.ID, admin.Email, admin.Name, admin.Position, admin.Status, deleted)
+		}
+	})
+}
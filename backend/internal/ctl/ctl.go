@@ -0,0 +1,187 @@
+// Package ctl implements the jiatingctl commands. They run against the same
+// repositories and services as the API: cmd/jiatingctl connects them from
+// the configuration, tests pass in-memory ones.
+package ctl
+
+import (
+	"backend/internal/database"
+	"backend/internal/s3service"
+	"backend/misc"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+// Usage lists the commands, flags come before the arguments
+const Usage = `usage: jiatingctl [-json] [-config file] <command> [flags] [args]
+
+-json prints results as JSON and failures as {"error": "..."}
+
+admins:
+  admin list [-deleted exclude|include|only] [-page n] [-page-size n]
+  admin get <id|email>
+  admin create -name name -email email -position position [-status status]
+  admin update [-name name] [-email email] [-position position] [-status status] <id|email>
+  admin promote <id|email> <position>
+  admin delete [-reassign-to id|email] <id|email>
+  admin restore <id|email>
+  admin reset-founder
+
+events:
+  event list [-page n] [-page-size n]
+  event export [-out file]
+  event import [-skip-existing] <file>
+
+storage:
+  storage photoshoots [year [event]]
+  storage usage
+  storage prune-testing [-older-than duration] [-dry-run]
+
+database:
+  migrate status
+  migrate up
+
+health
+`
+
+// ErrUnhealthy is returned by health after printing the failed checks
+var ErrUnhealthy = errors.New("unhealthy")
+
+// Failure is what PrintError prints for a failed command
+type Failure struct {
+	Error string `json:"error"`
+}
+
+// App runs commands, a field a command needs being nil is reported as an
+// error instead of a panic
+type App struct {
+	Repos database.Repositories
+	// DB is the connection pool migrations, founder resets and database
+	// health checks run on
+	DB *sql.DB
+	// Storage serves photoshoots like the API does, Objects is the raw bucket
+	// access storage maintenance needs
+	Storage s3service.Service
+	Objects misc.Storage
+	Bucket  string
+
+	Out io.Writer
+	// JSON prints results as JSON for scripts instead of tables
+	JSON bool
+}
+
+// Run runs the command in args, without the global flags
+func (a *App) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usageError("missing command")
+	}
+	command, args := args[0], args[1:]
+	if command == "health" {
+		return a.health(ctx)
+	}
+	if len(args) == 0 {
+		return usageError("missing subcommand for " + command)
+	}
+	sub, args := args[0], args[1:]
+
+	switch command {
+	case "admin":
+		return a.admin(ctx, sub, args)
+	case "event":
+		return a.event(ctx, sub, args)
+	case "storage":
+		return a.storage(ctx, sub, args)
+	case "migrate":
+		return a.migrate(ctx, sub, args)
+	}
+	return usageError("unknown command " + command)
+}
+
+// ===== internal ===== //
+
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// IsUsageError reports whether err is about how the command was called,
+// main prints Usage for those
+func IsUsageError(err error) bool {
+	var u usageError
+	return errors.As(err, &u)
+}
+
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parse parses flags and checks the number of arguments left
+func parse(flags *flag.FlagSet, args []string, want int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, usageError(flags.Name() + ": " + err.Error())
+	}
+	if flags.NArg() != want {
+		return nil, usageError(fmt.Sprintf("%s takes %d argument(s), got %d", flags.Name(), want, flags.NArg()))
+	}
+	return flags.Args(), nil
+}
+
+// adminField tells ids from emails the way the admin routes do
+func adminField(ref string) string {
+	if uuid.Validate(ref) == nil {
+		return "id"
+	}
+	return "email"
+}
+
+func (a *App) needDB() error {
+	if a.DB == nil {
+		return errors.New("no database connection")
+	}
+	return nil
+}
+
+func (a *App) needStorage() error {
+	if a.Storage == nil || a.Objects == nil {
+		return errors.New("no storage configured")
+	}
+	return nil
+}
+
+// PrintError prints err as a Failure with -json, so scripts read failures
+// from the same output as results. It reports whether it printed anything.
+func (a *App) PrintError(err error) bool {
+	if !a.JSON {
+		return false
+	}
+	return a.print(Failure{Error: err.Error()}, nil) == nil
+}
+
+// print writes v as JSON, or as the table text writes
+func (a *App) print(v interface{}, text func(w io.Writer)) error {
+	if a.JSON {
+		enc := json.NewEncoder(a.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(a.Out, 0, 4, 2, ' ', 0)
+	text(tw)
+	return tw.Flush()
+}
+
+func row(w io.Writer, cells ...interface{}) {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		values[i] = fmt.Sprint(cell)
+	}
+	fmt.Fprintln(w, strings.Join(values, "\t"))
+}
@@ -0,0 +1,146 @@
+package ctl
+
+import (
+	"backend/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// exportPageSize is how many events export reads at a time
+const exportPageSize = 100
+
+// ImportResult is what event import prints
+type ImportResult struct {
+	Imported []string `json:"imported"` // ids of the created events
+	Skipped  []string `json:"skipped"`  // ids of events that already existed
+}
+
+func (a *App) event(ctx context.Context, sub string, args []string) error {
+	switch sub {
+	case "list":
+		flags := newFlags("event list")
+		page := flags.Int("page", 1, "page to list")
+		pageSize := flags.Int("page-size", 50, "events per page")
+		if _, err := parse(flags, args, 0); err != nil {
+			return err
+		}
+		events, err := a.Repos.Events.GetAllEvents(ctx, *page, *pageSize)
+		if err != nil {
+			return err
+		}
+		if events == nil {
+			events = []models.Event{}
+		}
+		return a.print(events, func(w io.Writer) {
+			row(w, "ID", "DATE", "SLUG", "TITLE", "DRAFT")
+			for _, event := range events {
+				row(w, event.ID, event.Date.Format(time.DateOnly), event.Slug, event.EventTitle, event.IsDraft)
+			}
+		})
+	case "export":
+		flags := newFlags("event export")
+		out := flags.String("out", "", "file to write, standard output by default")
+		if _, err := parse(flags, args, 0); err != nil {
+			return err
+		}
+		return a.exportEvents(ctx, *out)
+	case "import":
+		flags := newFlags("event import")
+		skipExisting := flags.Bool("skip-existing", false, "skip events that already exist instead of failing")
+		args, err := parse(flags, args, 1)
+		if err != nil {
+			return err
+		}
+		return a.importEvents(ctx, args[0], *skipExisting)
+	}
+	return usageError("unknown event subcommand " + sub)
+}
+
+// exportEvents writes every event with its images and authors as a JSON
+// array, the format import reads. Exports are data, so they are JSON
+// whether or not -json was given.
+func (a *App) exportEvents(ctx context.Context, path string) error {
+	events := []models.Event{}
+	for page := 1; ; page++ {
+		batch, err := a.Repos.Events.GetAllEvents(ctx, page, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range batch {
+			if event.Images, err = a.Repos.Images.GetEventImages(ctx, event.ID); err != nil {
+				return fmt.Errorf("images of %s: %w", event.Slug, err)
+			}
+			if event.Authors, err = a.Repos.Authors.GetEventAuthors(ctx, event.ID); err != nil {
+				return fmt.Errorf("authors of %s: %w", event.Slug, err)
+			}
+			events = append(events, event)
+		}
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err = a.Out.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// importEvents creates the events of an export. Authors are matched by email
+// so exports move between databases, the first author creates the event.
+// Published events get a new publish time like events created in the API.
+func (a *App) importEvents(ctx context.Context, path string, skipExisting bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var events []models.Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	result := ImportResult{Imported: []string{}, Skipped: []string{}}
+	for _, event := range events {
+		var authorIDs []string
+		for _, author := range event.Authors {
+			admin, err := a.Repos.Admins.GetAdmin(ctx, "email", author.Email)
+			if err != nil {
+				return fmt.Errorf("author %s of %s: %w", author.Email, event.Slug, err)
+			}
+			authorIDs = append(authorIDs, admin.ID)
+		}
+		if len(authorIDs) == 0 {
+			return fmt.Errorf("event %s has no authors", event.Slug)
+		}
+
+		id, err := a.Repos.Events.CreateEvent(ctx, event, authorIDs[0])
+		if err != nil && err.Error() == "event already exists" && skipExisting {
+			result.Skipped = append(result.Skipped, event.ID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", event.Slug, err)
+		}
+		for _, authorID := range authorIDs[1:] {
+			if err := a.Repos.Authors.AddEventAuthor(ctx, id, authorID); err != nil {
+				return fmt.Errorf("adding author to %s: %w", event.Slug, err)
+			}
+		}
+		result.Imported = append(result.Imported, id)
+	}
+
+	return a.print(result, func(w io.Writer) {
+		row(w, "IMPORTED", "SKIPPED")
+		row(w, len(result.Imported), len(result.Skipped))
+	})
+}
@@ -0,0 +1,172 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// testingPrefix holds the uploads of DevGenerateEventImageUploadURL
+const testingPrefix = "testing/"
+
+// PrefixUsage is one row of storage usage
+type PrefixUsage struct {
+	Prefix  string `json:"prefix"`
+	Objects int    `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// PruneResult is what storage prune-testing prints
+type PruneResult struct {
+	Deleted []string `json:"deleted"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+func (a *App) storage(ctx context.Context, sub string, args []string) error {
+	if err := a.needStorage(); err != nil {
+		return err
+	}
+	switch sub {
+	case "photoshoots":
+		return a.photoshoots(ctx, args)
+	case "usage":
+		if _, err := parse(newFlags("storage usage"), args, 0); err != nil {
+			return err
+		}
+		return a.usage(ctx)
+	case "prune-testing":
+		flags := newFlags("storage prune-testing")
+		olderThan := flags.Duration("older-than", 7*24*time.Hour, "only delete uploads older than this")
+		dryRun := flags.Bool("dry-run", false, "list what would be deleted")
+		if _, err := parse(flags, args, 0); err != nil {
+			return err
+		}
+		return a.pruneTesting(ctx, *olderThan, *dryRun)
+	}
+	return usageError("unknown storage subcommand " + sub)
+}
+
+// photoshoots lists what the photoshoot routes serve: the years, the events
+// of a year or the photos of an event. Photoshoots are read from the bucket
+// on every request, so this is also how to check an upload is visible.
+func (a *App) photoshoots(ctx context.Context, args []string) error {
+	flags := newFlags("storage photoshoots")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	var names []string
+	var err error
+	header := "YEAR"
+	switch args = flags.Args(); len(args) {
+	case 0:
+		names, err = a.Storage.GetPhotoshootYears(ctx)
+	case 1:
+		header = "EVENT"
+		names, err = a.Storage.GetPhotoshootEvents(ctx, args[0])
+	case 2:
+		header = "PHOTO"
+		names, err = a.Storage.ListPhotoshootPhotos(ctx, args[0], args[1])
+	default:
+		return usageError("storage photoshoots takes at most a year and an event")
+	}
+	if err != nil {
+		return err
+	}
+	if names == nil {
+		names = []string{}
+	}
+	return a.print(names, func(w io.Writer) {
+		row(w, header)
+		for _, name := range names {
+			row(w, name)
+		}
+	})
+}
+
+// usage counts the objects and bytes under every top level prefix
+func (a *App) usage(ctx context.Context) error {
+	byPrefix := map[string]*PrefixUsage{}
+	err := a.eachObject(ctx, "", func(obj types.Object) error {
+		prefix, _, found := strings.Cut(*obj.Key, "/")
+		if found {
+			prefix += "/"
+		}
+		if byPrefix[prefix] == nil {
+			byPrefix[prefix] = &PrefixUsage{Prefix: prefix}
+		}
+		byPrefix[prefix].Objects++
+		byPrefix[prefix].Bytes += aws.ToInt64(obj.Size)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	usage := []PrefixUsage{}
+	for _, u := range byPrefix {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Prefix < usage[j].Prefix })
+	return a.print(usage, func(w io.Writer) {
+		row(w, "PREFIX", "OBJECTS", "BYTES")
+		for _, u := range usage {
+			row(w, u.Prefix, u.Objects, u.Bytes)
+		}
+	})
+}
+
+// pruneTesting deletes old uploads made from development, they are never
+// referenced by events
+func (a *App) pruneTesting(ctx context.Context, olderThan time.Duration, dryRun bool) error {
+	cutoff := time.Now().Add(-olderThan)
+	result := PruneResult{Deleted: []string{}, DryRun: dryRun}
+	err := a.eachObject(ctx, testingPrefix, func(obj types.Object) error {
+		if obj.LastModified != nil && obj.LastModified.After(cutoff) {
+			return nil
+		}
+		if !dryRun {
+			_, err := a.Objects.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(a.Bucket), Key: obj.Key})
+			if err != nil {
+				return fmt.Errorf("deleting %s: %w", *obj.Key, err)
+			}
+		}
+		result.Deleted = append(result.Deleted, *obj.Key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return a.print(result, func(w io.Writer) {
+		row(w, "DELETED")
+		for _, key := range result.Deleted {
+			row(w, key)
+		}
+	})
+}
+
+func (a *App) eachObject(ctx context.Context, prefix string, fn func(types.Object) error) error {
+	pages := s3.NewListObjectsV2Paginator(a.Objects, &s3.ListObjectsV2Input{
+		Bucket: aws.String(a.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package ctl
+
+import (
+	"backend/internal/database"
+	"context"
+	"fmt"
+	"io"
+)
+
+// SchemaStatus is what migrate prints
+type SchemaStatus struct {
+	Version  int  `json:"version"`  // latest version applied to the database
+	Expected int  `json:"expected"` // version this build creates
+	Current  bool `json:"current"`
+}
+
+// Check is the outcome of one health check
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Health is what health prints
+type Health struct {
+	OK     bool    `json:"ok"`
+	Checks []Check `json:"checks"`
+}
+
+func (a *App) migrate(ctx context.Context, sub string, args []string) error {
+	if _, err := parse(newFlags("migrate "+sub), args, 0); err != nil {
+		return err
+	}
+	if err := a.needDB(); err != nil {
+		return err
+	}
+	switch sub {
+	case "status":
+	case "up":
+		if err := database.Migrate(a.DB); err != nil {
+			return err
+		}
+	default:
+		return usageError("unknown migrate subcommand " + sub)
+	}
+
+	status, err := a.schemaStatus(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(status, func(w io.Writer) {
+		row(w, "VERSION", "EXPECTED", "CURRENT")
+		row(w, status.Version, status.Expected, status.Current)
+	})
+}
+
+// schemaStatus reads the applied version, a database that was never
+// migrated has no schema_migrations table and is at version 0
+func (a *App) schemaStatus(ctx context.Context) (SchemaStatus, error) {
+	status := SchemaStatus{Expected: database.SchemaVersion}
+	var exists bool
+	if err := a.DB.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return status, err
+	}
+	if exists {
+		version, err := database.New(a.DB).GetSchemaVersion(ctx)
+		if err != nil {
+			return status, err
+		}
+		status.Version = version
+	}
+	status.Current = status.Version >= status.Expected
+	return status, nil
+}
+
+// health checks the database, its schema and the bucket like the readiness
+// probe of the API, ErrUnhealthy is returned after printing a failed check
+func (a *App) health(ctx context.Context) error {
+	report := Health{OK: true}
+	add := func(check Check) {
+		report.OK = report.OK && check.OK
+		report.Checks = append(report.Checks, check)
+	}
+
+	if err := a.needDB(); err != nil {
+		add(Check{Name: "database", Error: err.Error()})
+	} else if err := a.DB.PingContext(ctx); err != nil {
+		add(Check{Name: "database", Error: err.Error()})
+	} else {
+		add(Check{Name: "database", OK: true})
+		status, err := a.schemaStatus(ctx)
+		check := Check{Name: "schema", OK: err == nil && status.Current}
+		switch {
+		case err != nil:
+			check.Error = err.Error()
+		case !status.Current:
+			check.Error = "schema is behind, run jiatingctl migrate up"
+		}
+		if err == nil {
+			check.Detail = fmt.Sprintf("version %d", status.Version)
+		}
+		add(check)
+	}
+
+	if err := a.needStorage(); err != nil {
+		add(Check{Name: "storage", Error: err.Error()})
+	} else if err := a.Storage.Ping(ctx); err != nil {
+		add(Check{Name: "storage", Error: err.Error()})
+	} else {
+		add(Check{Name: "storage", OK: true, Detail: a.Bucket})
+	}
+
+	err := a.print(report, func(w io.Writer) {
+		row(w, "CHECK", "OK", "DETAIL")
+		for _, check := range report.Checks {
+			detail := check.Detail
+			if check.Error != "" {
+				detail = check.Error
+			}
+			row(w, check.Name, check.OK, detail)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !report.OK {
+		return ErrUnhealthy
+	}
+	return nil
+}
@@ -8,8 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"time"
-
-	"github.com/lib/pq"
 )
 
 // ===== internal ===== //
@@ -29,7 +27,10 @@ func createAdminTable(db *sql.DB) error {
         status VARCHAR(50) NOT NULL
     );
 	
-	CREATE INDEX IF NOT EXISTS idx_admins_email on admins (email);`
+	CREATE INDEX IF NOT EXISTS idx_admins_email on admins (email);
+
+	-- sessions issued before this time are rejected, set when offboarding an admin
+	ALTER TABLE admins ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMP WITH TIME ZONE;`
 
 	_, err := db.Exec(createAdminTableSQL)
 	if err != nil {
@@ -57,15 +58,13 @@ func (s *service) associateAdminWithEventTx(ctx context.Context, tx *sql.Tx, adm
 
 	_, err := tx.ExecContext(ctx, query, adminID, eventID)
 	if err != nil {
-		if err, ok := err.(*pq.Error); ok {
-			switch err.Code {
-			case "23503": // foreign_key_violation
-				return errors.New("invalid adminID or eventID")
-			default:
-				return errors.New("unknown error: " + err.Code.Name())
-			}
+		switch sqlState(err) {
+		case "23503": // foreign_key_violation
+			return errors.New("invalid adminID or eventID")
+		case "23505": // unique_violation
+			return errors.New("admin is already an author")
 		}
-		loggers.Error.Printf("Error associating admin with event: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error associating admin with event: %v", err)
 		return err
 	}
 
@@ -82,7 +81,7 @@ func (s *service) CreateAdmin(ctx context.Context, admin models.Admin) (string,
 	// validate admin email format and check if it already exists
 	err := SanitizeAdminInput(&admin)
 	if err != nil {
-		loggers.Debug.Printf("invalid admin input: %v", err)
+		loggers.Debug.Ctx(ctx).Printf("invalid admin input: %v", err)
 		return "", err
 	}
 
@@ -98,17 +97,13 @@ func (s *service) CreateAdmin(ctx context.Context, admin models.Admin) (string,
 	).Scan(&id)
 
 	if err != nil {
-		if err, ok := err.(*pq.Error); ok {
-			switch err.Code {
-			case "23505": // unique_violation
-				return "", errors.New("email already exists")
-			case "23503": // foreign_key_violation
-				return "", errors.New("foreign key violation")
-			default:
-				return "", errors.New("database error: " + err.Code.Name())
-			}
+		switch sqlState(err) {
+		case "23505": // unique_violation
+			return "", errors.New("email already exists")
+		case "23503": // foreign_key_violation
+			return "", errors.New("foreign key violation")
 		}
-		loggers.Error.Printf("Error creating admin: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error creating admin: %v", err)
 		return "", err
 	}
 
@@ -143,10 +138,10 @@ func (s *service) getAdmin(ctx context.Context, query string, param string) (*mo
 		&admin.DeletedAt, &admin.Name, &admin.Email, &admin.Position, &admin.Status)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			loggers.Error.Printf("No admin found with parameter: %v", param)
+			loggers.Error.Ctx(ctx).Printf("No admin found with parameter: %v", param)
 			return nil, fmt.Errorf("admin not found")
 		}
-		loggers.Error.Printf("Error getting admin: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error getting admin: %v", err)
 		return nil, err
 	}
 	return &admin, nil
@@ -155,6 +150,18 @@ func (s *service) getAdmin(ctx context.Context, query string, param string) (*mo
 // define a function type for fetching admins to use in generalizzed handler
 type AdminFetchFunc func(ctx context.Context, page, pageSize int) ([]models.Admin, error)
 
+// AdminFilter selects the admins a listing covers, CountAdmins takes the same
+// filter so totals match the pages. The first three are the values of the
+// deleted query param.
+type AdminFilter string
+
+const (
+	AdminsActive        AdminFilter = "exclude"
+	AdminsWithDeleted   AdminFilter = "include"
+	AdminsDeleted       AdminFilter = "only"
+	AdminsExceptFounder AdminFilter = "except-founder"
+)
+
 func (s *service) GetAllAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
 	offset := getOffset(page, pageSize)
 	const query = `
@@ -166,7 +173,7 @@ func (s *service) GetAllAdmins(ctx context.Context, page, pageSize int) ([]model
 	LIMIT $1 OFFSET $2`
 	rows, err := s.db.QueryContext(ctx, query, pageSize, offset)
 	if err != nil {
-		loggers.Error.Printf("getting admins: %v", err)
+		loggers.Error.Ctx(ctx).Printf("getting admins: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -184,7 +191,42 @@ func (s *service) GetAllAdminsExceptFounder(ctx context.Context, page, pageSize
 	LIMIT $1 OFFSET $2`
 	rows, err := s.db.QueryContext(ctx, query, pageSize, offset)
 	if err != nil {
-		loggers.Error.Printf("getting admins: %v", err)
+		loggers.Error.Ctx(ctx).Printf("getting admins: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAdmins(rows)
+}
+
+func (s *service) GetAllAdminsIncludingDeleted(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	offset := getOffset(page, pageSize)
+	const query = `
+	SELECT id, created_at, updated_at, deleted_at, name, email, position, status
+	FROM admins
+	ORDER BY created_at DESC
+	LIMIT $1 OFFSET $2`
+	rows, err := s.db.QueryContext(ctx, query, pageSize, offset)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting admins: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAdmins(rows)
+}
+
+func (s *service) GetDeletedAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	offset := getOffset(page, pageSize)
+	const query = `
+	SELECT id, created_at, updated_at, deleted_at, name, email, position, status
+	FROM admins
+	WHERE deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC
+	LIMIT $1 OFFSET $2`
+	rows, err := s.db.QueryContext(ctx, query, pageSize, offset)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting deleted admins: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -194,6 +236,9 @@ func (s *service) GetAllAdminsExceptFounder(ctx context.Context, page, pageSize
 
 // fetch admin by field: email or id exclusively
 func (s *service) GetAdmin(ctx context.Context, field, value string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
 	query := fmt.Sprintf(`
     SELECT id, created_at, updated_at, deleted_at, name, email, position, status 
     FROM admins 
@@ -203,104 +248,242 @@ func (s *service) GetAdmin(ctx context.Context, field, value string) (*models.Ad
 	return s.getAdmin(ctx, query, value)
 }
 
+// GetAdminCount counts the admins that aren't deleted
 func (s *service) GetAdminCount(ctx context.Context) (int, error) {
-	const query = `SELECT COUNT(*) FROM admins`
+	const query = `SELECT COUNT(*) FROM admins WHERE deleted_at IS NULL`
 	row := s.db.QueryRowContext(ctx, query)
 	var count int
 	err := row.Scan(&count)
 	if err != nil {
-		loggers.Error.Printf("Error getting admin count: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error getting admin count: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountAdmins counts the admins the listing for filter pages through
+func (s *service) CountAdmins(ctx context.Context, filter AdminFilter) (int, error) {
+	var where string
+	switch filter {
+	case AdminsActive:
+		where = "WHERE deleted_at IS NULL"
+	case AdminsWithDeleted:
+		where = ""
+	case AdminsDeleted:
+		where = "WHERE deleted_at IS NOT NULL"
+	case AdminsExceptFounder:
+		where = "WHERE position != 'Founder' AND deleted_at IS NULL"
+	default:
+		return 0, errors.New("invalid admin filter")
+	}
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM admins "+where).Scan(&count); err != nil {
+		loggers.Error.Ctx(ctx).Printf("counting admins: %v", err)
 		return 0, err
 	}
 	return count, nil
 }
 
+// AdminSessionValid reports whether a session issued at issuedAt still belongs
+// to an admin that exists, isn't deleted and hasn't had its sessions revoked
+func (s *service) AdminSessionValid(ctx context.Context, adminID string, issuedAt time.Time) (bool, error) {
+	const query = `
+	SELECT EXISTS (
+		SELECT 1 FROM admins
+		WHERE id = $1 AND deleted_at IS NULL
+		AND (sessions_revoked_at IS NULL OR sessions_revoked_at < $2)
+	)`
+
+	var valid bool
+	if err := s.db.QueryRowContext(ctx, query, adminID, issuedAt).Scan(&valid); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error checking admin session: %v", err)
+		return false, err
+	}
+	return valid, nil
+}
+
 // ========== UPDATE ========== //
 
+// UpdateAdmin updates the name, email, position and status of an admin. The
+// permanent founder keeps their email, position and status.
 func (s *service) UpdateAdmin(ctx context.Context, admin models.Admin) error {
-	err := SanitizeAdminInput(&admin)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		loggers.Debug.Printf("invalid admin input: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
 		return err
 	}
+	defer tx.Rollback()
+
+	// a missing admin is left as is, the update below changes no rows
+	var current models.Admin
+	err = tx.QueryRowContext(ctx, `
+	SELECT email, position, status FROM admins
+	WHERE id = $1 AND deleted_at IS NULL
+	FOR UPDATE`, admin.ID,
+	).Scan(&current.Email, &current.Position, &current.Status)
+	if err != nil && err != sql.ErrNoRows {
+		loggers.Error.Ctx(ctx).Printf("Error getting admin: %v", err)
+		return err
+	}
+	if err := sanitizeAdminUpdate(current, &admin); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid admin input: %v", err)
+		return err
+	}
+
 	const query = `
 	UPDATE admins SET
 	updated_at = $1, name = $2, email = $3, position = $4, status = $5
 	WHERE id = $6
 	AND deleted_at IS NULL`
 
-	_, err = s.db.ExecContext(
+	_, err = tx.ExecContext(
 		ctx, query, time.Now(), admin.Name, admin.Email, admin.Position, admin.Status, admin.ID,
 	)
 	if err != nil {
-		if err, ok := err.(*pq.Error); ok {
-			switch err.Code {
-			case "23505": // unique_violation
-				return errors.New("email already exists")
-			default:
-				return errors.New("database error: " + err.Code.Name())
-			}
+		if sqlState(err) == "23505" { // unique_violation
+			return errors.New("email already exists")
 		}
-		loggers.Error.Printf("updated admin: %v", err)
+		loggers.Error.Ctx(ctx).Printf("updated admin: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing transaction: %v", err)
 		return err
 	}
 	return nil
 }
 
-// TODO: everything below this line needs to be refactored
+// ResetFounder puts the founder back the way the schema created them:
+// undeleted, permanent and with the original name and position. Their
+// sessions are revoked in case the account was tampered with.
+func ResetFounder(ctx context.Context, db *sql.DB) (*models.Admin, error) {
+	const query = `
+    INSERT INTO admins (name, email, position, status, created_at, updated_at)
+    VALUES ('Jiating', 'jiating.lion.dragon@gmail.com', 'Founder', 'permanent', NOW(), NOW())
+    ON CONFLICT (email) DO UPDATE SET
+        name = EXCLUDED.name, position = EXCLUDED.position, status = EXCLUDED.status,
+        deleted_at = NULL, sessions_revoked_at = NOW(), updated_at = NOW()
+    RETURNING id, created_at, updated_at, deleted_at, name, email, position, status`
+
+	var admin models.Admin
+	err := db.QueryRowContext(ctx, query).Scan(
+		&admin.ID, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.DeletedAt, &admin.Name, &admin.Email, &admin.Position, &admin.Status)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error resetting founder: %v", err)
+		return nil, err
+	}
+	return &admin, nil
+}
 
 // ========== DELETE ========== //
 
-func (s *service) DeleteAdmin(ctx context.Context, param string) (models.Admin, error) {
-	return models.Admin{}, nil
+// DeleteAdmin soft deletes an admin by field: email or id exclusively. The
+// permanent founder can't be deleted. Sessions of the admin are revoked and
+// their event_authors rows are moved to the admin identified by reassignTo
+// (id or email), or kept as they are when reassignTo is empty.
+func (s *service) DeleteAdmin(ctx context.Context, field, value, reassignTo string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var admin models.Admin
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+	SELECT id, created_at, updated_at, deleted_at, name, email, position, status
+	FROM admins
+	WHERE %s = $1 AND deleted_at IS NULL
+	FOR UPDATE`, field), value,
+	).Scan(&admin.ID, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.DeletedAt, &admin.Name, &admin.Email, &admin.Position, &admin.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("admin not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting admin: %v", err)
+		return nil, err
+	}
+	if admin.Status == "permanent" {
+		return nil, errors.New("cannot delete a permanent admin")
+	}
+
+	if reassignTo != "" {
+		var targetID string
+		err = tx.QueryRowContext(ctx, `
+		SELECT id FROM admins
+		WHERE (id::text = $1 OR email = lower($1)) AND deleted_at IS NULL`, reassignTo,
+		).Scan(&targetID)
+		if err == sql.ErrNoRows || targetID == admin.ID {
+			return nil, errors.New("invalid reassignment admin")
+		}
+		if err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error getting reassignment admin: %v", err)
+			return nil, err
+		}
+
+		// events both admins authored would violate the primary key, keep one row
+		if _, err := tx.ExecContext(ctx, `
+		INSERT INTO event_authors (admin_id, event_id)
+		SELECT $1, event_id FROM event_authors WHERE admin_id = $2
+		ON CONFLICT DO NOTHING`, targetID, admin.ID); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error reassigning event authors: %v", err)
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM event_authors WHERE admin_id = $1`, admin.ID); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error removing event authors: %v", err)
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `
+	UPDATE admins SET
+	updated_at = $1, deleted_at = $1, sessions_revoked_at = $1
+	WHERE id = $2`, now, admin.ID); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error deleting admin: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing transaction: %v", err)
+		return nil, err
+	}
+
+	admin.UpdatedAt = now
+	admin.DeletedAt = &now
+	return &admin, nil
 }
 
-// func (s *service) DeleteAdminByID(adminID string) error {
-// 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-// 	defer cancel()
-
-// 	const softDeleteAdminQuery = `
-// 	UPDATE admins
-// 	SET deleted_at = $1
-// 	WHERE id = $2
-// 		AND status != 'permanent'
-// 	RETURNING id`
-
-// 	var deletedAdminID string
-// 	err := s.db.QueryRowContext(ctx, softDeleteAdminQuery, time.Now(), adminID).Scan(&deletedAdminID)
-// 	if err != nil {
-// 		if err == sql.ErrNoRows {
-// 			// either the admin is permanent or does not exist
-// 			return fmt.Errorf("cannot delete admin: admin is either permanent or does not exist")
-// 		}
-// 		loggers.Error.Printf("Error deleting admin: %v", err)
-// 		return err
-// 	}
-
-// 	return nil
-// }
-
-// func (s *service) DeleteAdminByEmail(adminEmail string) error {
-// 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-// 	defer cancel()
-
-// 	const softDeleteAdminQuery = `
-// 	UPDATE admins
-// 	SET deleted_at = $1
-// 	WHERE email = $2
-// 		AND status != 'permanent'
-// 	RETURNING id`
-
-// 	var deletedAdminID string
-// 	err := s.db.QueryRowContext(ctx, softDeleteAdminQuery, time.Now(), adminEmail).Scan(&deletedAdminID)
-// 	if err != nil {
-// 		if err == sql.ErrNoRows {
-// 			// either the admin is permanent or does not exist
-// 			return fmt.Errorf("cannot delete admin: admin is either permanent or does not exist")
-// 		}
-// 		loggers.Error.Printf("Error deleting admin: %v", err)
-// 		return err
-// 	}
-
-// 	return nil
-// }
+// RestoreAdmin undoes a soft delete by field: email or id exclusively.
+// Sessions revoked by the deletion stay revoked.
+func (s *service) RestoreAdmin(ctx context.Context, field, value string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
+
+	query := fmt.Sprintf(`
+	UPDATE admins SET
+	updated_at = $1, deleted_at = NULL
+	WHERE %s = $2 AND deleted_at IS NOT NULL
+	RETURNING id, created_at, updated_at, deleted_at, name, email, position, status`, field)
+
+	var admin models.Admin
+	err := s.db.QueryRowContext(ctx, query, time.Now(), value).Scan(
+		&admin.ID, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.DeletedAt, &admin.Name, &admin.Email, &admin.Position, &admin.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("deleted admin not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error restoring admin: %v", err)
+		return nil, err
+	}
+	return &admin, nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createAPITokenTable(db *sql.DB) error {
+	createAPITokenTableSQL := `
+    CREATE TABLE IF NOT EXISTS api_tokens (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+        name VARCHAR(255) NOT NULL,
+        prefix VARCHAR(20) NOT NULL,
+        token_hash VARCHAR(64) NOT NULL UNIQUE,
+        scopes VARCHAR(1000) NOT NULL,
+        expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        last_used_at TIMESTAMP WITH TIME ZONE,
+        revoked_at TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_api_tokens_admin_id ON api_tokens(admin_id);`
+
+	_, err := db.Exec(createAPITokenTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating api token table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ===== external ===== //
+
+// ========== CREATE ========== //
+
+// CreateAPIToken stores a personal access token by its hash, the token itself
+// is only ever shown to the admin when it is created
+func (s *service) CreateAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (string, error) {
+	if err := SanitizeAPITokenInput(&token); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid api token input: %v", err)
+		return "", err
+	}
+
+	const query = `
+	INSERT INTO api_tokens (
+		created_at, admin_id, name, prefix, token_hash, scopes, expires_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	var id string
+	err := s.db.QueryRowContext(ctx, query, time.Now(), token.AdminID, token.Name,
+		token.Prefix, tokenHash, strings.Join(token.Scopes, ","), token.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		if sqlState(err) == "23503" { // foreign_key_violation
+			return "", errors.New("invalid admin id")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error creating api token: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// ========== READ ========== //
+
+func (s *service) GetAPITokens(ctx context.Context, adminID string) ([]models.APIToken, error) {
+	const query = `
+	SELECT id, created_at, admin_id, name, prefix, scopes, expires_at, last_used_at, revoked_at
+	FROM api_tokens
+	WHERE admin_id = $1
+	ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, adminID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting api tokens: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		var token models.APIToken
+		var scopes string
+		if err := rows.Scan(&token.ID, &token.CreatedAt, &token.AdminID, &token.Name, &token.Prefix,
+			&scopes, &token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning api token: %v", err)
+			continue // skip partial results
+		}
+		token.Scopes = strings.Split(scopes, ",")
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over api tokens: %v", err)
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// AuthenticateAPIToken returns the token matching tokenHash if it is neither
+// expired nor revoked and its admin isn't deleted, recording it as used
+func (s *service) AuthenticateAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	const query = `
+	UPDATE api_tokens t SET last_used_at = $1
+	FROM admins a
+	WHERE a.id = t.admin_id AND a.deleted_at IS NULL
+	AND t.token_hash = $2 AND t.revoked_at IS NULL AND t.expires_at > $1
+	RETURNING t.id, t.created_at, t.admin_id, t.name, t.prefix, t.scopes, t.expires_at, t.last_used_at`
+
+	var token models.APIToken
+	var scopes string
+	err := s.db.QueryRowContext(ctx, query, time.Now(), tokenHash).Scan(
+		&token.ID, &token.CreatedAt, &token.AdminID, &token.Name, &token.Prefix,
+		&scopes, &token.ExpiresAt, &token.LastUsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invalid or expired token")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error authenticating api token: %v", err)
+		return nil, err
+	}
+	token.Scopes = strings.Split(scopes, ",")
+	return &token, nil
+}
+
+// ========== DELETE ========== //
+
+// RevokeAPIToken revokes a token of the given admin, revoked tokens are kept
+// so they still show up in the token list
+func (s *service) RevokeAPIToken(ctx context.Context, adminID, id string) error {
+	const query = `
+	UPDATE api_tokens SET revoked_at = $1
+	WHERE id = $2 AND admin_id = $3 AND revoked_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), id, adminID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("revoking api token: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("api token not found")
+	}
+	return nil
+}
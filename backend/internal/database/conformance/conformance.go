@@ -0,0 +1,645 @@
+// Package conformance checks that implementations of the database
+// repositories behave the same, so tests running against the in-memory
+// implementation hold against postgres too.
+package conformance
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Opener returns empty repositories for one test: only the founder admin
+// exists. It is called once per subtest so tests don't see each other's rows.
+type Opener func(t *testing.T) database.Repositories
+
+// Run runs the whole suite against the repositories open returns
+func Run(t *testing.T, open Opener) {
+	t.Run("Admins", func(t *testing.T) { runAdmins(t, open) })
+	t.Run("Events", func(t *testing.T) { runEvents(t, open) })
+	t.Run("Images", func(t *testing.T) { runImages(t, open) })
+	t.Run("Authors", func(t *testing.T) { runAuthors(t, open) })
+}
+
+// ===== helpers ===== //
+
+func createAdmin(t *testing.T, repos database.Repositories, name string) string {
+	t.Helper()
+	id, err := repos.Admins.CreateAdmin(context.Background(), models.Admin{
+		Name: name, Email: name + "@gmail.com", Position: "Member", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("creating admin %s: %v", name, err)
+	}
+	return id
+}
+
+func createEvent(t *testing.T, repos database.Repositories, slug, adminID string, images ...models.EventImage) string {
+	t.Helper()
+	id, err := repos.Events.CreateEvent(context.Background(), newEvent(slug, images...), adminID)
+	if err != nil {
+		t.Fatalf("creating event %s: %v", slug, err)
+	}
+	return id
+}
+
+func newEvent(slug string, images ...models.EventImage) models.Event {
+	return models.Event{
+		EventTitle:  "Lion Dance " + slug,
+		Metatitle:   "Lion Dance " + slug,
+		Slug:        slug,
+		Date:        time.Date(2026, time.February, 17, 18, 0, 0, 0, time.UTC),
+		Description: "Performance at the " + slug,
+		Content:     "https://youtube.com/watch?v=" + slug,
+		Images:      images,
+	}
+}
+
+func adminIDs(admins []models.Admin) []string {
+	ids := make([]string, 0, len(admins))
+	for _, a := range admins {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func imageIDs(images []models.EventImage) []string {
+	ids := make([]string, 0, len(images))
+	for _, img := range images {
+		ids = append(ids, img.ID)
+	}
+	return ids
+}
+
+// displayImages returns the ids of the images marked as display image
+func displayImages(images []models.EventImage) []string {
+	var ids []string
+	for _, img := range images {
+		if img.IsDisplay {
+			ids = append(ids, img.ID)
+		}
+	}
+	return ids
+}
+
+func assertNewestFirst(t *testing.T, admins []models.Admin) {
+	t.Helper()
+	assert.True(t, sort.SliceIsSorted(admins, func(i, j int) bool {
+		return admins[i].CreatedAt.After(admins[j].CreatedAt)
+	}), "admins should be listed newest first")
+}
+
+func assertError(t *testing.T, err error, msg string) {
+	t.Helper()
+	if assert.Error(t, err) {
+		assert.Equal(t, msg, err.Error())
+	}
+}
+
+// ===== admins ===== //
+
+func runAdmins(t *testing.T, open Opener) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := open(t)
+		before := time.Now().Add(-time.Second)
+
+		id, err := repos.Admins.CreateAdmin(ctx, models.Admin{
+			Name: " Mei <Lin> ", Email: "mei@gmail.com", Position: "Treasurer", Status: "active",
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		byID, err := repos.Admins.GetAdmin(ctx, "id", id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, byID.ID)
+			assert.Equal(t, "Mei &lt;Lin&gt;", byID.Name, "names are trimmed and escaped")
+			assert.Equal(t, "mei@gmail.com", byID.Email)
+			assert.Equal(t, "Treasurer", byID.Position)
+			assert.Equal(t, "active", byID.Status)
+			assert.Nil(t, byID.DeletedAt)
+			assert.True(t, byID.CreatedAt.After(before))
+			assert.True(t, byID.UpdatedAt.Equal(byID.CreatedAt))
+		}
+
+		byEmail, err := repos.Admins.GetAdmin(ctx, "email", "mei@gmail.com")
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, byEmail.ID)
+		}
+	})
+
+	t.Run("CreateValidates", func(t *testing.T) {
+		repos := open(t)
+		createAdmin(t, repos, "mei")
+
+		_, err := repos.Admins.CreateAdmin(ctx, models.Admin{Name: "Mei", Email: "mei@gmail.com", Status: "active"})
+		assertError(t, err, "email already exists")
+		_, err = repos.Admins.CreateAdmin(ctx, models.Admin{Name: "Mei", Email: "not-an-email", Status: "active"})
+		assertError(t, err, "invalid email format")
+		_, err = repos.Admins.CreateAdmin(ctx, models.Admin{Name: "Mei", Email: "mei2@gmail.com", Status: "retired"})
+		assertError(t, err, "invalid admin status")
+
+		count, err := repos.Admins.GetAdminCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count, "only the founder and the first admin exist")
+	})
+
+	t.Run("GetErrors", func(t *testing.T) {
+		repos := open(t)
+
+		_, err := repos.Admins.GetAdmin(ctx, "id", uuid.NewString())
+		assertError(t, err, "admin not found")
+		_, err = repos.Admins.GetAdmin(ctx, "email", "nobody@gmail.com")
+		assertError(t, err, "admin not found")
+		_, err = repos.Admins.GetAdmin(ctx, "name", "Jiating")
+		assertError(t, err, "invalid admin field")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := open(t)
+		var ids []string
+		for _, name := range []string{"ana", "bo", "cy"} {
+			ids = append(ids, createAdmin(t, repos, name))
+		}
+
+		all, err := repos.Admins.GetAllAdmins(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, all, 4, "the founder is listed too")
+		assert.Subset(t, adminIDs(all), ids)
+		assertNewestFirst(t, all)
+
+		team, err := repos.Admins.GetAllAdminsExceptFounder(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, ids, adminIDs(team))
+
+		first, err := repos.Admins.GetAllAdmins(ctx, 1, 3)
+		assert.NoError(t, err)
+		second, err := repos.Admins.GetAllAdmins(ctx, 2, 3)
+		assert.NoError(t, err)
+		assert.Len(t, first, 3)
+		assert.Len(t, second, 1)
+		assert.ElementsMatch(t, adminIDs(all), append(adminIDs(first), adminIDs(second)...))
+
+		past, err := repos.Admins.GetAllAdmins(ctx, 3, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, past)
+
+		count, err := repos.Admins.GetAdminCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, count)
+		count, err = repos.Admins.CountAdmins(ctx, database.AdminsExceptFounder)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		_, err = repos.Admins.CountAdmins(ctx, "everyone")
+		assertError(t, err, "invalid admin filter")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := open(t)
+		id := createAdmin(t, repos, "mei")
+		createAdmin(t, repos, "bo")
+
+		err := repos.Admins.UpdateAdmin(ctx, models.Admin{
+			ID: id, Name: " Mei Lin ", Email: "meilin@gmail.com", Position: "President", Status: "hiatus",
+		})
+		assert.NoError(t, err)
+
+		admin, err := repos.Admins.GetAdmin(ctx, "id", id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Mei Lin", admin.Name)
+			assert.Equal(t, "meilin@gmail.com", admin.Email)
+			assert.Equal(t, "President", admin.Position)
+			assert.Equal(t, "hiatus", admin.Status)
+			assert.False(t, admin.UpdatedAt.Before(admin.CreatedAt))
+		}
+
+		err = repos.Admins.UpdateAdmin(ctx, models.Admin{
+			ID: id, Name: "Mei", Email: "bo@gmail.com", Position: "President", Status: "active",
+		})
+		assertError(t, err, "email already exists")
+	})
+
+	t.Run("UpdateFounder", func(t *testing.T) {
+		repos := open(t)
+		founder, err := repos.Admins.GetAdmin(ctx, "email", "jiating.lion.dragon@gmail.com")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, change := range []models.Admin{
+			{Name: "Jiating", Email: founder.Email, Position: founder.Position, Status: "active"},
+			{Name: "Jiating", Email: "someone@gmail.com", Position: founder.Position, Status: "permanent"},
+			{Name: "Jiating", Email: founder.Email, Position: "Member", Status: "permanent"},
+		} {
+			change.ID = founder.ID
+			err = repos.Admins.UpdateAdmin(ctx, change)
+			assertError(t, err, "cannot change a permanent admin")
+		}
+
+		err = repos.Admins.UpdateAdmin(ctx, models.Admin{
+			ID: founder.ID, Name: "Jiating Lin", Email: founder.Email, Position: founder.Position, Status: "permanent",
+		})
+		assert.NoError(t, err)
+		admin, err := repos.Admins.GetAdmin(ctx, "id", founder.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Jiating Lin", admin.Name)
+			assert.Equal(t, "permanent", admin.Status)
+		}
+	})
+
+	t.Run("DeleteAndRestore", func(t *testing.T) {
+		repos := open(t)
+		id := createAdmin(t, repos, "mei")
+		issued := time.Now()
+
+		valid, err := repos.Admins.AdminSessionValid(ctx, id, issued)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+
+		deleted, err := repos.Admins.DeleteAdmin(ctx, "email", "mei@gmail.com", "")
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, deleted.ID)
+			assert.NotNil(t, deleted.DeletedAt)
+		}
+
+		_, err = repos.Admins.GetAdmin(ctx, "id", id)
+		assertError(t, err, "admin not found")
+		_, err = repos.Admins.DeleteAdmin(ctx, "id", id, "")
+		assertError(t, err, "admin not found")
+
+		active, err := repos.Admins.GetAllAdmins(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.NotContains(t, adminIDs(active), id)
+		gone, err := repos.Admins.GetDeletedAdmins(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{id}, adminIDs(gone))
+		everyone, err := repos.Admins.GetAllAdminsIncludingDeleted(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Contains(t, adminIDs(everyone), id)
+		count, err := repos.Admins.GetAdminCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count, "deleted admins aren't counted")
+		for filter, want := range map[database.AdminFilter]int{
+			database.AdminsActive: 1, database.AdminsWithDeleted: 2, database.AdminsDeleted: 1,
+		} {
+			count, err = repos.Admins.CountAdmins(ctx, filter)
+			assert.NoError(t, err)
+			assert.Equal(t, want, count, "counting %s", filter)
+		}
+
+		valid, err = repos.Admins.AdminSessionValid(ctx, id, issued)
+		assert.NoError(t, err)
+		assert.False(t, valid, "sessions of deleted admins are invalid")
+
+		restored, err := repos.Admins.RestoreAdmin(ctx, "id", id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, restored.ID)
+			assert.Nil(t, restored.DeletedAt)
+		}
+		_, err = repos.Admins.RestoreAdmin(ctx, "id", id)
+		assertError(t, err, "deleted admin not found")
+
+		valid, err = repos.Admins.AdminSessionValid(ctx, id, issued)
+		assert.NoError(t, err)
+		assert.False(t, valid, "sessions revoked by the deletion stay revoked")
+		valid, err = repos.Admins.AdminSessionValid(ctx, id, time.Now().Add(time.Second))
+		assert.NoError(t, err)
+		assert.True(t, valid, "new sessions of a restored admin are valid")
+	})
+
+	t.Run("DeleteErrors", func(t *testing.T) {
+		repos := open(t)
+		id := createAdmin(t, repos, "mei")
+
+		_, err := repos.Admins.DeleteAdmin(ctx, "email", "jiating.lion.dragon@gmail.com", "")
+		assertError(t, err, "cannot delete a permanent admin")
+		_, err = repos.Admins.DeleteAdmin(ctx, "name", "mei", "")
+		assertError(t, err, "invalid admin field")
+		_, err = repos.Admins.DeleteAdmin(ctx, "id", id, id)
+		assertError(t, err, "invalid reassignment admin")
+		_, err = repos.Admins.DeleteAdmin(ctx, "id", id, "nobody@gmail.com")
+		assertError(t, err, "invalid reassignment admin")
+		_, err = repos.Admins.RestoreAdmin(ctx, "id", id)
+		assertError(t, err, "deleted admin not found")
+
+		_, err = repos.Admins.GetAdmin(ctx, "id", id)
+		assert.NoError(t, err, "failed deletions leave the admin alone")
+	})
+
+	t.Run("DeleteReassignsEvents", func(t *testing.T) {
+		repos := open(t)
+		leaving := createAdmin(t, repos, "mei")
+		staying := createAdmin(t, repos, "bo")
+		solo := createEvent(t, repos, "parade", leaving)
+		shared := createEvent(t, repos, "gala", leaving)
+		assert.NoError(t, repos.Authors.AddEventAuthor(ctx, shared, staying))
+
+		_, err := repos.Admins.DeleteAdmin(ctx, "id", leaving, "BO@gmail.com")
+		assert.NoError(t, err)
+
+		for _, eventID := range []string{solo, shared} {
+			authors, err := repos.Authors.GetEventAuthors(ctx, eventID)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{staying}, adminIDs(authors))
+		}
+	})
+
+	t.Run("DeleteKeepsAuthorship", func(t *testing.T) {
+		repos := open(t)
+		leaving := createAdmin(t, repos, "mei")
+		eventID := createEvent(t, repos, "parade", leaving)
+
+		_, err := repos.Admins.DeleteAdmin(ctx, "id", leaving, "")
+		assert.NoError(t, err)
+
+		authors, err := repos.Authors.GetEventAuthors(ctx, eventID)
+		assert.NoError(t, err)
+		if assert.Equal(t, []string{leaving}, adminIDs(authors)) {
+			assert.NotNil(t, authors[0].DeletedAt)
+		}
+	})
+}
+
+// ===== events ===== //
+
+func runEvents(t *testing.T, open Opener) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := open(t)
+		adminID := createAdmin(t, repos, "mei")
+
+		published := newEvent("parade")
+		id, err := repos.Events.CreateEvent(ctx, published, adminID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, uuid.Validate(id), "ids are generated when missing")
+
+		event, err := repos.Events.GetEventByID(ctx, id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, event.ID)
+			assert.Equal(t, published.EventTitle, event.EventTitle)
+			assert.Equal(t, published.Metatitle, event.Metatitle)
+			assert.Equal(t, published.Slug, event.Slug)
+			assert.True(t, published.Date.Equal(event.Date))
+			assert.Equal(t, published.Description, event.Description)
+			assert.Equal(t, published.Content, event.Content)
+			assert.False(t, event.IsDraft)
+			assert.NotNil(t, event.PublishedAt, "published events get a publish time")
+		}
+
+		draft := newEvent("gala")
+		draft.ID = uuid.NewString()
+		draft.IsDraft = true
+		draftAt := time.Now()
+		draft.PublishedAt = &draftAt
+		id, err = repos.Events.CreateEvent(ctx, draft, adminID)
+		assert.NoError(t, err)
+		assert.Equal(t, draft.ID, id, "given ids are kept")
+
+		event, err = repos.Events.GetEventByID(ctx, id)
+		if assert.NoError(t, err) {
+			assert.True(t, event.IsDraft)
+			assert.Nil(t, event.PublishedAt, "drafts aren't published")
+		}
+
+		authors, err := repos.Authors.GetEventAuthors(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{adminID}, adminIDs(authors), "the creating admin is the author")
+	})
+
+	t.Run("CreateErrors", func(t *testing.T) {
+		repos := open(t)
+		adminID := createAdmin(t, repos, "mei")
+		createEvent(t, repos, "parade", adminID)
+
+		_, err := repos.Events.CreateEvent(ctx, newEvent("parade"), adminID)
+		assertError(t, err, "event already exists")
+
+		orphan := newEvent("gala")
+		orphan.ID = uuid.NewString()
+		_, err = repos.Events.CreateEvent(ctx, orphan, uuid.NewString())
+		assertError(t, err, "invalid adminID or eventID")
+		_, err = repos.Events.GetEventByID(ctx, orphan.ID)
+		assertError(t, err, "event not found")
+
+		// a failing image rolls back the event and the images before it
+		taken := uuid.NewString()
+		broken := newEvent("festival", models.EventImage{ID: taken, ImageURL: "a.jpg"}, models.EventImage{ID: taken, ImageURL: "b.jpg"})
+		broken.ID = uuid.NewString()
+		_, err = repos.Events.CreateEvent(ctx, broken, adminID)
+		assertError(t, err, "image already exists")
+		_, err = repos.Events.GetEventByID(ctx, broken.ID)
+		assertError(t, err, "event not found")
+		_, err = repos.Events.CreateEvent(ctx, newEvent("festival", models.EventImage{ID: taken, ImageURL: "a.jpg"}), adminID)
+		assert.NoError(t, err, "nothing of the failed event is left behind")
+	})
+
+	t.Run("CreateWithImages", func(t *testing.T) {
+		repos := open(t)
+		adminID := createAdmin(t, repos, "mei")
+		start := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+		eventID := createEvent(t, repos, "parade", adminID,
+			models.EventImage{ImageURL: "a.jpg", AltText: "lion", CreatedAt: start, IsDisplay: true},
+			models.EventImage{ImageURL: "b.jpg", AltText: "drum", CreatedAt: start.Add(time.Minute), IsDisplay: true},
+			models.EventImage{ImageURL: "c.jpg", AltText: "crowd", CreatedAt: start.Add(2 * time.Minute)},
+		)
+
+		images, err := repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		if assert.Len(t, images, 3) {
+			assert.Equal(t, "a.jpg", images[0].ImageURL, "images are listed oldest first")
+			assert.Equal(t, "lion", images[0].AltText)
+			assert.True(t, start.Equal(images[0].CreatedAt))
+			assert.Equal(t, []string{images[1].ID}, displayImages(images), "the last display image wins")
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		repos := open(t)
+		_, err := repos.Events.GetEventByID(ctx, uuid.NewString())
+		assertError(t, err, "event not found")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := open(t)
+		adminID := createAdmin(t, repos, "mei")
+		for i, slug := range []string{"parade", "gala", "festival"} {
+			event := newEvent(slug)
+			event.Date = event.Date.AddDate(0, 0, i)
+			event.IsDraft = slug == "gala"
+			_, err := repos.Events.CreateEvent(ctx, event, adminID)
+			assert.NoError(t, err)
+		}
+
+		first, err := repos.Events.GetAllEvents(ctx, 1, 2)
+		assert.NoError(t, err)
+		second, err := repos.Events.GetAllEvents(ctx, 2, 2)
+		assert.NoError(t, err)
+		var listed []string
+		for _, event := range append(first, second...) {
+			listed = append(listed, event.Slug)
+		}
+		assert.Equal(t, []string{"festival", "gala", "parade"}, listed, "latest date first, drafts included")
+
+		past, err := repos.Events.GetAllEvents(ctx, 3, 2)
+		assert.NoError(t, err)
+		assert.Empty(t, past)
+	})
+}
+
+// ===== images ===== //
+
+func runImages(t *testing.T, open Opener) {
+	ctx := context.Background()
+
+	t.Run("AddAndList", func(t *testing.T) {
+		repos := open(t)
+		eventID := createEvent(t, repos, "parade", createAdmin(t, repos, "mei"))
+		otherID := createEvent(t, repos, "gala", createAdmin(t, repos, "bo"))
+
+		empty, err := repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		assert.NotNil(t, empty, "no images is an empty list")
+		assert.Empty(t, empty)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			id, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: fmt.Sprintf("%d.jpg", i)})
+			assert.NoError(t, err)
+			assert.NoError(t, uuid.Validate(id))
+			ids = append(ids, id)
+		}
+		_, err = repos.Images.AddEventImage(ctx, otherID, models.EventImage{ImageURL: "other.jpg"})
+		assert.NoError(t, err)
+
+		images, err := repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, ids, imageIDs(images))
+		assert.True(t, sort.SliceIsSorted(images, func(i, j int) bool {
+			return images[i].CreatedAt.Before(images[j].CreatedAt)
+		}))
+		assert.Empty(t, displayImages(images))
+	})
+
+	t.Run("Display", func(t *testing.T) {
+		repos := open(t)
+		eventID := createEvent(t, repos, "parade", createAdmin(t, repos, "mei"))
+		otherID := createEvent(t, repos, "gala", createAdmin(t, repos, "bo"))
+
+		_, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "a.jpg", IsDisplay: true})
+		assert.NoError(t, err)
+		second, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "b.jpg"})
+		assert.NoError(t, err)
+		other, err := repos.Images.AddEventImage(ctx, otherID, models.EventImage{ImageURL: "c.jpg", IsDisplay: true})
+		assert.NoError(t, err)
+
+		assert.NoError(t, repos.Images.SetDisplayImage(ctx, eventID, second))
+		images, err := repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{second}, displayImages(images))
+
+		third, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "d.jpg", IsDisplay: true})
+		assert.NoError(t, err)
+		images, err = repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{third}, displayImages(images))
+
+		assertError(t, repos.Images.SetDisplayImage(ctx, eventID, other), "image not found")
+		assertError(t, repos.Images.SetDisplayImage(ctx, eventID, uuid.NewString()), "image not found")
+
+		images, err = repos.Images.GetEventImages(ctx, otherID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{other}, displayImages(images), "other events keep their display image")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		repos := open(t)
+		eventID := createEvent(t, repos, "parade", createAdmin(t, repos, "mei"))
+		otherID := createEvent(t, repos, "gala", createAdmin(t, repos, "bo"))
+
+		keep, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "a.jpg"})
+		assert.NoError(t, err)
+		drop, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "b.jpg"})
+		assert.NoError(t, err)
+
+		assertError(t, repos.Images.RemoveEventImage(ctx, otherID, drop), "image not found")
+		assert.NoError(t, repos.Images.RemoveEventImage(ctx, eventID, drop))
+		assertError(t, repos.Images.RemoveEventImage(ctx, eventID, drop), "image not found")
+
+		images, err := repos.Images.GetEventImages(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{keep}, imageIDs(images))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		repos := open(t)
+		eventID := createEvent(t, repos, "parade", createAdmin(t, repos, "mei"))
+
+		_, err := repos.Images.AddEventImage(ctx, uuid.NewString(), models.EventImage{ImageURL: "a.jpg"})
+		assertError(t, err, "event not found")
+
+		id, err := repos.Images.AddEventImage(ctx, eventID, models.EventImage{ImageURL: "a.jpg"})
+		assert.NoError(t, err)
+		_, err = repos.Images.AddEventImage(ctx, eventID, models.EventImage{ID: id, ImageURL: "b.jpg"})
+		assertError(t, err, "image already exists")
+	})
+}
+
+// ===== authors ===== //
+
+func runAuthors(t *testing.T, open Opener) {
+	ctx := context.Background()
+
+	t.Run("AddListRemove", func(t *testing.T) {
+		repos := open(t)
+		creator := createAdmin(t, repos, "mei")
+		editor := createAdmin(t, repos, "bo")
+		eventID := createEvent(t, repos, "parade", creator)
+		otherID := createEvent(t, repos, "gala", editor)
+
+		assert.NoError(t, repos.Authors.AddEventAuthor(ctx, eventID, editor))
+		authors, err := repos.Authors.GetEventAuthors(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{creator, editor}, adminIDs(authors), "authors are listed by when they joined")
+		if assert.Len(t, authors, 2) {
+			assert.Equal(t, "mei@gmail.com", authors[0].Email)
+		}
+
+		assert.NoError(t, repos.Authors.RemoveEventAuthor(ctx, eventID, creator))
+		authors, err = repos.Authors.GetEventAuthors(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{editor}, adminIDs(authors))
+
+		authors, err = repos.Authors.GetEventAuthors(ctx, otherID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{editor}, adminIDs(authors), "other events are untouched")
+
+		none, err := repos.Authors.GetEventAuthors(ctx, uuid.NewString())
+		assert.NoError(t, err)
+		assert.NotNil(t, none, "no authors is an empty list")
+		assert.Empty(t, none)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		repos := open(t)
+		adminID := createAdmin(t, repos, "mei")
+		eventID := createEvent(t, repos, "parade", adminID)
+
+		assertError(t, repos.Authors.AddEventAuthor(ctx, eventID, adminID), "admin is already an author")
+		assertError(t, repos.Authors.AddEventAuthor(ctx, eventID, uuid.NewString()), "invalid adminID or eventID")
+		assertError(t, repos.Authors.AddEventAuthor(ctx, uuid.NewString(), adminID), "invalid adminID or eventID")
+		assertError(t, repos.Authors.RemoveEventAuthor(ctx, eventID, uuid.NewString()), "author not found")
+	})
+}
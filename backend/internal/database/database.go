@@ -1,121 +1,222 @@
 package database
 
 import (
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/loggers"
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
-	_ "github.com/joho/godotenv/autoload"
 )
 
 type Service interface {
-	Health() map[string]string
+	// lifecycle and health
+	Ping(ctx context.Context) error
+	Stats() sql.DBStats
+	GetSchemaVersion(ctx context.Context) (int, error)
+	Close() error
 
 	// admin operations
-
-	// done
-	CreateAdmin(ctx context.Context, admin models.Admin) (string, error)
-
-	GetAllAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error)
-	GetAllAdminsExceptFounder(ctx context.Context, page, pageSize int) ([]models.Admin, error)
-	GetAdmin(ctx context.Context, field, value string) (*models.Admin, error)
-
-	GetAdminCount(ctx context.Context) (int, error)
-
-	UpdateAdmin(ctx context.Context, admin models.Admin) error
-	// needs revision
-	//TODO: deletion
+	AdminRepository
+
+	// admin identity operations
+	LinkAdminIdentity(ctx context.Context, identity models.AdminIdentity) error
+	ResolveAdminLogin(ctx context.Context, provider, providerUserID, email string, emailVerified bool) (*models.Admin, error)
+	GetAdminIdentities(ctx context.Context, adminID string) ([]models.AdminIdentity, error)
+	UnlinkAdminIdentity(ctx context.Context, adminID, provider, providerUserID string) error
+
+	// personal api token operations
+	CreateAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (string, error)
+	GetAPITokens(ctx context.Context, adminID string) ([]models.APIToken, error)
+	AuthenticateAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	RevokeAPIToken(ctx context.Context, adminID, id string) error
+
+	// rate limit operations, shared by every instance of the API
+	TakeRateLimitToken(ctx context.Context, key string, burst int, rate float64, now time.Time) (bool, float64, error)
+	DeleteIdleRateLimitBuckets(ctx context.Context, before time.Time) (int64, error)
+
+	// admin invitation operations
+	CreateAdminInvitation(ctx context.Context, invitation models.AdminInvitation, tokenHash string) (string, error)
+	GetAdminInvitations(ctx context.Context, status string) ([]models.AdminInvitation, error)
+	AcceptAdminInvitation(ctx context.Context, tokenHash, accountEmail string, bind bool) (*models.Admin, error)
+	RevokeAdminInvitation(ctx context.Context, id string) error
 
 	// event operations
-
-	// done
-	CreateEvent(ctx context.Context, event models.Event, adminID string) (string, error)
+	EventRepository
+	ImageRepository
+	AuthorRepository
 
 	// TODO: refactor
-	// GetAuthorsByEventID(eventID string) ([]models.Admin, error)
-	// CreateEvent(event models.Event, adminIDs []string) (string, error)
 	// UpdateEvent(event models.Event, editorAdminID string, newImages []models.EventImage, removedImageIDs []string, newDisplayImageID string) error
 	// UpdateEventByID(eventID string, req models.UpdateEventRequest) error
-	// GetEventByID(eventID string) (*models.Event, error)
 	// GetLastSevenPublishedEvents() ([]models.Event, error)
 
-	// event helpers
-	//UpdateDynamicEventFields(tx *sql.Tx, eventID string, updatedData map[string]interface{}) error
-	//UpdateEventAuthorship(tx *sql.Tx, eventID, editorAdminID string) error
-
-	// image operations
-	//AddImageToEvent(image models.EventImage, eventID string) error
-	//RemoveImageFromEvent(imageID string) error
-	//SetDisplayImageForEvent(imageID string, eventID string) error
+	// performance operations
+	CreatePerformance(ctx context.Context, performance models.Performance) (string, error)
+	GetPerformance(ctx context.Context, id string) (*models.Performance, error)
+	GetPerformances(ctx context.Context, from, to time.Time, publicOnly bool) ([]models.Performance, error)
+	UpdatePerformance(ctx context.Context, performance models.Performance) error
+	DeletePerformance(ctx context.Context, id string) error
+
+	// member operations
+	CreateMember(ctx context.Context, member models.Member) (string, error)
+	GetMember(ctx context.Context, id string) (*models.Member, error)
+	GetMembers(ctx context.Context, activeOnly bool) ([]models.Member, error)
+	UpdateMember(ctx context.Context, member models.Member) error
+	DeleteMember(ctx context.Context, id string) error
+
+	// practice and attendance operations
+	CreatePracticeSession(ctx context.Context, session models.PracticeSession) (string, error)
+	GetPracticeSession(ctx context.Context, id string) (*models.PracticeSession, error)
+	GetPracticeSessions(ctx context.Context, from, to time.Time) ([]models.PracticeSession, error)
+	UpdatePracticeSession(ctx context.Context, session models.PracticeSession) error
+	DeletePracticeSession(ctx context.Context, id string) error
+	RecordAttendance(ctx context.Context, sessionID, adminID string, records []models.AttendanceRecord) error
+	GetAttendanceReport(ctx context.Context, from, to time.Time) ([]models.AttendanceSummary, error)
+
+	// inventory operations
+	CreateInventoryItem(ctx context.Context, item models.InventoryItem) (string, error)
+	GetInventoryItem(ctx context.Context, id string) (*models.InventoryItem, error)
+	GetInventoryItems(ctx context.Context, category string) ([]models.InventoryItem, error)
+	UpdateInventoryItem(ctx context.Context, item models.InventoryItem) error
+	DeleteInventoryItem(ctx context.Context, id string) error
+	CheckOutItem(ctx context.Context, checkout models.ItemCheckout) (string, error)
+	CheckInItem(ctx context.Context, itemID string, req models.CheckInRequest) error
+	GetItemCheckouts(ctx context.Context, itemID string) ([]models.ItemCheckout, error)
+	GetOverdueCheckouts(ctx context.Context, asOf time.Time) ([]models.ItemCheckout, error)
+
+	// newsletter subscriber operations
+	CreatePendingSubscriber(ctx context.Context, email, name, tokenHash string, expiresAt time.Time) (bool, error)
+	GetConfirmedSubscribers(ctx context.Context) ([]models.Subscriber, error)
+	ConfirmSubscriber(ctx context.Context, tokenHash string) error
+	UnsubscribeSubscriber(ctx context.Context, email string) error
 }
 
 type service struct {
 	db *sql.DB
 }
 
-var (
-	database = os.Getenv("DB_DATABASE")
-	password = os.Getenv("DB_PASSWORD")
-	username = os.Getenv("DB_USERNAME")
-	port     = os.Getenv("DB_PORT")
-	host     = os.Getenv("DB_HOST")
-)
-
 func New(db *sql.DB) Service {
-	if db == nil {
-		// Create a real database connection if db is nil
-		connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", username, password, host, port, database)
-		var err error
-		db, err = sql.Open("pgx", connStr)
-		if err != nil {
-			loggers.Error.Fatalf("error connecting to the database: %v", err)
-		}
-
-		// Initialize tables
-		loggers.Debug.Println("initializing tables...")
-		if err := initTables(db); err != nil {
-			loggers.Error.Fatalf("error initializing tables: %v", err)
-		}
-	}
-
-	return &service{db: db}
+	return &traced{next: &service{db: db}}
+}
+
+// Connect opens the connection pool described by cfg and creates missing tables
+func Connect(cfg config.Database) (*sql.DB, error) {
+	return Open(cfg.DSN())
+}
+
+// Open is Connect for a connection string, tests use it for throwaway databases
+func Open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the database: %w", err)
+	}
+
+	// Initialize tables
+	loggers.Debug.Println("initializing tables...")
+	if err := initTables(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing tables: %w", err)
+	}
+
+	return db, nil
+}
+
+// Wipe deletes every row but the schema version and recreates the founder,
+// the seed command uses it to start over on a development database
+func Wipe(ctx context.Context, db *sql.DB) error {
+	const query = `TRUNCATE
+        admins, events, event_authors, event_images,
+        members, performances, performance_assignments, practice_sessions, attendance,
+        inventory_items, item_checkouts, subscribers,
+        admin_invitations, admin_identities, api_tokens, rate_limit_buckets
+    CASCADE`
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error wiping tables: %w", err)
+	}
+	return createAdminTable(db)
 }
 
 func initTables(db *sql.DB) error {
 	if err := createAdminTable(db); err != nil {
-		loggers.Error.Fatalf("error creating admins table: %v", err)
+		return fmt.Errorf("error creating admins table: %w", err)
 	}
 
 	if err := createEventTable(db); err != nil {
-		loggers.Error.Fatalf("error creating events table: %v", err)
+		return fmt.Errorf("error creating events table: %w", err)
 	}
 
 	if err := createEventAuthorTable(db); err != nil {
-		loggers.Error.Fatalf("error creating event authors table: %v", err)
+		return fmt.Errorf("error creating event authors table: %w", err)
 	}
 
 	if err := createImageTable(db); err != nil {
-		loggers.Error.Fatalf("error creating images table: %v", err)
+		return fmt.Errorf("error creating images table: %w", err)
 	}
 
-	return nil
-}
+	if err := createMemberTable(db); err != nil {
+		return fmt.Errorf("error creating members table: %w", err)
+	}
 
-func (s *service) Health() map[string]string {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+	if err := createPerformanceTable(db); err != nil {
+		return fmt.Errorf("error creating performances table: %w", err)
+	}
 
-	err := s.db.PingContext(ctx)
-	if err != nil {
-		loggers.Error.Fatalf(fmt.Sprintf("db down: %v", err))
+	if err := createPerformanceAssignmentTable(db); err != nil {
+		return fmt.Errorf("error creating performance assignments table: %w", err)
 	}
 
-	return map[string]string{
-		"message": "It's healthy",
+	if err := createPracticeSessionTable(db); err != nil {
+		return fmt.Errorf("error creating practice sessions table: %w", err)
 	}
+
+	if err := createAttendanceTable(db); err != nil {
+		return fmt.Errorf("error creating attendance table: %w", err)
+	}
+
+	if err := createInventoryItemTable(db); err != nil {
+		return fmt.Errorf("error creating inventory items table: %w", err)
+	}
+
+	if err := createItemCheckoutTable(db); err != nil {
+		return fmt.Errorf("error creating item checkouts table: %w", err)
+	}
+
+	if err := createSubscriberTable(db); err != nil {
+		return fmt.Errorf("error creating subscribers table: %w", err)
+	}
+
+	if err := createAdminInvitationTable(db); err != nil {
+		return fmt.Errorf("error creating admin invitations table: %w", err)
+	}
+
+	if err := createAdminIdentityTable(db); err != nil {
+		return fmt.Errorf("error creating admin identities table: %w", err)
+	}
+
+	if err := createAPITokenTable(db); err != nil {
+		return fmt.Errorf("error creating api tokens table: %w", err)
+	}
+
+	if err := createRateLimitBucketTable(db); err != nil {
+		return fmt.Errorf("error creating rate limit buckets table: %w", err)
+	}
+
+	if err := createSchemaMigrationTable(db); err != nil {
+		return fmt.Errorf("error creating schema migrations table: %w", err)
+	}
+
+	if err := recordSchemaVersion(db); err != nil {
+		return fmt.Errorf("error recording schema version: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the connection pool, in-flight queries finish first
+func (s *service) Close() error {
+	return s.db.Close()
 }
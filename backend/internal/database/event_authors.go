@@ -1,8 +1,11 @@
 package database
 
 import (
+	"backend/internal/models"
 	"backend/loggers"
+	"context"
 	"database/sql"
+	"errors"
 )
 
 func createEventAuthorTable(db *sql.DB) error {
@@ -28,8 +31,70 @@ func createEventAuthorTable(db *sql.DB) error {
 
 // ========== CREATE ========== //
 
+// AddEventAuthor makes an admin an author of an event
+func (s *service) AddEventAuthor(ctx context.Context, eventID, adminID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.associateAdminWithEventTx(ctx, tx, adminID, eventID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
 // ========== READ ========== //
 
+// GetEventAuthors returns the authors of an event in the order they joined
+// the team, deleted admins stay listed unless their events were reassigned
+func (s *service) GetEventAuthors(ctx context.Context, eventID string) ([]models.Admin, error) {
+	const query = `
+	SELECT a.id, a.created_at, a.updated_at, a.deleted_at, a.name, a.email, a.position, a.status
+	FROM admins a
+	INNER JOIN event_authors ea ON a.id = ea.admin_id
+	WHERE ea.event_id = $1
+	ORDER BY a.created_at, a.id`
+
+	rows, err := s.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting event authors: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors, err := scanAdmins(rows)
+	if err != nil {
+		return nil, err
+	}
+	if authors == nil {
+		authors = []models.Admin{}
+	}
+	return authors, nil
+}
+
 // ========== UPDATE ========== //
 
 // ========== DELETE ========== //
+
+// RemoveEventAuthor removes an admin from the authors of an event
+func (s *service) RemoveEventAuthor(ctx context.Context, eventID, adminID string) error {
+	const query = `DELETE FROM event_authors WHERE event_id = $1 AND admin_id = $2`
+
+	res, err := s.db.ExecContext(ctx, query, eventID, adminID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error removing event author: %v", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New("author not found")
+	}
+	return nil
+}
@@ -3,7 +3,12 @@ package database
 import (
 	"backend/internal/models"
 	"backend/loggers"
+	"context"
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // Initialize image table in database on startup
@@ -34,30 +39,137 @@ func createImageTable(db *sql.DB) error {
 // image metadata is created when an event is created
 // images are uploaded to s3 and the url is stored in the db
 
-// addImageToEventTx adds an image to an event in a transaction
-func (s *service) AddImageToEventTx(tx *sql.Tx, image models.EventImage, eventID string) error {
+// addImageToEventTx adds an image to an event in a transaction, a new display
+// image replaces the previous one
+func (s *service) addImageToEventTx(ctx context.Context, tx *sql.Tx, image models.EventImage, eventID string) (string, error) {
+	if image.ID == "" {
+		image.ID = uuid.NewString()
+	}
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = time.Now()
+	}
+
+	if image.IsDisplay {
+		const resetQuery = `UPDATE event_images SET is_display = false WHERE event_id = $1`
+		if _, err := tx.ExecContext(ctx, resetQuery, eventID); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error resetting display image: %v", err)
+			return "", err
+		}
+	}
+
 	const query = `
 	INSERT INTO event_images(
 		id, created_at, image_url, 
 		alt_text, is_display, event_id
 	) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := tx.Exec(
+	_, err := tx.ExecContext(ctx,
 		query, image.ID, image.CreatedAt, image.ImageURL,
 		image.AltText, image.IsDisplay, eventID)
 	if err != nil {
-		tx.Rollback()
-		loggers.Error.Printf("Error adding image to event: %v", err)
-		return err
+		switch sqlState(err) {
+		case "23503": // foreign_key_violation
+			return "", errors.New("event not found")
+		case "23505": // unique_violation
+			return "", errors.New("image already exists")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error adding image to event: %v", err)
+		return "", err
 	}
-	return nil
+	return image.ID, nil
+}
+
+// AddEventImage stores the metadata of an image already uploaded to s3
+func (s *service) AddEventImage(ctx context.Context, eventID string, image models.EventImage) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return "", err
+	}
+	defer tx.Rollback()
+
+	id, err := s.addImageToEventTx(ctx, tx, image, eventID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing transaction: %v", err)
+		return "", err
+	}
+	return id, nil
 }
 
 // ========== READ ========== //
 
+// GetEventImages returns the images of an event oldest first
+func (s *service) GetEventImages(ctx context.Context, eventID string) ([]models.EventImage, error) {
+	const query = `
+	SELECT id, created_at, image_url, alt_text, is_display
+	FROM event_images
+	WHERE event_id = $1
+	ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting event images: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []models.EventImage{}
+	for rows.Next() {
+		var img models.EventImage
+		if err := rows.Scan(&img.ID, &img.CreatedAt, &img.ImageURL, &img.AltText, &img.IsDisplay); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error scanning event image: %v", err)
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over event images: %v", err)
+		return nil, err
+	}
+	return images, nil
+}
+
 // ========== UPDATE ========== //
 
+// SetDisplayImage makes imageID the display image of its event
+func (s *service) SetDisplayImage(ctx context.Context, eventID, imageID string) error {
+	// a single statement so the event never has two display images
+	const query = `
+	UPDATE event_images SET is_display = (id = $2)
+	WHERE event_id = $1
+	AND EXISTS (SELECT 1 FROM event_images WHERE id = $2 AND event_id = $1)`
+
+	res, err := s.db.ExecContext(ctx, query, eventID, imageID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error setting display image: %v", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New("image not found")
+	}
+	return nil
+}
+
 // ========== DELETE ========== //
 
+// RemoveEventImage deletes the metadata of an image, the s3 object is left to the caller
+func (s *service) RemoveEventImage(ctx context.Context, eventID, imageID string) error {
+	const query = `DELETE FROM event_images WHERE id = $1 AND event_id = $2`
+
+	res, err := s.db.ExecContext(ctx, query, imageID, eventID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error removing event image: %v", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New("image not found")
+	}
+	return nil
+}
+
 // func (s *service) RemoveImageFromEvent(imageID string) error {
 // 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 // 	defer cancel()
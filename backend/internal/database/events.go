@@ -5,7 +5,11 @@ import (
 	"backend/loggers"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // ===== internal ===== //
@@ -43,18 +47,25 @@ func createEventTable(db *sql.DB) error {
 
 // Create operations:
 
+// CreateEvent stores the event with its images and makes adminID its author,
+// the id is generated when the event doesn't have one
 func (s *service) CreateEvent(ctx context.Context, event models.Event, adminID string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
 
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+
 	// start a transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		loggers.Error.Printf("Error starting transaction: %v", err)
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
 		return "", err
 	}
+	defer tx.Rollback()
 
-	// if the image is a draft, set the published_at field
+	// if the event isn't a draft, set the published_at field
 	currentTime := time.Now()
 	if !event.IsDraft {
 		event.PublishedAt = &currentTime
@@ -79,27 +90,29 @@ func (s *service) CreateEvent(ctx context.Context, event models.Event, adminID s
 		event.EventTitle, event.Metatitle, event.Slug, event.Date,
 		event.Description, event.Content, event.IsDraft, event.PublishedAt).
 		Scan(&eventID); err != nil {
-		tx.Rollback()
-		loggers.Error.Printf("Error inserting event: %v", err)
+		if sqlState(err) == "23505" { // unique_violation
+			return "", errors.New("event already exists")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error inserting event: %v", err)
 		return "", err
 	}
 
-	// associate admiin as author of event
-	s.associateAdminWithEventTx(ctx, tx, adminID, eventID) // error handling is done in the function
+	// associate admin as author of event
+	if err := s.associateAdminWithEventTx(ctx, tx, adminID, eventID); err != nil {
+		return "", err
+	}
 
 	// insert image metadata into images table
 	for _, img := range event.Images {
-		if err := s.AddImageToEventTx(tx, img, eventID); err != nil {
-			tx.Rollback()
-			loggers.Error.Printf("Error adding image to event: %v", err)
+		if _, err := s.addImageToEventTx(ctx, tx, img, eventID); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error adding image to event: %v", err)
 			return "", err
 		}
 	}
 
-	// TODO: handle display image
 	err = tx.Commit()
 	if err != nil {
-		loggers.Error.Printf("committing create event: %v", err)
+		loggers.Error.Ctx(ctx).Printf("committing create event: %v", err)
 		return "", err
 	}
 	return eventID, nil
@@ -107,40 +120,60 @@ func (s *service) CreateEvent(ctx context.Context, event models.Event, adminID s
 
 // Read Operations:
 
-// func (s *service) GetEventByID(eventID string) (*models.Event, error) {
-// 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-// 	defer cancel()
-
-// 	// fetch the event details
-// 	const getEventQuery = `SELECT id, created_at, updated_at, event_name, date, description, content, is_draft, published_at FROM events WHERE id = $1`
-// 	row := s.db.QueryRowContext(ctx, getEventQuery, eventID)
-
-// 	var event models.Event
-// 	err := row.Scan(&event.ID, &event.CreatedAt, &event.UpdatedAt, &event.EventName, &event.Date, &event.Description, &event.Content, &event.IsDraft, &event.PublishedAt)
-// 	if err != nil {
-// 		loggers.Error.Printf("Error retrieving event: %v", err)
-// 		return nil, err
-// 	}
-
-// 	// fetch associated images
-// 	const getImagesQuery = `SELECT id, image_url, is_display FROM images WHERE event_id = $1`
-// 	images, err := s.getImagesByEventID(ctx, eventID)
-// 	if err != nil {
-// 		loggers.Error.Printf("Error retrieving images for event: %v", err)
-// 		return nil, err
-// 	}
-// 	event.Images = images
+// GetEventByID returns the event fields only, images and authors are not loaded
+func (s *service) GetEventByID(ctx context.Context, eventID string) (*models.Event, error) {
+	const query = `
+	SELECT id, created_at, updated_at, event_title, meta_title, slug,
+	date, description, content, is_draft, published_at
+	FROM events
+	WHERE id = $1`
+
+	var event models.Event
+	err := s.db.QueryRowContext(ctx, query, eventID).Scan(
+		&event.ID, &event.CreatedAt, &event.UpdatedAt, &event.EventTitle, &event.Metatitle, &event.Slug,
+		&event.Date, &event.Description, &event.Content, &event.IsDraft, &event.PublishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error retrieving event: %v", err)
+		return nil, err
+	}
 
-// 	// fetch associated authors
-// 	authors, err := s.GetAuthorsByEventID(eventID)
-// 	if err != nil {
-// 		loggers.Error.Printf("Error retrieving authors for event: %v", err)
-// 		return nil, err
-// 	}
-// 	event.Authors = authors
+	return &event, nil
+}
 
-// 	return &event, nil
-// }
+// GetAllEvents returns a page of events, drafts included, the latest date
+// first. Images and authors are not loaded.
+func (s *service) GetAllEvents(ctx context.Context, page, pageSize int) ([]models.Event, error) {
+	offset := getOffset(page, pageSize)
+	const query = `
+	SELECT id, created_at, updated_at, event_title, meta_title, slug,
+	date, description, content, is_draft, published_at
+	FROM events
+	ORDER BY date DESC, id DESC
+	LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, pageSize, offset)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error retrieving events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(
+			&event.ID, &event.CreatedAt, &event.UpdatedAt, &event.EventTitle, &event.Metatitle, &event.Slug,
+			&event.Date, &event.Description, &event.Content, &event.IsDraft, &event.PublishedAt); err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error scanning event: %v", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
 
 // func (s *service) GetAuthorsByEventID(eventID string) ([]models.Admin, error) {
 // 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createAdminIdentityTable(db *sql.DB) error {
+	createAdminIdentityTableSQL := `
+    CREATE TABLE IF NOT EXISTS admin_identities (
+        provider VARCHAR(50) NOT NULL,
+        provider_user_id VARCHAR(255) NOT NULL,
+        admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+        email VARCHAR(255) NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        last_login_at TIMESTAMP WITH TIME ZONE,
+        PRIMARY KEY (provider, provider_user_id)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_admin_identities_admin_id ON admin_identities(admin_id);`
+
+	_, err := db.Exec(createAdminIdentityTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating admin identity table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ===== external ===== //
+
+// ========== CREATE ========== //
+
+// LinkAdminIdentity links a provider account to an admin, linking the same
+// account again only refreshes its email. An account can belong to a single admin.
+func (s *service) LinkAdminIdentity(ctx context.Context, identity models.AdminIdentity) error {
+	if identity.Provider == "" || identity.ProviderUserID == "" {
+		return errors.New("missing provider or provider user id")
+	}
+
+	const query = `
+	INSERT INTO admin_identities (provider, provider_user_id, admin_id, email, created_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (provider, provider_user_id) DO UPDATE SET email = EXCLUDED.email
+	WHERE admin_identities.admin_id = EXCLUDED.admin_id`
+
+	res, err := s.db.ExecContext(ctx, query, identity.Provider, identity.ProviderUserID,
+		identity.AdminID, strings.ToLower(identity.Email), time.Now())
+	if err != nil {
+		if sqlState(err) == "23503" { // foreign_key_violation
+			return errors.New("invalid admin id")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error linking admin identity: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("identity already linked to another admin")
+	}
+	return nil
+}
+
+// ========== READ ========== //
+
+// ResolveAdminLogin finds the admin signing in with a provider account. The
+// stable provider user ID is looked up first. The email is only a fallback
+// for admins without any linked account, e.g. the seeded founder, and only
+// when the provider verified it; the account is linked on success. Once an
+// admin has an identity, other accounts with their email are refused, so
+// unlinking an account really keeps it out.
+func (s *service) ResolveAdminLogin(ctx context.Context, provider, providerUserID, email string, emailVerified bool) (*models.Admin, error) {
+	const byIdentity = `
+	SELECT a.id, a.created_at, a.updated_at, a.deleted_at, a.name, a.email, a.position, a.status
+	FROM admin_identities i
+	JOIN admins a ON a.id = i.admin_id
+	WHERE i.provider = $1 AND i.provider_user_id = $2
+	AND a.deleted_at IS NULL`
+
+	var admin models.Admin
+	err := s.db.QueryRowContext(ctx, byIdentity, provider, providerUserID).Scan(
+		&admin.ID, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.DeletedAt, &admin.Name, &admin.Email, &admin.Position, &admin.Status)
+	switch {
+	case err == sql.ErrNoRows:
+		if !emailVerified {
+			return nil, errors.New("admin not found")
+		}
+		const byEmail = `
+		SELECT a.id, a.created_at, a.updated_at, a.deleted_at, a.name, a.email, a.position, a.status
+		FROM admins a
+		WHERE a.email = $1 AND a.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM admin_identities i WHERE i.admin_id = a.id)`
+		found, err := s.getAdmin(ctx, byEmail, strings.ToLower(email))
+		if err != nil {
+			return nil, err
+		}
+		admin = *found
+		if err := s.LinkAdminIdentity(ctx, models.AdminIdentity{
+			AdminID: admin.ID, Provider: provider, ProviderUserID: providerUserID, Email: email,
+		}); err != nil {
+			// the account belongs to a deleted admin, don't let the email take it over
+			return nil, err
+		}
+	case err != nil:
+		loggers.Error.Ctx(ctx).Printf("Error getting admin by identity: %v", err)
+		return nil, err
+	}
+
+	const touch = `
+	UPDATE admin_identities SET last_login_at = $1, email = $2
+	WHERE provider = $3 AND provider_user_id = $4`
+	if _, err := s.db.ExecContext(ctx, touch, time.Now(), strings.ToLower(email), provider, providerUserID); err != nil {
+		// not worth failing the login over
+		loggers.Error.Ctx(ctx).Printf("Error updating identity last login: %v", err)
+	}
+
+	return &admin, nil
+}
+
+func (s *service) GetAdminIdentities(ctx context.Context, adminID string) ([]models.AdminIdentity, error) {
+	const query = `
+	SELECT admin_id, provider, provider_user_id, email, created_at, last_login_at
+	FROM admin_identities
+	WHERE admin_id = $1
+	ORDER BY created_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, adminID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting admin identities: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.AdminIdentity{}
+	for rows.Next() {
+		var identity models.AdminIdentity
+		if err := rows.Scan(&identity.AdminID, &identity.Provider, &identity.ProviderUserID,
+			&identity.Email, &identity.CreatedAt, &identity.LastLoginAt); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning admin identity: %v", err)
+			continue // skip partial results
+		}
+		identities = append(identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over admin identities: %v", err)
+		return nil, err
+	}
+	return identities, nil
+}
+
+// ========== DELETE ========== //
+
+// UnlinkAdminIdentity removes a provider account from an admin, the last
+// identity can't be removed so the admin can't lock themselves out
+func (s *service) UnlinkAdminIdentity(ctx context.Context, adminID, provider, providerUserID string) error {
+	const query = `
+	DELETE FROM admin_identities
+	WHERE admin_id = $1 AND provider = $2 AND provider_user_id = $3
+	AND (SELECT COUNT(*) FROM admin_identities WHERE admin_id = $1) > 1`
+
+	res, err := s.db.ExecContext(ctx, query, adminID, provider, providerUserID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error unlinking admin identity: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	// tell apart a missing identity from the last one
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `
+	SELECT EXISTS (SELECT 1 FROM admin_identities
+	WHERE admin_id = $1 AND provider = $2 AND provider_user_id = $3)`,
+		adminID, provider, providerUserID).Scan(&exists); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error checking admin identity: %v", err)
+		return err
+	}
+	if exists {
+		return errors.New("cannot unlink the last identity")
+	}
+	return errors.New("identity not found")
+}
@@ -0,0 +1,340 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createInventoryItemTable(db *sql.DB) error {
+	createInventoryItemTableSQL := `
+    CREATE TABLE IF NOT EXISTS inventory_items (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        category VARCHAR(50) NOT NULL,
+        condition VARCHAR(50) NOT NULL,
+        condition_notes VARCHAR(2000) NOT NULL,
+        photo_key VARCHAR(255) NOT NULL
+    );`
+
+	_, err := db.Exec(createInventoryItemTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating inventory item table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func createItemCheckoutTable(db *sql.DB) error {
+	createItemCheckoutTableSQL := `
+    CREATE TABLE IF NOT EXISTS item_checkouts (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        item_id UUID NOT NULL REFERENCES inventory_items(id) ON DELETE CASCADE,
+        performance_id UUID REFERENCES performances(id) ON DELETE SET NULL,
+        member_id UUID REFERENCES members(id) ON DELETE NO ACTION,
+        checked_out_by UUID NOT NULL REFERENCES admins(id) ON DELETE NO ACTION,
+        checked_out_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        checked_in_at TIMESTAMP WITH TIME ZONE,
+        notes VARCHAR(2000) NOT NULL
+    );
+
+    -- an item can only be out once at a time
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_item_checkouts_open
+        ON item_checkouts(item_id) WHERE checked_in_at IS NULL;`
+
+	_, err := db.Exec(createItemCheckoutTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating item checkout table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanCheckouts(rows *sql.Rows) ([]models.ItemCheckout, error) {
+	checkouts := []models.ItemCheckout{}
+	for rows.Next() {
+		var c models.ItemCheckout
+		if err := rows.Scan(
+			&c.ID, &c.ItemID, &c.PerformanceID, &c.MemberID, &c.CheckedOutBy,
+			&c.CheckedOutAt, &c.DueAt, &c.CheckedInAt, &c.Notes, &c.ItemName); err != nil {
+			loggers.Error.Printf("scanning checkout: %v", err)
+			continue // skip partial results
+		}
+		checkouts = append(checkouts, c)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Printf("Error iterating over checkouts: %v", err)
+		return nil, err
+	}
+	return checkouts, nil
+}
+
+const selectInventoryItem = `
+	SELECT i.id, i.created_at, i.updated_at, i.name, i.category, i.condition,
+	i.condition_notes, i.photo_key,
+	EXISTS(SELECT 1 FROM item_checkouts c WHERE c.item_id = i.id AND c.checked_in_at IS NULL)
+	FROM inventory_items i`
+
+const selectCheckout = `
+	SELECT c.id, c.item_id, c.performance_id, c.member_id, c.checked_out_by,
+	c.checked_out_at, c.due_at, c.checked_in_at, c.notes, i.name
+	FROM item_checkouts c
+	INNER JOIN inventory_items i ON i.id = c.item_id`
+
+// ===== external ===== //
+
+// CRUD operations for inventory items
+
+// ========== CREATE ========== //
+
+func (s *service) CreateInventoryItem(ctx context.Context, item models.InventoryItem) (string, error) {
+	if err := SanitizeInventoryItemInput(&item); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid inventory item input: %v", err)
+		return "", err
+	}
+
+	var id string
+	const query = `
+	INSERT INTO inventory_items (
+		created_at, updated_at, name, category, condition, condition_notes, photo_key
+	) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	currTime := time.Now()
+	err := s.db.QueryRowContext(
+		ctx, query, currTime, currTime, item.Name, item.Category,
+		item.Condition, item.ConditionNotes, item.PhotoKey,
+	).Scan(&id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error creating inventory item: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// ========== READ ========== //
+
+func (s *service) GetInventoryItem(ctx context.Context, id string) (*models.InventoryItem, error) {
+	query := selectInventoryItem + ` WHERE i.id = $1`
+
+	var item models.InventoryItem
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Name, &item.Category,
+		&item.Condition, &item.ConditionNotes, &item.PhotoKey, &item.CheckedOut)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("inventory item not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting inventory item: %v", err)
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetInventoryItems returns all items, optionally filtered by category
+func (s *service) GetInventoryItems(ctx context.Context, category string) ([]models.InventoryItem, error) {
+	query := selectInventoryItem + `
+	WHERE ($1 = '' OR i.category = $1)
+	ORDER BY i.category ASC, i.name ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, category)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting inventory items: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.InventoryItem{}
+	for rows.Next() {
+		var item models.InventoryItem
+		if err := rows.Scan(
+			&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Name, &item.Category,
+			&item.Condition, &item.ConditionNotes, &item.PhotoKey, &item.CheckedOut); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning inventory item: %v", err)
+			continue // skip partial results
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over inventory items: %v", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// ========== UPDATE ========== //
+
+func (s *service) UpdateInventoryItem(ctx context.Context, item models.InventoryItem) error {
+	if err := SanitizeInventoryItemInput(&item); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid inventory item input: %v", err)
+		return err
+	}
+
+	const query = `
+	UPDATE inventory_items SET
+	updated_at = $1, name = $2, category = $3, condition = $4,
+	condition_notes = $5, photo_key = $6
+	WHERE id = $7`
+
+	res, err := s.db.ExecContext(
+		ctx, query, time.Now(), item.Name, item.Category, item.Condition,
+		item.ConditionNotes, item.PhotoKey, item.ID,
+	)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("updating inventory item: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("inventory item not found")
+	}
+	return nil
+}
+
+// ========== DELETE ========== //
+
+// DeleteInventoryItem permanently removes an item and its checkout history,
+// prefer setting the condition to retired
+func (s *service) DeleteInventoryItem(ctx context.Context, id string) error {
+	const query = `DELETE FROM inventory_items WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("deleting inventory item: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("inventory item not found")
+	}
+	return nil
+}
+
+// ========== CHECK OUT / CHECK IN ========== //
+
+func (s *service) CheckOutItem(ctx context.Context, checkout models.ItemCheckout) (string, error) {
+	if err := SanitizeCheckoutInput(&checkout); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid checkout input: %v", err)
+		return "", err
+	}
+
+	var id string
+	const query = `
+	INSERT INTO item_checkouts (
+		item_id, performance_id, member_id, checked_out_by,
+		checked_out_at, due_at, notes
+	)
+	SELECT i.id, $2::uuid, $3::uuid, $4::uuid, $5::timestamptz, $6::timestamptz, $7::text
+	FROM inventory_items i
+	WHERE i.id = $1 AND i.condition != 'retired'
+	RETURNING id`
+
+	err := s.db.QueryRowContext(
+		ctx, query, checkout.ItemID, checkout.PerformanceID, checkout.MemberID,
+		checkout.CheckedOutBy, time.Now(), checkout.DueAt, checkout.Notes,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.New("item not found or retired")
+		}
+		switch sqlState(err) {
+		case "23505": // unique_violation on idx_item_checkouts_open
+			return "", errors.New("item is already checked out")
+		case "23503": // foreign_key_violation
+			return "", errors.New("invalid performance, member or admin id")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error checking out item: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// CheckInItem closes the open checkout of an item and optionally records the
+// condition it came back in
+func (s *service) CheckInItem(ctx context.Context, itemID string, req models.CheckInRequest) error {
+	if req.Condition != "" && !isValidItemCondition(req.Condition) {
+		return errors.New("invalid item condition")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return err
+	}
+
+	const checkInQuery = `
+	UPDATE item_checkouts SET
+	checked_in_at = $1,
+	notes = CASE WHEN $2 = '' THEN notes ELSE TRIM(notes || E'\n' || $2) END
+	WHERE item_id = $3 AND checked_in_at IS NULL`
+
+	currTime := time.Now()
+	res, err := tx.ExecContext(ctx, checkInQuery, currTime, req.Notes, itemID)
+	if err != nil {
+		tx.Rollback()
+		loggers.Error.Ctx(ctx).Printf("Error checking in item: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errors.New("item is not checked out")
+	}
+
+	if req.Condition != "" {
+		const conditionQuery = `
+		UPDATE inventory_items SET
+		updated_at = $1, condition = $2,
+		condition_notes = CASE WHEN $3 = '' THEN condition_notes ELSE $3 END
+		WHERE id = $4`
+		if _, err := tx.ExecContext(ctx, conditionQuery, currTime, req.Condition, req.ConditionNotes, itemID); err != nil {
+			tx.Rollback()
+			loggers.Error.Ctx(ctx).Printf("Error updating item condition: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("committing check in: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetItemCheckouts returns the checkout history of an item, most recent first
+func (s *service) GetItemCheckouts(ctx context.Context, itemID string) ([]models.ItemCheckout, error) {
+	query := selectCheckout + `
+	WHERE c.item_id = $1
+	ORDER BY c.checked_out_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, itemID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting item checkouts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCheckouts(rows)
+}
+
+// GetOverdueCheckouts returns open checkouts that were due before asOf
+func (s *service) GetOverdueCheckouts(ctx context.Context, asOf time.Time) ([]models.ItemCheckout, error) {
+	query := selectCheckout + `
+	WHERE c.checked_in_at IS NULL AND c.due_at < $1
+	ORDER BY c.due_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, asOf)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting overdue checkouts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCheckouts(rows)
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createAdminInvitationTable(db *sql.DB) error {
+	createAdminInvitationTableSQL := `
+    CREATE TABLE IF NOT EXISTS admin_invitations (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        email VARCHAR(255) NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        position VARCHAR(255) NOT NULL,
+        invited_by UUID NOT NULL REFERENCES admins(id),
+        token_hash VARCHAR(64) NOT NULL UNIQUE,
+        expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        accepted_at TIMESTAMP WITH TIME ZONE,
+        accepted_admin_id UUID REFERENCES admins(id) ON DELETE SET NULL,
+        revoked_at TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_admin_invitations_email ON admin_invitations(email);`
+
+	_, err := db.Exec(createAdminInvitationTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating admin invitation table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// invitationStatusSQL derives the status of an invitation, $1 is the current time
+const invitationStatusSQL = `
+	CASE
+		WHEN revoked_at IS NOT NULL THEN 'revoked'
+		WHEN accepted_at IS NOT NULL THEN 'accepted'
+		WHEN expires_at <= $1 THEN 'expired'
+		ELSE 'pending'
+	END`
+
+func scanAdminInvitations(rows *sql.Rows) ([]models.AdminInvitation, error) {
+	invitations := []models.AdminInvitation{}
+	for rows.Next() {
+		var inv models.AdminInvitation
+		if err := rows.Scan(
+			&inv.ID, &inv.CreatedAt, &inv.UpdatedAt, &inv.Email, &inv.Name, &inv.Position,
+			&inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.AcceptedAdminID,
+			&inv.RevokedAt, &inv.Status); err != nil {
+			loggers.Error.Printf("scanning admin invitation: %v", err)
+			continue // skip partial results
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Printf("Error iterating over admin invitations: %v", err)
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// ===== external ===== //
+
+// ========== CREATE ========== //
+
+// CreateAdminInvitation stores an invitation along with the hash of the token
+// mailed to the invitee, the token itself is never stored
+func (s *service) CreateAdminInvitation(ctx context.Context, invitation models.AdminInvitation, tokenHash string) (string, error) {
+	if err := SanitizeAdminInvitationInput(&invitation); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid admin invitation input: %v", err)
+		return "", err
+	}
+
+	// inviting an existing admin would only fail once the invitee accepts
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM admins WHERE email = $1)`, invitation.Email,
+	).Scan(&exists); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error checking admin email: %v", err)
+		return "", err
+	}
+	if exists {
+		return "", errors.New("email already exists")
+	}
+
+	const query = `
+	INSERT INTO admin_invitations (
+		created_at, updated_at, email, name, position, invited_by, token_hash, expires_at
+	) VALUES ($1, $1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	var id string
+	err := s.db.QueryRowContext(ctx, query, time.Now(), invitation.Email, invitation.Name,
+		invitation.Position, invitation.InvitedBy, tokenHash, invitation.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		if sqlState(err) == "23503" { // foreign_key_violation
+			return "", errors.New("invalid admin id")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error creating admin invitation: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// ========== READ ========== //
+
+// GetAdminInvitations lists invitations newest first, status is one of
+// pending, accepted, expired, revoked or empty for all of them
+func (s *service) GetAdminInvitations(ctx context.Context, status string) ([]models.AdminInvitation, error) {
+	if status != "" && !contains(InvitationStatuses, status) {
+		return nil, errors.New("invalid invitation status")
+	}
+
+	const query = `
+	SELECT * FROM (
+		SELECT id, created_at, updated_at, email, name, position, invited_by,
+		expires_at, accepted_at, accepted_admin_id, revoked_at,` + invitationStatusSQL + ` AS status
+		FROM admin_invitations
+	) invitations
+	WHERE $2 = '' OR status = $2
+	ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, time.Now(), status)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting admin invitations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAdminInvitations(rows)
+}
+
+// ========== UPDATE ========== //
+
+// AcceptAdminInvitation redeems a pending invitation for the account that
+// completed OAuth and creates its admin. Unless bind is set the account email
+// must match the invited email, with bind the admin is created for the
+// account email instead. Invitations can only be redeemed once.
+func (s *service) AcceptAdminInvitation(ctx context.Context, tokenHash, accountEmail string, bind bool) (*models.Admin, error) {
+	accountEmail = strings.ToLower(strings.TrimSpace(accountEmail))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var inv models.AdminInvitation
+	err = tx.QueryRowContext(ctx, `
+	SELECT id, email, name, position, expires_at, accepted_at, revoked_at
+	FROM admin_invitations
+	WHERE token_hash = $1
+	FOR UPDATE`, tokenHash,
+	).Scan(&inv.ID, &inv.Email, &inv.Name, &inv.Position, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invitation not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting admin invitation: %v", err)
+		return nil, err
+	}
+
+	switch {
+	case inv.RevokedAt != nil:
+		return nil, errors.New("invitation has been revoked")
+	case inv.AcceptedAt != nil:
+		return nil, errors.New("invitation has already been accepted")
+	case !inv.ExpiresAt.After(time.Now()):
+		return nil, errors.New("invitation has expired")
+	case !bind && accountEmail != inv.Email:
+		return nil, errors.New("account email does not match invitation")
+	}
+
+	admin := models.Admin{
+		Name:     inv.Name,
+		Email:    accountEmail,
+		Position: inv.Position,
+		Status:   "active",
+	}
+	if err := SanitizeAdminInput(&admin); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = tx.QueryRowContext(ctx, `
+	INSERT INTO admins (created_at, updated_at, name, email, position, status)
+	VALUES ($1, $1, $2, $3, $4, $5)
+	RETURNING id, created_at, updated_at`,
+		now, admin.Name, admin.Email, admin.Position, admin.Status,
+	).Scan(&admin.ID, &admin.CreatedAt, &admin.UpdatedAt)
+	if err != nil {
+		if sqlState(err) == "23505" { // unique_violation
+			return nil, errors.New("email already exists")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error creating admin from invitation: %v", err)
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE admin_invitations SET
+	updated_at = $1, accepted_at = $1, accepted_admin_id = $2
+	WHERE id = $3`, now, admin.ID, inv.ID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error accepting admin invitation: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing transaction: %v", err)
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// RevokeAdminInvitation invalidates a pending invitation
+func (s *service) RevokeAdminInvitation(ctx context.Context, id string) error {
+	const query = `
+	UPDATE admin_invitations SET
+	updated_at = $1, revoked_at = $1
+	WHERE id = $2 AND revoked_at IS NULL AND accepted_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("revoking admin invitation: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("invitation not found or no longer pending")
+	}
+	return nil
+}
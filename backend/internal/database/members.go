@@ -0,0 +1,191 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createMemberTable(db *sql.DB) error {
+	createMemberTableSQL := `
+    CREATE TABLE IF NOT EXISTS members (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        chinese_name VARCHAR(255) NOT NULL,
+        role VARCHAR(50) NOT NULL,
+        join_date DATE NOT NULL,
+        is_active BOOLEAN NOT NULL,
+        photo_url VARCHAR(255) NOT NULL,
+        bio VARCHAR(2000) NOT NULL,
+        admin_id UUID UNIQUE REFERENCES admins(id) ON DELETE SET NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_members_is_active ON members(is_active);`
+
+	_, err := db.Exec(createMemberTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating member table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanMembers(rows *sql.Rows) ([]models.Member, error) {
+	members := []models.Member{}
+	for rows.Next() {
+		var m models.Member
+		if err := rows.Scan(
+			&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.Name, &m.ChineseName, &m.Role,
+			&m.JoinDate, &m.IsActive, &m.PhotoURL, &m.Bio, &m.AdminID); err != nil {
+			loggers.Error.Printf("scanning member: %v", err)
+			continue // skip partial results
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Printf("Error iterating over members: %v", err)
+		return nil, err
+	}
+	return members, nil
+}
+
+func memberWriteError(err error) error {
+	switch sqlState(err) {
+	case "23505": // unique_violation
+		return errors.New("admin already linked to a member")
+	case "23503": // foreign_key_violation
+		return errors.New("invalid admin id")
+	}
+	return err
+}
+
+// ===== external ===== //
+
+// CRUD operations for members
+
+// ========== CREATE ========== //
+
+func (s *service) CreateMember(ctx context.Context, member models.Member) (string, error) {
+	if err := SanitizeMemberInput(&member); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid member input: %v", err)
+		return "", err
+	}
+
+	var id string
+	const query = `
+	INSERT INTO members (
+		created_at, updated_at, name, chinese_name, role,
+		join_date, is_active, photo_url, bio, admin_id
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	currTime := time.Now()
+	err := s.db.QueryRowContext(
+		ctx, query, currTime, currTime, member.Name, member.ChineseName, member.Role,
+		member.JoinDate, member.IsActive, member.PhotoURL, member.Bio, member.AdminID,
+	).Scan(&id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error creating member: %v", err)
+		return "", memberWriteError(err)
+	}
+
+	return id, nil
+}
+
+// ========== READ ========== //
+
+func (s *service) GetMember(ctx context.Context, id string) (*models.Member, error) {
+	const query = `
+	SELECT id, created_at, updated_at, name, chinese_name, role,
+	join_date, is_active, photo_url, bio, admin_id
+	FROM members
+	WHERE id = $1`
+
+	var m models.Member
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.Name, &m.ChineseName, &m.Role,
+		&m.JoinDate, &m.IsActive, &m.PhotoURL, &m.Bio, &m.AdminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("member not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting member: %v", err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMembers returns the roster ordered by join date, optionally only active members
+func (s *service) GetMembers(ctx context.Context, activeOnly bool) ([]models.Member, error) {
+	const query = `
+	SELECT id, created_at, updated_at, name, chinese_name, role,
+	join_date, is_active, photo_url, bio, admin_id
+	FROM members
+	WHERE (NOT $1 OR is_active)
+	ORDER BY join_date ASC, name ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, activeOnly)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting members: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMembers(rows)
+}
+
+// ========== UPDATE ========== //
+
+func (s *service) UpdateMember(ctx context.Context, member models.Member) error {
+	if err := SanitizeMemberInput(&member); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid member input: %v", err)
+		return err
+	}
+
+	const query = `
+	UPDATE members SET
+	updated_at = $1, name = $2, chinese_name = $3, role = $4, join_date = $5,
+	is_active = $6, photo_url = $7, bio = $8, admin_id = $9
+	WHERE id = $10`
+
+	res, err := s.db.ExecContext(
+		ctx, query, time.Now(), member.Name, member.ChineseName, member.Role, member.JoinDate,
+		member.IsActive, member.PhotoURL, member.Bio, member.AdminID, member.ID,
+	)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("updating member: %v", err)
+		return memberWriteError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+// ========== DELETE ========== //
+
+// DeleteMember permanently removes a member, prefer marking members inactive
+// to keep them in historical records
+func (s *service) DeleteMember(ctx context.Context, id string) error {
+	const query = `DELETE FROM members WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if sqlState(err) == "23503" { // foreign_key_violation
+			return errors.New("member is still referenced")
+		}
+		loggers.Error.Ctx(ctx).Printf("deleting member: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
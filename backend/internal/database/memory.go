@@ -0,0 +1,518 @@
+package database
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memory keeps admins, events, images and authors in maps guarded by one
+// lock. It mirrors the postgres schema: unique emails and slugs, the foreign
+// keys of event_authors and event_images and the founder createAdminTable
+// inserts. Timestamps are truncated to microseconds like postgres stores them.
+type memory struct {
+	mu      sync.Mutex
+	admins  map[string]*memoryAdmin
+	events  map[string]*models.Event
+	images  map[string]*memoryImage
+	authors map[models.EventAuthor]bool
+}
+
+type memoryAdmin struct {
+	admin             models.Admin
+	sessionsRevokedAt *time.Time
+}
+
+type memoryImage struct {
+	image   models.EventImage
+	eventID string
+}
+
+// NewMemory returns repositories that keep everything in memory, for tests
+// and tools that shouldn't need postgres
+func NewMemory() Repositories {
+	m := &memory{
+		admins:  map[string]*memoryAdmin{},
+		events:  map[string]*models.Event{},
+		images:  map[string]*memoryImage{},
+		authors: map[models.EventAuthor]bool{},
+	}
+	now := memoryNow()
+	founder := models.Admin{
+		ID: uuid.NewString(), CreatedAt: now, UpdatedAt: now,
+		Name: "Jiating", Email: "jiating.lion.dragon@gmail.com", Position: "Founder", Status: "permanent",
+	}
+	m.admins[founder.ID] = &memoryAdmin{admin: founder}
+	return Repositories{Admins: m, Events: m, Images: m, Authors: m}
+}
+
+func memoryNow() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// ===== internal ===== //
+
+// findAdmin looks an admin up by id or email, deleted admins only when asked
+func (m *memory) findAdmin(field, value string, deleted bool) *memoryAdmin {
+	for _, a := range m.admins {
+		if (a.admin.DeletedAt != nil) != deleted {
+			continue
+		}
+		if (field == "id" && a.admin.ID == value) || (field == "email" && a.admin.Email == value) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (m *memory) emailTaken(email, exceptID string) bool {
+	for _, a := range m.admins {
+		if a.admin.Email == email && a.admin.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// listAdmins sorts the admins keep selects newest first by the time at picks
+// and returns the requested page
+func (m *memory) listAdmins(page, pageSize int, keep func(models.Admin) bool, at func(models.Admin) time.Time) []models.Admin {
+	var admins []models.Admin
+	for _, a := range m.admins {
+		if keep(a.admin) {
+			admins = append(admins, a.admin)
+		}
+	}
+	sort.Slice(admins, func(i, j int) bool {
+		if !at(admins[i]).Equal(at(admins[j])) {
+			return at(admins[i]).After(at(admins[j]))
+		}
+		return admins[i].ID > admins[j].ID
+	})
+
+	offset := getOffset(page, pageSize)
+	if offset >= len(admins) {
+		return nil
+	}
+	end := offset + pageSize
+	if end > len(admins) {
+		end = len(admins)
+	}
+	return admins[offset:end]
+}
+
+// adminFilters mirror the WHERE clauses of the postgres listings
+var adminFilters = map[AdminFilter]func(models.Admin) bool{
+	AdminsActive:        func(a models.Admin) bool { return a.DeletedAt == nil },
+	AdminsWithDeleted:   func(a models.Admin) bool { return true },
+	AdminsDeleted:       func(a models.Admin) bool { return a.DeletedAt != nil },
+	AdminsExceptFounder: func(a models.Admin) bool { return a.DeletedAt == nil && a.Position != "Founder" },
+}
+
+func createdAt(a models.Admin) time.Time { return a.CreatedAt }
+
+func deletedAt(a models.Admin) time.Time { return *a.DeletedAt }
+
+func (m *memory) addImage(image models.EventImage, eventID string) (string, error) {
+	if _, ok := m.events[eventID]; !ok {
+		return "", errors.New("event not found")
+	}
+	if image.ID == "" {
+		image.ID = uuid.NewString()
+	}
+	if _, ok := m.images[image.ID]; ok {
+		return "", errors.New("image already exists")
+	}
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = memoryNow()
+	}
+	image.CreatedAt = image.CreatedAt.Truncate(time.Microsecond)
+
+	if image.IsDisplay {
+		for _, img := range m.images {
+			if img.eventID == eventID {
+				img.image.IsDisplay = false
+			}
+		}
+	}
+	m.images[image.ID] = &memoryImage{image: image, eventID: eventID}
+	return image.ID, nil
+}
+
+func (m *memory) addAuthor(eventID, adminID string) error {
+	_, adminOK := m.admins[adminID]
+	_, eventOK := m.events[eventID]
+	if !adminOK || !eventOK {
+		return errors.New("invalid adminID or eventID")
+	}
+	key := models.EventAuthor{AdminID: adminID, EventID: eventID}
+	if m.authors[key] {
+		return errors.New("admin is already an author")
+	}
+	m.authors[key] = true
+	return nil
+}
+
+// ===== external ===== //
+
+// ========== ADMINS ========== //
+
+func (m *memory) CreateAdmin(ctx context.Context, admin models.Admin) (string, error) {
+	if err := SanitizeAdminInput(&admin); err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.emailTaken(admin.Email, "") {
+		return "", errors.New("email already exists")
+	}
+	now := memoryNow()
+	stored := models.Admin{
+		ID: uuid.NewString(), CreatedAt: now, UpdatedAt: now,
+		Name: admin.Name, Email: admin.Email, Position: admin.Position, Status: admin.Status,
+	}
+	m.admins[stored.ID] = &memoryAdmin{admin: stored}
+	return stored.ID, nil
+}
+
+func (m *memory) GetAllAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listAdmins(page, pageSize, adminFilters[AdminsActive], createdAt), nil
+}
+
+func (m *memory) GetAllAdminsExceptFounder(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listAdmins(page, pageSize, adminFilters[AdminsExceptFounder], createdAt), nil
+}
+
+func (m *memory) GetAllAdminsIncludingDeleted(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listAdmins(page, pageSize, adminFilters[AdminsWithDeleted], createdAt), nil
+}
+
+func (m *memory) GetDeletedAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listAdmins(page, pageSize, adminFilters[AdminsDeleted], deletedAt), nil
+}
+
+func (m *memory) GetAdmin(ctx context.Context, field, value string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.findAdmin(field, value, false)
+	if a == nil {
+		return nil, errors.New("admin not found")
+	}
+	admin := a.admin
+	return &admin, nil
+}
+
+func (m *memory) AdminSessionValid(ctx context.Context, adminID string, issuedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.findAdmin("id", adminID, false)
+	if a == nil {
+		return false, nil
+	}
+	return a.sessionsRevokedAt == nil || a.sessionsRevokedAt.Before(issuedAt), nil
+}
+
+func (m *memory) GetAdminCount(ctx context.Context) (int, error) {
+	return m.CountAdmins(ctx, AdminsActive)
+}
+
+func (m *memory) CountAdmins(ctx context.Context, filter AdminFilter) (int, error) {
+	keep, ok := adminFilters[filter]
+	if !ok {
+		return 0, errors.New("invalid admin filter")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, a := range m.admins {
+		if keep(a.admin) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memory) UpdateAdmin(ctx context.Context, admin models.Admin) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.findAdmin("id", admin.ID, false)
+	var current models.Admin
+	if a != nil {
+		current = a.admin
+	}
+	if err := sanitizeAdminUpdate(current, &admin); err != nil {
+		return err
+	}
+	if a == nil {
+		return nil // postgres updates no rows without an error
+	}
+	if m.emailTaken(admin.Email, admin.ID) {
+		return errors.New("email already exists")
+	}
+	a.admin.UpdatedAt = memoryNow()
+	a.admin.Name = admin.Name
+	a.admin.Email = admin.Email
+	a.admin.Position = admin.Position
+	a.admin.Status = admin.Status
+	return nil
+}
+
+func (m *memory) DeleteAdmin(ctx context.Context, field, value, reassignTo string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.findAdmin(field, value, false)
+	if a == nil {
+		return nil, errors.New("admin not found")
+	}
+	if a.admin.Status == "permanent" {
+		return nil, errors.New("cannot delete a permanent admin")
+	}
+
+	if reassignTo != "" {
+		target := m.findAdmin("id", reassignTo, false)
+		if target == nil {
+			target = m.findAdmin("email", strings.ToLower(reassignTo), false)
+		}
+		if target == nil || target.admin.ID == a.admin.ID {
+			return nil, errors.New("invalid reassignment admin")
+		}
+		for key := range m.authors {
+			if key.AdminID == a.admin.ID {
+				delete(m.authors, key)
+				m.authors[models.EventAuthor{AdminID: target.admin.ID, EventID: key.EventID}] = true
+			}
+		}
+	}
+
+	now := memoryNow()
+	a.admin.UpdatedAt = now
+	a.admin.DeletedAt = &now
+	a.sessionsRevokedAt = &now
+	admin := a.admin
+	return &admin, nil
+}
+
+func (m *memory) RestoreAdmin(ctx context.Context, field, value string) (*models.Admin, error) {
+	if field != "id" && field != "email" {
+		return nil, errors.New("invalid admin field")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.findAdmin(field, value, true)
+	if a == nil {
+		return nil, errors.New("deleted admin not found")
+	}
+	a.admin.UpdatedAt = memoryNow()
+	a.admin.DeletedAt = nil
+	admin := a.admin
+	return &admin, nil
+}
+
+// ========== EVENTS ========== //
+
+func (m *memory) CreateEvent(ctx context.Context, event models.Event, adminID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	for _, e := range m.events {
+		if e.ID == event.ID || e.Slug == event.Slug {
+			return "", errors.New("event already exists")
+		}
+	}
+
+	now := memoryNow()
+	stored := models.Event{
+		ID: event.ID, CreatedAt: now, UpdatedAt: now,
+		EventTitle: event.EventTitle, Metatitle: event.Metatitle, Slug: event.Slug,
+		Date: event.Date.Truncate(time.Microsecond), Description: event.Description,
+		Content: event.Content, IsDraft: event.IsDraft,
+	}
+	if !event.IsDraft {
+		stored.PublishedAt = &now
+	}
+	m.events[stored.ID] = &stored
+
+	// undo everything on failure like the postgres transaction does
+	images := make(map[string]*memoryImage, len(m.images))
+	for id, img := range m.images {
+		copied := *img
+		images[id] = &copied
+	}
+	rollback := func() {
+		delete(m.events, stored.ID)
+		delete(m.authors, models.EventAuthor{AdminID: adminID, EventID: stored.ID})
+		m.images = images
+	}
+
+	if err := m.addAuthor(stored.ID, adminID); err != nil {
+		rollback()
+		return "", err
+	}
+	for _, img := range event.Images {
+		if _, err := m.addImage(img, stored.ID); err != nil {
+			rollback()
+			return "", err
+		}
+	}
+	return stored.ID, nil
+}
+
+func (m *memory) GetEventByID(ctx context.Context, eventID string) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.events[eventID]
+	if !ok {
+		return nil, errors.New("event not found")
+	}
+	event := *e
+	return &event, nil
+}
+
+func (m *memory) GetAllEvents(ctx context.Context, page, pageSize int) ([]models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []models.Event
+	for _, e := range m.events {
+		events = append(events, *e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.After(events[j].Date)
+		}
+		return events[i].ID > events[j].ID
+	})
+
+	offset := getOffset(page, pageSize)
+	if offset >= len(events) {
+		return nil, nil
+	}
+	return events[offset:min(offset+pageSize, len(events))], nil
+}
+
+// ========== IMAGES ========== //
+
+func (m *memory) AddEventImage(ctx context.Context, eventID string, image models.EventImage) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addImage(image, eventID)
+}
+
+func (m *memory) GetEventImages(ctx context.Context, eventID string) ([]models.EventImage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	images := []models.EventImage{}
+	for _, img := range m.images {
+		if img.eventID == eventID {
+			images = append(images, img.image)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if !images[i].CreatedAt.Equal(images[j].CreatedAt) {
+			return images[i].CreatedAt.Before(images[j].CreatedAt)
+		}
+		return images[i].ID < images[j].ID
+	})
+	return images, nil
+}
+
+func (m *memory) SetDisplayImage(ctx context.Context, eventID, imageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if img, ok := m.images[imageID]; !ok || img.eventID != eventID {
+		return errors.New("image not found")
+	}
+	for id, img := range m.images {
+		if img.eventID == eventID {
+			img.image.IsDisplay = id == imageID
+		}
+	}
+	return nil
+}
+
+func (m *memory) RemoveEventImage(ctx context.Context, eventID, imageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if img, ok := m.images[imageID]; !ok || img.eventID != eventID {
+		return errors.New("image not found")
+	}
+	delete(m.images, imageID)
+	return nil
+}
+
+// ========== AUTHORS ========== //
+
+func (m *memory) AddEventAuthor(ctx context.Context, eventID, adminID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addAuthor(eventID, adminID)
+}
+
+func (m *memory) GetEventAuthors(ctx context.Context, eventID string) ([]models.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	authors := []models.Admin{}
+	for key := range m.authors {
+		if key.EventID == eventID {
+			authors = append(authors, m.admins[key.AdminID].admin)
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if !authors[i].CreatedAt.Equal(authors[j].CreatedAt) {
+			return authors[i].CreatedAt.Before(authors[j].CreatedAt)
+		}
+		return authors[i].ID < authors[j].ID
+	})
+	return authors, nil
+}
+
+func (m *memory) RemoveEventAuthor(ctx context.Context, eventID, adminID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := models.EventAuthor{AdminID: adminID, EventID: eventID}
+	if !m.authors[key] {
+		return errors.New("author not found")
+	}
+	delete(m.authors, key)
+	return nil
+}
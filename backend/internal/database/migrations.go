@@ -0,0 +1,72 @@
+package database
+
+import (
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SchemaVersion is the version of the schema initTables creates, bump it
+// whenever a table or column is added so readiness checks catch instances
+// running against a database that wasn't migrated
+const SchemaVersion = 3
+
+// ===== internal ===== //
+
+func createSchemaMigrationTable(db *sql.DB) error {
+	createSchemaMigrationTableSQL := `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );`
+
+	_, err := db.Exec(createSchemaMigrationTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating schema migration table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// recordSchemaVersion marks SchemaVersion as applied, it runs after every table was created
+func recordSchemaVersion(db *sql.DB) error {
+	_, err := db.Exec(`
+    INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)
+    ON CONFLICT (version) DO NOTHING`, SchemaVersion, time.Now())
+	if err != nil {
+		loggers.Error.Printf("Error recording schema version: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ===== external ===== //
+
+// Migrate creates missing tables and records SchemaVersion, Open runs it
+// every time the server starts
+func Migrate(db *sql.DB) error {
+	return initTables(db)
+}
+
+// Ping checks that the database is reachable
+func (s *service) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Stats returns the connection pool statistics
+func (s *service) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// GetSchemaVersion returns the latest applied schema version, 0 when none was recorded
+func (s *service) GetSchemaVersion(ctx context.Context) (int, error) {
+	var version sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting schema version: %v", err)
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
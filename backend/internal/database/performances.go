@@ -0,0 +1,341 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createPerformanceTable(db *sql.DB) error {
+	createPerformanceTableSQL := `
+    CREATE TABLE IF NOT EXISTS performances (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        title VARCHAR(255) NOT NULL,
+        description VARCHAR(2000) NOT NULL,
+        location VARCHAR(255) NOT NULL,
+        start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+        end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+        is_public BOOLEAN NOT NULL,
+        CHECK (end_time > start_time)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_performances_start_time ON performances(start_time);`
+
+	_, err := db.Exec(createPerformanceTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating performance table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func createPerformanceAssignmentTable(db *sql.DB) error {
+	createPerformanceAssignmentTableSQL := `
+    CREATE TABLE IF NOT EXISTS performance_assignments (
+        performance_id UUID NOT NULL,
+        member_id UUID NOT NULL,
+        PRIMARY KEY (performance_id, member_id),
+        FOREIGN KEY (performance_id) REFERENCES performances(id) ON DELETE CASCADE,
+        FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE NO ACTION
+    );`
+
+	_, err := db.Exec(createPerformanceAssignmentTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating performance_assignments table: %v", err)
+		return err
+	}
+
+	return migratePerformanceAssignments(db)
+}
+
+// migratePerformanceAssignments renames the admin_id column of databases
+// created by the first version, which assigned admins, to member_id. Admins
+// linked to a roster member are replaced by that member, the assignments of
+// the others can't be kept under the members foreign key and are dropped.
+func migratePerformanceAssignments(db *sql.DB) error {
+	var legacy bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'performance_assignments' AND column_name = 'admin_id'
+    )`).Scan(&legacy)
+	if err != nil || !legacy {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+    ALTER TABLE performance_assignments DROP CONSTRAINT IF EXISTS performance_assignments_admin_id_fkey;
+    ALTER TABLE performance_assignments RENAME COLUMN admin_id TO member_id;
+    UPDATE performance_assignments pa SET member_id = m.id
+    FROM members m WHERE m.admin_id = pa.member_id`); err != nil {
+		loggers.Error.Printf("Error moving performance assignments to members: %v", err)
+		return err
+	}
+	res, err := tx.Exec(`
+    DELETE FROM performance_assignments pa
+    WHERE NOT EXISTS (SELECT 1 FROM members m WHERE m.id = pa.member_id)`)
+	if err != nil {
+		loggers.Error.Printf("Error dropping unmapped performance assignments: %v", err)
+		return err
+	}
+	dropped, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+    ALTER TABLE performance_assignments ADD CONSTRAINT performance_assignments_member_id_fkey
+        FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE NO ACTION`); err != nil {
+		loggers.Error.Printf("Error adding performance assignment foreign key: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	loggers.Info.Printf("performance assignments now reference members, dropped %d of admins without a member", dropped)
+	return nil
+}
+
+// setPerformanceMembersTx replaces the members assigned to a performance
+func (s *service) setPerformanceMembersTx(ctx context.Context, tx *sql.Tx, performanceID string, memberIDs []string) error {
+	const deleteQuery = `DELETE FROM performance_assignments WHERE performance_id = $1`
+	if _, err := tx.ExecContext(ctx, deleteQuery, performanceID); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error clearing performance members: %v", err)
+		return err
+	}
+
+	const insertQuery = `
+	INSERT INTO performance_assignments (performance_id, member_id)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING`
+	for _, memberID := range memberIDs {
+		if _, err := tx.ExecContext(ctx, insertQuery, performanceID, memberID); err != nil {
+			if sqlState(err) == "23503" { // foreign_key_violation
+				return errors.New("invalid member id")
+			}
+			loggers.Error.Ctx(ctx).Printf("Error assigning member to performance: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) getPerformanceMembers(ctx context.Context, performanceID string) ([]string, error) {
+	const query = `SELECT member_id FROM performance_assignments WHERE performance_id = $1`
+	rows, err := s.db.QueryContext(ctx, query, performanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning performance member: %v", err)
+			continue
+		}
+		memberIDs = append(memberIDs, id)
+	}
+	return memberIDs, rows.Err()
+}
+
+// ===== external ===== //
+
+// CRUD operations for performances
+
+// ========== CREATE ========== //
+
+func (s *service) CreatePerformance(ctx context.Context, performance models.Performance) (string, error) {
+	if err := SanitizePerformanceInput(&performance); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid performance input: %v", err)
+		return "", err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return "", err
+	}
+
+	var id string
+	const query = `
+	INSERT INTO performances (
+		created_at, updated_at, title, description,
+		location, start_time, end_time, is_public
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	currTime := time.Now()
+	if err := tx.QueryRowContext(
+		ctx, query, currTime, currTime, performance.Title, performance.Description,
+		performance.Location, performance.StartTime, performance.EndTime, performance.IsPublic,
+	).Scan(&id); err != nil {
+		tx.Rollback()
+		loggers.Error.Ctx(ctx).Printf("Error creating performance: %v", err)
+		return "", err
+	}
+
+	if err := s.setPerformanceMembersTx(ctx, tx, id, performance.MemberIDs); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("committing create performance: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// ========== READ ========== //
+
+func (s *service) GetPerformance(ctx context.Context, id string) (*models.Performance, error) {
+	const query = `
+	SELECT id, created_at, updated_at, title, description,
+	location, start_time, end_time, is_public
+	FROM performances
+	WHERE id = $1`
+
+	var p models.Performance
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.Title, &p.Description,
+		&p.Location, &p.StartTime, &p.EndTime, &p.IsPublic)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("performance not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting performance: %v", err)
+		return nil, err
+	}
+
+	p.MemberIDs, err = s.getPerformanceMembers(ctx, p.ID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting performance members: %v", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPerformances returns the performances starting within [from, to),
+// ordered by start time. A zero from or to leaves that end of the range open.
+func (s *service) GetPerformances(ctx context.Context, from, to time.Time, publicOnly bool) ([]models.Performance, error) {
+	const query = `
+	SELECT id, created_at, updated_at, title, description,
+	location, start_time, end_time, is_public
+	FROM performances
+	WHERE ($1::timestamptz IS NULL OR start_time >= $1)
+	AND ($2::timestamptz IS NULL OR start_time < $2)
+	AND (NOT $3 OR is_public)
+	ORDER BY start_time ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, nullTime(from), nullTime(to), publicOnly)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting performances: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	performances := []models.Performance{}
+	for rows.Next() {
+		var p models.Performance
+		if err := rows.Scan(
+			&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.Title, &p.Description,
+			&p.Location, &p.StartTime, &p.EndTime, &p.IsPublic); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning performance: %v", err)
+			continue // skip partial results
+		}
+		performances = append(performances, p)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over performances: %v", err)
+		return nil, err
+	}
+
+	for i := range performances {
+		members, err := s.getPerformanceMembers(ctx, performances[i].ID)
+		if err != nil {
+			loggers.Error.Ctx(ctx).Printf("Error getting performance members: %v", err)
+			return nil, err
+		}
+		performances[i].MemberIDs = members
+	}
+	return performances, nil
+}
+
+// ========== UPDATE ========== //
+
+func (s *service) UpdatePerformance(ctx context.Context, performance models.Performance) error {
+	if err := SanitizePerformanceInput(&performance); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid performance input: %v", err)
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return err
+	}
+
+	const query = `
+	UPDATE performances SET
+	updated_at = $1, title = $2, description = $3, location = $4,
+	start_time = $5, end_time = $6, is_public = $7
+	WHERE id = $8`
+
+	res, err := tx.ExecContext(
+		ctx, query, time.Now(), performance.Title, performance.Description, performance.Location,
+		performance.StartTime, performance.EndTime, performance.IsPublic, performance.ID,
+	)
+	if err != nil {
+		tx.Rollback()
+		loggers.Error.Ctx(ctx).Printf("updating performance: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errors.New("performance not found")
+	}
+
+	if err := s.setPerformanceMembersTx(ctx, tx, performance.ID, performance.MemberIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("committing update performance: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ========== DELETE ========== //
+
+// DeletePerformance permanently removes a performance, assignments are removed by cascade
+func (s *service) DeletePerformance(ctx context.Context, id string) error {
+	const query = `DELETE FROM performances WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("deleting performance: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("performance not found")
+	}
+	return nil
+}
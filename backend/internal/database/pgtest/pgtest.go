@@ -0,0 +1,393 @@
+// Package pgtest gives tests a real postgres without docker. It starts a
+// throwaway server from the postgres binaries installed on the machine, or
+// uses the server in TEST_DATABASE_URL, and hands every test a database of its
+// own cloned from a template that already has the schema.
+//
+// Tests are skipped when no server can be started, set PGTEST_REQUIRE=1 (in
+// CI) to fail them instead. PG_BIN points at the directory holding initdb and
+// postgres when they aren't on the PATH or in a usual install location.
+// Postgres refuses to run as root, so tests running as root, e.g. in a
+// container, start it as the nobody user.
+package pgtest
+
+import (
+	"backend/internal/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// startTimeout bounds initdb plus the server accepting connections
+const startTimeout = 60 * time.Second
+
+// unprivileged runs initdb and postgres when the tests run as root
+const unprivileged = "nobody"
+
+// binDirs are globbed for initdb and postgres after PG_BIN and the PATH,
+// newest version first
+var binDirs = []string{
+	"/usr/lib/postgresql/*/bin",
+	"/usr/pgsql-*/bin",
+	"/usr/local/pgsql/bin",
+	"/opt/homebrew/opt/postgresql*/bin",
+	"/usr/local/opt/postgresql*/bin",
+	"/opt/local/lib/postgresql*/bin",
+}
+
+// Server is a postgres the tests of one package share
+type Server struct {
+	// dsn returns the connection string of a database on the server
+	dsn      func(name string) string
+	admin    *sql.DB
+	template string
+	created  atomic.Int64
+
+	// only set for servers started from local binaries
+	dir    string
+	cmd    *exec.Cmd
+	exited chan struct{}
+}
+
+var (
+	sharedOnce sync.Once
+	shared     *Server
+	sharedErr  error
+)
+
+// Main runs the tests of a package and stops the server they shared, call it
+// from TestMain
+func Main(m *testing.M) {
+	code := m.Run()
+	if shared != nil {
+		if err := shared.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "pgtest: stopping postgres: %v\n", err)
+		}
+	}
+	os.Exit(code)
+}
+
+// NewDatabase returns a connection pool to an empty database with the schema
+// applied, only the founder admin exists. The database is dropped when the
+// test ends.
+func NewDatabase(t testing.TB) *sql.DB {
+	t.Helper()
+	sharedOnce.Do(func() { shared, sharedErr = Start() })
+	if sharedErr != nil {
+		if os.Getenv("PGTEST_REQUIRE") != "" {
+			t.Fatalf("postgres is required: %v", sharedErr)
+		}
+		t.Skipf("postgres is unavailable: %v", sharedErr)
+	}
+	db, drop, err := shared.NewDatabase()
+	if err != nil {
+		t.Fatalf("creating test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := drop(); err != nil {
+			t.Logf("dropping test database: %v", err)
+		}
+	})
+	return db
+}
+
+// Start connects to TEST_DATABASE_URL when set, otherwise it starts a server
+// from local binaries listening on a unix socket only. Either way the schema
+// is applied to a template database tests are cloned from.
+func Start() (*Server, error) {
+	var s *Server
+	var err error
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		s, err = external(dsn)
+	} else {
+		s, err = local()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.template = fmt.Sprintf("pgtest_template_%d", os.Getpid())
+	if err := s.createTemplate(); err != nil {
+		s.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewDatabase clones the template into a new database, drop closes the
+// returned pool and drops the database
+func (s *Server) NewDatabase() (db *sql.DB, drop func() error, err error) {
+	name := fmt.Sprintf("pgtest_%d_%d", os.Getpid(), s.created.Add(1))
+	if _, err := s.admin.Exec("CREATE DATABASE " + name + " TEMPLATE " + s.template); err != nil {
+		return nil, nil, err
+	}
+	drop = func() error {
+		if db != nil {
+			db.Close()
+		}
+		_, err := s.admin.Exec("DROP DATABASE IF EXISTS " + name)
+		return err
+	}
+	db, err = sql.Open("pgx", s.dsn(name))
+	if err != nil {
+		drop()
+		return nil, nil, err
+	}
+	return db, drop, nil
+}
+
+// Stop drops the template and, for local servers, shuts postgres down and
+// removes its data directory
+func (s *Server) Stop() error {
+	var errs []error
+	if s.admin != nil {
+		if s.template != "" {
+			if _, err := s.admin.Exec("DROP DATABASE IF EXISTS " + s.template); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		s.admin.Close()
+	}
+	if s.cmd != nil {
+		errs = append(errs, s.stopProcess())
+	}
+	if s.dir != "" {
+		errs = append(errs, os.RemoveAll(s.dir))
+	}
+	return errors.Join(errs...)
+}
+
+// ===== internal ===== //
+
+// createTemplate applies the schema the way the server does on startup
+func (s *Server) createTemplate() error {
+	if _, err := s.admin.Exec("CREATE DATABASE " + s.template); err != nil {
+		return fmt.Errorf("creating template: %w", err)
+	}
+	db, err := database.Open(s.dsn(s.template))
+	if err != nil {
+		return fmt.Errorf("applying schema: %w", err)
+	}
+	// clones fail while anyone is connected to the template
+	return db.Close()
+}
+
+func external(dsn string) (*Server, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("TEST_DATABASE_URL must be a postgres:// url")
+	}
+	s := &Server{dsn: func(name string) string {
+		u := *u
+		u.Path = "/" + name
+		return u.String()
+	}}
+	s.admin, err = sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.admin.Ping(); err != nil {
+		s.admin.Close()
+		return nil, fmt.Errorf("connecting to TEST_DATABASE_URL: %w", err)
+	}
+	return s, nil
+}
+
+func local() (*Server, error) {
+	bin, err := findBinaries()
+	if err != nil {
+		return nil, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "pgtest")
+	if err != nil {
+		return nil, err
+	}
+	if cred != nil {
+		// initdb creates the data directory and postgres its socket in dir
+		if err := os.Chown(dir, int(cred.Uid), int(cred.Gid)); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+	s := &Server{dir: dir, dsn: func(name string) string {
+		return fmt.Sprintf("host=%s port=5432 user=postgres dbname=%s sslmode=disable", dir, name)
+	}}
+
+	data := filepath.Join(dir, "data")
+	initdb := exec.Command(filepath.Join(bin, "initdb"),
+		"-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync")
+	runAs(initdb, dir, cred)
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %w\n%s", err, out)
+	}
+
+	logFile, err := os.Create(filepath.Join(dir, "postgres.log"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	defer logFile.Close()
+	// durability is pointless for databases thrown away with the tests
+	s.cmd = exec.Command(filepath.Join(bin, "postgres"),
+		"-D", data, "-k", dir, "-c", "listen_addresses=",
+		"-c", "fsync=off", "-c", "synchronous_commit=off", "-c", "full_page_writes=off")
+	s.cmd.Stdout = logFile
+	s.cmd.Stderr = logFile
+	runAs(s.cmd, dir, cred)
+	if err := s.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("starting postgres: %w", err)
+	}
+	s.exited = make(chan struct{})
+	go func() {
+		s.cmd.Wait()
+		close(s.exited)
+	}()
+
+	s.admin, err = sql.Open("pgx", s.dsn("postgres"))
+	if err == nil {
+		err = waitReady(s.admin, s.exited)
+	}
+	if err != nil {
+		logs, _ := os.ReadFile(filepath.Join(dir, "postgres.log"))
+		s.Stop()
+		return nil, fmt.Errorf("%w\n%s", err, logs)
+	}
+	return s, nil
+}
+
+// credential is the unprivileged user to start postgres as, nil unless the
+// tests run as root
+func credential() (*syscall.Credential, error) {
+	if os.Geteuid() != 0 {
+		return nil, nil
+	}
+	u, err := user.Lookup(unprivileged)
+	if err != nil {
+		return nil, fmt.Errorf("postgres refuses to run as root and there is no %s user to run it as, set TEST_DATABASE_URL instead: %w", unprivileged, err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("uid of %s: %w", unprivileged, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("gid of %s: %w", unprivileged, err)
+	}
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
+
+// runAs starts cmd in dir as cred, the working directory of the tests may not
+// be readable by that user
+func runAs(cmd *exec.Cmd, dir string, cred *syscall.Credential) {
+	cmd.Dir = dir
+	if cred != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Credential: cred}
+	}
+}
+
+func waitReady(db *sql.DB, exited <-chan struct{}) error {
+	deadline := time.Now().Add(startTimeout)
+	for {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-exited:
+			return errors.New("postgres exited during startup")
+		default:
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("postgres not ready after %s: %w", startTimeout, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// stopProcess asks for a fast shutdown, which aborts open transactions
+func (s *Server) stopProcess() error {
+	select {
+	case <-s.exited:
+		return nil
+	default:
+	}
+	if err := s.cmd.Process.Signal(os.Interrupt); err != nil {
+		return err
+	}
+	select {
+	case <-s.exited:
+		return nil
+	case <-time.After(10 * time.Second):
+		err := s.cmd.Process.Kill()
+		<-s.exited
+		return err
+	}
+}
+
+// findBinaries returns the directory holding initdb and postgres
+func findBinaries() (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		if !hasBinaries(dir) {
+			return "", fmt.Errorf("PG_BIN=%s has no initdb and postgres", dir)
+		}
+		return dir, nil
+	}
+	if path, err := exec.LookPath("initdb"); err == nil && hasBinaries(filepath.Dir(path)) {
+		return filepath.Dir(path), nil
+	}
+	for _, pattern := range binDirs {
+		matches, _ := filepath.Glob(pattern)
+		sort.Slice(matches, func(i, j int) bool { return majorVersion(matches[i]) > majorVersion(matches[j]) })
+		for _, dir := range matches {
+			if hasBinaries(dir) {
+				return dir, nil
+			}
+		}
+	}
+	return "", errors.New("no postgres binaries found, install postgres or set PG_BIN or TEST_DATABASE_URL")
+}
+
+func hasBinaries(dir string) bool {
+	for _, name := range []string{"initdb", "postgres"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// majorVersion is the first number in an install path, 16 for
+// /usr/lib/postgresql/16/bin and 9 for /usr/lib/postgresql/9.6/bin
+func majorVersion(path string) int {
+	start := strings.IndexAny(path, "0123456789")
+	if start < 0 {
+		return 0
+	}
+	end := start
+	for end < len(path) && path[end] >= '0' && path[end] <= '9' {
+		end++
+	}
+	v, _ := strconv.Atoi(path[start:end])
+	return v
+}
@@ -0,0 +1,299 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createPracticeSessionTable(db *sql.DB) error {
+	createPracticeSessionTableSQL := `
+    CREATE TABLE IF NOT EXISTS practice_sessions (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        date TIMESTAMP WITH TIME ZONE NOT NULL,
+        location VARCHAR(255) NOT NULL,
+        notes VARCHAR(2000) NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_practice_sessions_date ON practice_sessions(date);`
+
+	_, err := db.Exec(createPracticeSessionTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating practice session table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func createAttendanceTable(db *sql.DB) error {
+	createAttendanceTableSQL := `
+    CREATE TABLE IF NOT EXISTS attendance (
+        session_id UUID NOT NULL,
+        member_id UUID NOT NULL,
+        status VARCHAR(50) NOT NULL,
+        recorded_by UUID NOT NULL,
+        recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        PRIMARY KEY (session_id, member_id),
+        FOREIGN KEY (session_id) REFERENCES practice_sessions(id) ON DELETE CASCADE,
+        FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE NO ACTION,
+        FOREIGN KEY (recorded_by) REFERENCES admins(id) ON DELETE NO ACTION
+    );`
+
+	_, err := db.Exec(createAttendanceTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating attendance table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) getSessionAttendance(ctx context.Context, sessionID string) ([]models.AttendanceRecord, error) {
+	const query = `
+	SELECT session_id, member_id, status, recorded_by, recorded_at
+	FROM attendance
+	WHERE session_id = $1`
+
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.AttendanceRecord{}
+	for rows.Next() {
+		var a models.AttendanceRecord
+		if err := rows.Scan(&a.SessionID, &a.MemberID, &a.Status, &a.RecordedBy, &a.RecordedAt); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning attendance: %v", err)
+			continue
+		}
+		records = append(records, a)
+	}
+	return records, rows.Err()
+}
+
+// ===== external ===== //
+
+// CRUD operations for practice sessions
+
+// ========== CREATE ========== //
+
+func (s *service) CreatePracticeSession(ctx context.Context, session models.PracticeSession) (string, error) {
+	if err := SanitizePracticeSessionInput(&session); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid practice session input: %v", err)
+		return "", err
+	}
+
+	var id string
+	const query = `
+	INSERT INTO practice_sessions (
+		created_at, updated_at, date, location, notes
+	) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	currTime := time.Now()
+	err := s.db.QueryRowContext(
+		ctx, query, currTime, currTime, session.Date, session.Location, session.Notes,
+	).Scan(&id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error creating practice session: %v", err)
+		return "", err
+	}
+	return id, nil
+}
+
+// ========== READ ========== //
+
+// GetPracticeSession returns a session along with its attendance marks
+func (s *service) GetPracticeSession(ctx context.Context, id string) (*models.PracticeSession, error) {
+	const query = `
+	SELECT id, created_at, updated_at, date, location, notes
+	FROM practice_sessions
+	WHERE id = $1`
+
+	var session models.PracticeSession
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID, &session.CreatedAt, &session.UpdatedAt,
+		&session.Date, &session.Location, &session.Notes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("practice session not found")
+		}
+		loggers.Error.Ctx(ctx).Printf("Error getting practice session: %v", err)
+		return nil, err
+	}
+
+	session.Attendance, err = s.getSessionAttendance(ctx, session.ID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting session attendance: %v", err)
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetPracticeSessions returns the sessions within [from, to) without attendance,
+// most recent first. A zero from or to leaves that end of the range open.
+func (s *service) GetPracticeSessions(ctx context.Context, from, to time.Time) ([]models.PracticeSession, error) {
+	const query = `
+	SELECT id, created_at, updated_at, date, location, notes
+	FROM practice_sessions
+	WHERE ($1::timestamptz IS NULL OR date >= $1)
+	AND ($2::timestamptz IS NULL OR date < $2)
+	ORDER BY date DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, nullTime(from), nullTime(to))
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting practice sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.PracticeSession{}
+	for rows.Next() {
+		var session models.PracticeSession
+		if err := rows.Scan(
+			&session.ID, &session.CreatedAt, &session.UpdatedAt,
+			&session.Date, &session.Location, &session.Notes); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning practice session: %v", err)
+			continue // skip partial results
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over practice sessions: %v", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ========== UPDATE ========== //
+
+func (s *service) UpdatePracticeSession(ctx context.Context, session models.PracticeSession) error {
+	if err := SanitizePracticeSessionInput(&session); err != nil {
+		loggers.Debug.Ctx(ctx).Printf("invalid practice session input: %v", err)
+		return err
+	}
+
+	const query = `
+	UPDATE practice_sessions SET
+	updated_at = $1, date = $2, location = $3, notes = $4
+	WHERE id = $5`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), session.Date, session.Location, session.Notes, session.ID)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("updating practice session: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("practice session not found")
+	}
+	return nil
+}
+
+// ========== DELETE ========== //
+
+// DeletePracticeSession permanently removes a session, attendance is removed by cascade
+func (s *service) DeletePracticeSession(ctx context.Context, id string) error {
+	const query = `DELETE FROM practice_sessions WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("deleting practice session: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("practice session not found")
+	}
+	return nil
+}
+
+// ========== ATTENDANCE ========== //
+
+// RecordAttendance upserts attendance marks for a session in a single
+// transaction, marks for members not in records are left untouched.
+func (s *service) RecordAttendance(ctx context.Context, sessionID, adminID string, records []models.AttendanceRecord) error {
+	for _, record := range records {
+		if !isValidAttendanceStatus(record.Status) {
+			return errors.New("invalid attendance status")
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting transaction: %v", err)
+		return err
+	}
+
+	const query = `
+	INSERT INTO attendance (session_id, member_id, status, recorded_by, recorded_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (session_id, member_id) DO UPDATE SET
+	status = EXCLUDED.status, recorded_by = EXCLUDED.recorded_by, recorded_at = EXCLUDED.recorded_at`
+
+	currTime := time.Now()
+	for _, record := range records {
+		if _, err := tx.ExecContext(ctx, query, sessionID, record.MemberID, record.Status, adminID, currTime); err != nil {
+			tx.Rollback()
+			if sqlState(err) == "23503" { // foreign_key_violation
+				return errors.New("invalid session, member or admin id")
+			}
+			loggers.Error.Ctx(ctx).Printf("Error recording attendance: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("committing attendance: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetAttendanceReport summarizes attendance per member for sessions within
+// [from, to). Active members without any marks are included with zero counts.
+func (s *service) GetAttendanceReport(ctx context.Context, from, to time.Time) ([]models.AttendanceSummary, error) {
+	const query = `
+	SELECT m.id, m.name,
+	COUNT(a.member_id) FILTER (WHERE a.status = 'present'),
+	COUNT(a.member_id) FILTER (WHERE a.status = 'absent'),
+	COUNT(a.member_id) FILTER (WHERE a.status = 'excused')
+	FROM members m
+	LEFT JOIN attendance a ON a.member_id = m.id AND a.session_id IN (
+		SELECT id FROM practice_sessions
+		WHERE ($1::timestamptz IS NULL OR date >= $1)
+		AND ($2::timestamptz IS NULL OR date < $2)
+	)
+	GROUP BY m.id, m.name, m.is_active
+	HAVING m.is_active OR COUNT(a.member_id) > 0
+	ORDER BY m.name ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, nullTime(from), nullTime(to))
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting attendance report: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := []models.AttendanceSummary{}
+	for rows.Next() {
+		var row models.AttendanceSummary
+		if err := rows.Scan(&row.MemberID, &row.Name, &row.Present, &row.Absent, &row.Excused); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning attendance summary: %v", err)
+			continue
+		}
+		row.Percentage = attendancePercentage(row.Present, row.Absent)
+		report = append(report, row)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over attendance report: %v", err)
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"math"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createRateLimitBucketTable(db *sql.DB) error {
+	createRateLimitBucketTableSQL := `
+    CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+        key VARCHAR(255) PRIMARY KEY,
+        tokens DOUBLE PRECISION NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);`
+
+	_, err := db.Exec(createRateLimitBucketTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating rate limit bucket table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ===== external ===== //
+
+// TakeRateLimitToken refills the token bucket of key by rate tokens per
+// second, up to burst, and takes a token when one is left. It returns whether
+// a token was taken and how many remain. The row is locked while it's
+// updated so instances sharing the database count every request.
+func (s *service) TakeRateLimitToken(ctx context.Context, key string, burst int, rate float64, now time.Time) (bool, float64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error starting rate limit transaction: %v", err)
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	// a new client starts with a full bucket
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, $3)
+	ON CONFLICT (key) DO NOTHING`, key, float64(burst), now)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error creating rate limit bucket: %v", err)
+		return false, 0, err
+	}
+
+	var tokens float64
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+	SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key,
+	).Scan(&tokens, &updatedAt)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error getting rate limit bucket: %v", err)
+		return false, 0, err
+	}
+
+	if elapsed := now.Sub(updatedAt).Seconds(); elapsed > 0 {
+		tokens = math.Min(float64(burst), tokens+elapsed*rate)
+	}
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`, key, tokens, now)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error updating rate limit bucket: %v", err)
+		return false, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error committing rate limit bucket: %v", err)
+		return false, 0, err
+	}
+	return allowed, tokens, nil
+}
+
+// DeleteIdleRateLimitBuckets removes buckets not used since before, a bucket
+// idle for a whole window is full again and the same as no bucket
+func (s *service) DeleteIdleRateLimitBuckets(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error deleting idle rate limit buckets: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"backend/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// The repositories below are the aggregates Service is made of that also have
+// an in-memory implementation (see NewMemory). Both implementations have to
+// pass the suite in database/conformance, so errors are returned with the
+// same messages handlers match on.
+
+// AdminRepository manages admins, deletion is soft and the founder is permanent
+type AdminRepository interface {
+	CreateAdmin(ctx context.Context, admin models.Admin) (string, error)
+
+	GetAllAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error)
+	GetAllAdminsExceptFounder(ctx context.Context, page, pageSize int) ([]models.Admin, error)
+	GetAllAdminsIncludingDeleted(ctx context.Context, page, pageSize int) ([]models.Admin, error)
+	GetDeletedAdmins(ctx context.Context, page, pageSize int) ([]models.Admin, error)
+	GetAdmin(ctx context.Context, field, value string) (*models.Admin, error)
+	AdminSessionValid(ctx context.Context, adminID string, issuedAt time.Time) (bool, error)
+
+	GetAdminCount(ctx context.Context) (int, error)
+	CountAdmins(ctx context.Context, filter AdminFilter) (int, error)
+
+	UpdateAdmin(ctx context.Context, admin models.Admin) error
+
+	DeleteAdmin(ctx context.Context, field, value, reassignTo string) (*models.Admin, error)
+	RestoreAdmin(ctx context.Context, field, value string) (*models.Admin, error)
+}
+
+// EventRepository manages events, creating one also stores its images and
+// makes the creating admin its author
+type EventRepository interface {
+	CreateEvent(ctx context.Context, event models.Event, adminID string) (string, error)
+	GetEventByID(ctx context.Context, eventID string) (*models.Event, error)
+	GetAllEvents(ctx context.Context, page, pageSize int) ([]models.Event, error)
+}
+
+// ImageRepository manages the images of events, at most one per event is the display image
+type ImageRepository interface {
+	AddEventImage(ctx context.Context, eventID string, image models.EventImage) (string, error)
+	GetEventImages(ctx context.Context, eventID string) ([]models.EventImage, error)
+	SetDisplayImage(ctx context.Context, eventID, imageID string) error
+	RemoveEventImage(ctx context.Context, eventID, imageID string) error
+}
+
+// AuthorRepository manages which admins authored which events
+type AuthorRepository interface {
+	AddEventAuthor(ctx context.Context, eventID, adminID string) error
+	GetEventAuthors(ctx context.Context, eventID string) ([]models.Admin, error)
+	RemoveEventAuthor(ctx context.Context, eventID, adminID string) error
+}
+
+// Repositories bundles one implementation of every repository, the ones
+// returned together share their storage so cross aggregate rules hold
+type Repositories struct {
+	Admins  AdminRepository
+	Events  EventRepository
+	Images  ImageRepository
+	Authors AuthorRepository
+}
+
+// NewRepositories returns the postgres repositories, db must have its tables created
+func NewRepositories(db *sql.DB) Repositories {
+	s := New(db)
+	return Repositories{Admins: s, Events: s, Images: s, Authors: s}
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ===== internal ===== //
+
+func createSubscriberTable(db *sql.DB) error {
+	createSubscriberTableSQL := `
+    CREATE TABLE IF NOT EXISTS subscribers (
+        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        email VARCHAR(255) NOT NULL UNIQUE,
+        name VARCHAR(255) NOT NULL,
+        status VARCHAR(50) NOT NULL,
+        confirm_token_hash VARCHAR(64),
+        confirm_expires_at TIMESTAMP WITH TIME ZONE,
+        confirmed_at TIMESTAMP WITH TIME ZONE,
+        unsubscribed_at TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_subscribers_confirm_token_hash ON subscribers(confirm_token_hash);`
+
+	_, err := db.Exec(createSubscriberTableSQL)
+	if err != nil {
+		loggers.Error.Printf("Error creating subscriber table: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ===== external ===== //
+
+// ========== CREATE ========== //
+
+// CreatePendingSubscriber registers an email as pending confirmation with the
+// hash of the token sent in the opt-in email. Unsubscribed or still pending
+// addresses are moved back to pending with the new token, confirmed addresses
+// are left untouched and reported with alreadyConfirmed.
+func (s *service) CreatePendingSubscriber(ctx context.Context, email, name, tokenHash string, expiresAt time.Time) (alreadyConfirmed bool, err error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	name = strings.TrimSpace(name)
+	if !isValidEmail(email) {
+		return false, errors.New("invalid email format")
+	}
+	if len(email) > 255 || len(name) > 255 {
+		return false, errors.New("input too long")
+	}
+
+	const query = `
+	INSERT INTO subscribers (
+		created_at, updated_at, email, name, status,
+		confirm_token_hash, confirm_expires_at
+	) VALUES ($1, $1, $2, $3, 'pending', $4, $5)
+	ON CONFLICT (email) DO UPDATE SET
+	updated_at = EXCLUDED.updated_at, name = EXCLUDED.name, status = 'pending',
+	confirm_token_hash = EXCLUDED.confirm_token_hash,
+	confirm_expires_at = EXCLUDED.confirm_expires_at
+	WHERE subscribers.status != 'confirmed'`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), email, name, tokenHash, expiresAt)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error creating subscriber: %v", err)
+		return false, err
+	}
+	// no row is touched when the conflicting subscriber is already confirmed
+	n, _ := res.RowsAffected()
+	return n == 0, nil
+}
+
+// ========== READ ========== //
+
+func (s *service) GetConfirmedSubscribers(ctx context.Context) ([]models.Subscriber, error) {
+	const query = `
+	SELECT id, created_at, updated_at, email, name, status, confirmed_at, unsubscribed_at
+	FROM subscribers
+	WHERE status = 'confirmed'
+	ORDER BY confirmed_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("getting subscribers: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscribers := []models.Subscriber{}
+	for rows.Next() {
+		var sub models.Subscriber
+		if err := rows.Scan(
+			&sub.ID, &sub.CreatedAt, &sub.UpdatedAt, &sub.Email, &sub.Name,
+			&sub.Status, &sub.ConfirmedAt, &sub.UnsubscribedAt); err != nil {
+			loggers.Error.Ctx(ctx).Printf("scanning subscriber: %v", err)
+			continue // skip partial results
+		}
+		subscribers = append(subscribers, sub)
+	}
+	if err := rows.Err(); err != nil {
+		loggers.Error.Ctx(ctx).Printf("Error iterating over subscribers: %v", err)
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+// ========== UPDATE ========== //
+
+// ConfirmSubscriber completes the double opt-in for the pending subscriber
+// holding an unexpired token, tokens are single use
+func (s *service) ConfirmSubscriber(ctx context.Context, tokenHash string) error {
+	const query = `
+	UPDATE subscribers SET
+	updated_at = $1, status = 'confirmed', confirmed_at = $1,
+	confirm_token_hash = NULL, confirm_expires_at = NULL
+	WHERE confirm_token_hash = $2
+	AND status = 'pending'
+	AND confirm_expires_at > $1`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), tokenHash)
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("confirming subscriber: %v", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("invalid or expired token")
+	}
+	return nil
+}
+
+// UnsubscribeSubscriber is idempotent, unknown emails are not reported to
+// avoid leaking who is subscribed
+func (s *service) UnsubscribeSubscriber(ctx context.Context, email string) error {
+	const query = `
+	UPDATE subscribers SET
+	updated_at = $1, status = 'unsubscribed', unsubscribed_at = $1,
+	confirm_token_hash = NULL, confirm_expires_at = NULL
+	WHERE email = $2 AND status != 'unsubscribed'`
+
+	_, err := s.db.ExecContext(ctx, query, time.Now(), strings.ToLower(email))
+	if err != nil {
+		loggers.Error.Ctx(ctx).Printf("unsubscribing subscriber: %v", err)
+		return err
+	}
+	return nil
+}
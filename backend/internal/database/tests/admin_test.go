@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,15 +30,10 @@ func TestCreateAdminSuccess(t *testing.T) {
 		Name:     "john",
 		Email:    "john@mail.com",
 		Position: "idiot",
-		Status:   "Active",
+		Status:   "active",
 	}
 	expectedID := "some-uuid"
 
-	// mocking email existence check
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1)")).
-		WithArgs(admin.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-
 	// mocking insert query
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(expectedID)
 	mock.ExpectQuery("INSERT INTO admins").WithArgs(
@@ -67,14 +64,9 @@ func TestCreateAdminFailure(t *testing.T) {
 		Name:     "tuan",
 		Email:    "tuan@mail.com",
 		Position: "dev",
-		Status:   "Inactive",
+		Status:   "inactive",
 	}
 
-	// mocking email existence check
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1)")).
-		WithArgs(admin.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-
 	// set up the mock to expect a QueryRow and return an error
 	mock.ExpectQuery(
 		"INSERT INTO admins").WithArgs(
@@ -84,7 +76,7 @@ func TestCreateAdminFailure(t *testing.T) {
 
 	id, err := s.CreateAdmin(context.TODO(), admin)
 
-	assert.Error(t, err)
+	assert.EqualError(t, err, "sql error")
 	assert.Empty(t, id, "Expected ID to be empty when an error occurs")
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -106,20 +98,18 @@ func TestCreateAdminDuplicateEmail(t *testing.T) {
 		Name:     "doop",
 		Email:    duplicateEmail,
 		Position: "Founder",
-		Status:   "permanent",
+		Status:   "active",
 	}
 
-	// mocking email existence check, return true for duplicate email
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1)")).
-		WithArgs(admin.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	// the unique index on email rejects the insert
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO admins")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), admin.Name, admin.Email, admin.Position, admin.Status).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "admins_email_key"})
 
-	// mocking insert query
 	id, err := s.CreateAdmin(context.TODO(), admin)
 
-	assert.Error(t, err)
+	assert.EqualError(t, err, "email already exists")
 	assert.Empty(t, id, "Expected ID to be empty when an error occurs")
-	assert.Contains(t, err.Error(), "email already exists", "Expected error message for duplicate email")
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("There were unfulfilled expectations: %s", err)
@@ -141,7 +131,7 @@ func TestCreateAdminInvalidEmail(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid email", "Expected error message for invalid email")
 }
 
-// simulating a unique violation error -> race condition where the email becomes duplicated between the check and the insert
+// a unique violation reported by the lib/pq driver maps the same way
 func TestCreateAdminUniqueViolation(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -157,23 +147,21 @@ func TestCreateAdminUniqueViolation(t *testing.T) {
 		Status:   "active",
 	}
 
-	// mock database error during email existence check
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1)")).
-		WithArgs(admin.Email).
-		WillReturnError(fmt.Errorf("database error"))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO admins")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), admin.Name, admin.Email, admin.Position, admin.Status).
+		WillReturnError(&pq.Error{Code: "23505"})
 
 	id, err := s.CreateAdmin(context.Background(), admin)
 
-	assert.Error(t, err)
+	assert.EqualError(t, err, "email already exists")
 	assert.Empty(t, id, "Expected ID to be empty when an error occurs")
-	assert.Contains(t, err.Error(), "database error", "Expected error message for database error")
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("There were unfulfilled expectations: %s", err)
 	}
 }
 
-// if two requestse trying to create an admin with the same email
+// if two requests try to create an admin with the same email
 func TestCreateAdminRaceCondition(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -187,30 +175,32 @@ func TestCreateAdminRaceCondition(t *testing.T) {
 		Name:     "john",
 		Email:    "john@mail.com",
 		Position: "idiot",
-		Status:   "Active",
+		Status:   "active",
 	}
 
-	// mocking email existence check
-	// assuming that the first call will be successful and subsequent calls will fail
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1)")).
-		WithArgs(admin.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-
-	// set up mock for successful insert
+	// the first insert wins, the unique index rejects the others
 	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO admins")).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), admin.Name, admin.Email, admin.Position, admin.Status).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("some-uuid"))
+	for i := 0; i < 4; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO admins")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), admin.Name, admin.Email, admin.Position, admin.Status).
+			WillReturnError(&pgconn.PgError{Code: "23505"})
+	}
 
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 	successCount := 0
-	failureCount := 0
+	var failures []string
 	for i := 0; i < 5; i++ { // simulate 5 concurrent requests
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			_, err := s.CreateAdmin(context.Background(), admin)
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				failureCount++
+				failures = append(failures, err.Error())
 			} else {
 				successCount++
 			}
@@ -219,7 +209,13 @@ func TestCreateAdminRaceCondition(t *testing.T) {
 	wg.Wait()
 	// check only one insert was successful
 	assert.Equal(t, 1, successCount, "Only one admin creation should be successful")
-	assert.Equal(t, 4, failureCount, "Nine admin creations should fail")
+	assert.Equal(t, []string{
+		"email already exists", "email already exists", "email already exists", "email already exists",
+	}, failures)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
 }
 
 func TestGetAllAdmins(t *testing.T) {
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/models"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveAdminLoginAfterUnlink(t *testing.T) {
+	s := database.New(pgtest.NewDatabase(t))
+	ctx := context.Background()
+	const email = "jiating.lion.dragon@gmail.com"
+
+	// the founder has no identity yet, the first verified login links it
+	founder, err := s.ResolveAdminLogin(ctx, "google", "google-1", email, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// a second account of the same email isn't linked by email any more
+	_, err = s.ResolveAdminLogin(ctx, "oidc", "oidc-1", email, true)
+	assert.EqualError(t, err, "admin not found", "the founder already has an identity")
+
+	assert.NoError(t, s.LinkAdminIdentity(ctx, models.AdminIdentity{
+		AdminID: founder.ID, Provider: "oidc", ProviderUserID: "oidc-1", Email: email,
+	}))
+	admin, err := s.ResolveAdminLogin(ctx, "oidc", "oidc-1", email, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, founder.ID, admin.ID)
+	}
+
+	// unlinking keeps the account out even though its email still matches
+	assert.NoError(t, s.UnlinkAdminIdentity(ctx, founder.ID, "oidc", "oidc-1"))
+	_, err = s.ResolveAdminLogin(ctx, "oidc", "oidc-1", email, true)
+	assert.EqualError(t, err, "admin not found")
+
+	admin, err = s.ResolveAdminLogin(ctx, "google", "google-1", email, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, founder.ID, admin.ID)
+	}
+}
+
+func TestResolveAdminLoginNeedsVerifiedEmail(t *testing.T) {
+	s := database.New(pgtest.NewDatabase(t))
+	ctx := context.Background()
+
+	_, err := s.ResolveAdminLogin(ctx, "oidc", "oidc-1", "jiating.lion.dragon@gmail.com", false)
+	assert.EqualError(t, err, "admin not found")
+
+	founder, err := s.GetAdmin(ctx, "email", "jiating.lion.dragon@gmail.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	identities, err := s.GetAdminIdentities(ctx, founder.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, identities, "nothing is linked")
+}
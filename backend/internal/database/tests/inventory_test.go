@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOutAndCheckInItem(t *testing.T) {
+	s := database.New(pgtest.NewDatabase(t))
+	ctx := context.Background()
+
+	founder, err := s.GetAdmin(ctx, "email", "jiating.lion.dragon@gmail.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	memberID, err := s.CreateMember(ctx, models.Member{Name: "Mei", Role: "head"})
+	assert.NoError(t, err)
+	itemID, err := s.CreateInventoryItem(ctx, models.InventoryItem{Name: "Red lion head", Category: "lion_head"})
+	assert.NoError(t, err)
+
+	checkout := models.ItemCheckout{
+		ItemID: itemID, MemberID: &memberID, CheckedOutBy: founder.ID,
+		DueAt: time.Now().Add(48 * time.Hour), Notes: "parade",
+	}
+	_, err = s.CheckOutItem(ctx, checkout)
+	assert.NoError(t, err)
+	item, err := s.GetInventoryItem(ctx, itemID)
+	if assert.NoError(t, err) {
+		assert.True(t, item.CheckedOut)
+	}
+
+	_, err = s.CheckOutItem(ctx, checkout)
+	assert.EqualError(t, err, "item is already checked out")
+
+	assert.NoError(t, s.CheckInItem(ctx, itemID, models.CheckInRequest{Condition: "needs_repair", ConditionNotes: "torn mane"}))
+	assert.EqualError(t, s.CheckInItem(ctx, itemID, models.CheckInRequest{}), "item is not checked out")
+	item, err = s.GetInventoryItem(ctx, itemID)
+	if assert.NoError(t, err) {
+		assert.False(t, item.CheckedOut)
+		assert.Equal(t, "needs_repair", item.Condition)
+	}
+
+	// checked in items can go out again
+	_, err = s.CheckOutItem(ctx, checkout)
+	assert.NoError(t, err)
+	checkouts, err := s.GetItemCheckouts(ctx, itemID)
+	assert.NoError(t, err)
+	assert.Len(t, checkouts, 2)
+}
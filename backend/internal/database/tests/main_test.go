@@ -0,0 +1,10 @@
+package tests
+
+import (
+	"backend/internal/database/pgtest"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
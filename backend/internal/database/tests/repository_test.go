@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/database/conformance"
+	"backend/internal/database/pgtest"
+	"testing"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) database.Repositories {
+		return database.NewMemory()
+	})
+}
+
+// TestPostgresRepositories runs the suite against a throwaway postgres,
+// every subtest gets a database of its own
+func TestPostgresRepositories(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) database.Repositories {
+		return database.NewRepositories(pgtest.NewDatabase(t))
+	})
+}
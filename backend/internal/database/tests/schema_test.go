@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaIsApplied(t *testing.T) {
+	db := pgtest.NewDatabase(t)
+	s := database.New(db)
+	ctx := context.Background()
+
+	version, err := s.GetSchemaVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, database.SchemaVersion, version)
+
+	founder, err := s.GetAdmin(ctx, "email", "jiating.lion.dragon@gmail.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "permanent", founder.Status)
+		assert.NoError(t, uuid.Validate(founder.ID), "ids default to uuid_generate_v4")
+	}
+}
+
+// callers such as pgtest decide what a failed schema means, Open doesn't exit
+func TestOpenReportsSchemaErrors(t *testing.T) {
+	db, err := database.Open("postgres://admin@127.0.0.1:1/jiating?sslmode=disable&connect_timeout=1")
+	assert.Nil(t, db)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "error initializing tables: error creating admins table:")
+	}
+}
+
+func TestDeletingEventCascadesToAuthors(t *testing.T) {
+	db := pgtest.NewDatabase(t)
+	s := database.New(db)
+	ctx := context.Background()
+
+	adminID, err := s.CreateAdmin(ctx, models.Admin{Name: "Mei", Email: "mei@gmail.com", Position: "Member", Status: "active"})
+	assert.NoError(t, err)
+	eventID, err := s.CreateEvent(ctx, models.Event{EventTitle: "Parade", Metatitle: "Parade", Slug: "parade", Date: time.Now()}, adminID)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(`DELETE FROM events WHERE id = $1`, eventID)
+	assert.NoError(t, err)
+
+	var authors int
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM event_authors WHERE event_id = $1`, eventID).Scan(&authors))
+	assert.Zero(t, authors)
+}
+
+func TestConstraintViolationsAreReported(t *testing.T) {
+	s := database.New(pgtest.NewDatabase(t))
+	ctx := context.Background()
+
+	_, err := s.CreateAPIToken(ctx, models.APIToken{
+		AdminID: uuid.NewString(), Name: "ci", Prefix: "jt_abcd", Scopes: []string{"events:read"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, "hash")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid admin id", err.Error(), "foreign key violations are mapped with the pgx driver")
+	}
+
+	_, err = s.CreateAdmin(ctx, models.Admin{Name: "Founder", Email: "jiating.lion.dragon@gmail.com", Status: "active"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "email already exists", err.Error())
+	}
+}
+
+func TestMigrateRenamesPerformanceAssignmentColumn(t *testing.T) {
+	db := pgtest.NewDatabase(t)
+	s := database.New(db)
+	ctx := context.Background()
+
+	// an admin who is on the roster and one who isn't
+	adminID, err := s.CreateAdmin(ctx, models.Admin{Name: "Mei", Email: "mei@gmail.com", Position: "Member", Status: "active"})
+	assert.NoError(t, err)
+	otherID, err := s.CreateAdmin(ctx, models.Admin{Name: "Bo", Email: "bo@gmail.com", Position: "Member", Status: "active"})
+	assert.NoError(t, err)
+	memberID, err := s.CreateMember(ctx, models.Member{Name: "Mei", Role: "head", AdminID: &adminID})
+	assert.NoError(t, err)
+	start := time.Now().Add(24 * time.Hour)
+	assigned, err := s.CreatePerformance(ctx, models.Performance{
+		Title: "Parade", Location: "Main St", StartTime: start, EndTime: start.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	// the table as the first version of the performance calendar created it
+	_, err = db.Exec(`
+	DROP TABLE performance_assignments;
+	CREATE TABLE performance_assignments (
+		performance_id UUID NOT NULL,
+		admin_id UUID NOT NULL,
+		PRIMARY KEY (performance_id, admin_id),
+		FOREIGN KEY (performance_id) REFERENCES performances(id) ON DELETE CASCADE,
+		FOREIGN KEY (admin_id) REFERENCES admins(id) ON DELETE NO ACTION
+	)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO performance_assignments (performance_id, admin_id) VALUES ($1, $2), ($1, $3)`,
+		assigned, adminID, otherID)
+	assert.NoError(t, err)
+	assert.NoError(t, database.Migrate(db))
+	assert.NoError(t, database.Migrate(db), "migrating twice is a no-op")
+
+	performance, err := s.GetPerformance(ctx, assigned)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{memberID}, performance.MemberIDs, "admins on the roster are kept as their member")
+	}
+
+	performanceID, err := s.CreatePerformance(ctx, models.Performance{
+		Title: "Parade", Location: "Main St", StartTime: start, EndTime: start.Add(time.Hour),
+		MemberIDs: []string{memberID},
+	})
+	assert.NoError(t, err)
+	performance, err = s.GetPerformance(ctx, performanceID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{memberID}, performance.MemberIDs)
+	}
+
+	_, err = s.CreatePerformance(ctx, models.Performance{
+		Title: "Parade", Location: "Main St", StartTime: start, EndTime: start.Add(time.Hour),
+		MemberIDs: []string{uuid.NewString()},
+	})
+	assert.EqualError(t, err, "invalid member id", "assignments reference members after the rename")
+}
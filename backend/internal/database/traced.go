@@ -0,0 +1,500 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/internal/tracing"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// traced wraps every Service method that takes a context in a span named
+// database.<Method>, so slow requests show which query they waited on.
+// Keep it in sync with the Service interface.
+type traced struct {
+	next Service
+}
+
+func (t *traced) Ping(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "database.Ping")
+	err := t.next.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) Stats() sql.DBStats {
+	return t.next.Stats()
+}
+
+func (t *traced) GetSchemaVersion(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "database.GetSchemaVersion")
+	res, err := t.next.GetSchemaVersion(ctx)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) Close() error {
+	return t.next.Close()
+}
+
+func (t *traced) CreateAdmin(ctx context.Context, admin models.Admin) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateAdmin")
+	res, err := t.next.CreateAdmin(ctx, admin)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAllAdmins(ctx context.Context, page int, pageSize int) ([]models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAllAdmins")
+	res, err := t.next.GetAllAdmins(ctx, page, pageSize)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAllAdminsExceptFounder(ctx context.Context, page int, pageSize int) ([]models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAllAdminsExceptFounder")
+	res, err := t.next.GetAllAdminsExceptFounder(ctx, page, pageSize)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAllAdminsIncludingDeleted(ctx context.Context, page int, pageSize int) ([]models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAllAdminsIncludingDeleted")
+	res, err := t.next.GetAllAdminsIncludingDeleted(ctx, page, pageSize)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetDeletedAdmins(ctx context.Context, page int, pageSize int) ([]models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetDeletedAdmins")
+	res, err := t.next.GetDeletedAdmins(ctx, page, pageSize)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAdmin(ctx context.Context, field string, value string) (*models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAdmin")
+	res, err := t.next.GetAdmin(ctx, field, value)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) AdminSessionValid(ctx context.Context, adminID string, issuedAt time.Time) (bool, error) {
+	ctx, span := tracing.Start(ctx, "database.AdminSessionValid")
+	res, err := t.next.AdminSessionValid(ctx, adminID, issuedAt)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAdminCount(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAdminCount")
+	res, err := t.next.GetAdminCount(ctx)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) CountAdmins(ctx context.Context, filter AdminFilter) (int, error) {
+	ctx, span := tracing.Start(ctx, "database.CountAdmins")
+	res, err := t.next.CountAdmins(ctx, filter)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UpdateAdmin(ctx context.Context, admin models.Admin) error {
+	ctx, span := tracing.Start(ctx, "database.UpdateAdmin")
+	err := t.next.UpdateAdmin(ctx, admin)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) DeleteAdmin(ctx context.Context, field string, value string, reassignTo string) (*models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.DeleteAdmin")
+	res, err := t.next.DeleteAdmin(ctx, field, value, reassignTo)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) RestoreAdmin(ctx context.Context, field string, value string) (*models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.RestoreAdmin")
+	res, err := t.next.RestoreAdmin(ctx, field, value)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) LinkAdminIdentity(ctx context.Context, identity models.AdminIdentity) error {
+	ctx, span := tracing.Start(ctx, "database.LinkAdminIdentity")
+	err := t.next.LinkAdminIdentity(ctx, identity)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) ResolveAdminLogin(ctx context.Context, provider string, providerUserID string, email string, emailVerified bool) (*models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.ResolveAdminLogin")
+	res, err := t.next.ResolveAdminLogin(ctx, provider, providerUserID, email, emailVerified)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAdminIdentities(ctx context.Context, adminID string) ([]models.AdminIdentity, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAdminIdentities")
+	res, err := t.next.GetAdminIdentities(ctx, adminID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UnlinkAdminIdentity(ctx context.Context, adminID string, provider string, providerUserID string) error {
+	ctx, span := tracing.Start(ctx, "database.UnlinkAdminIdentity")
+	err := t.next.UnlinkAdminIdentity(ctx, adminID, provider, providerUserID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CreateAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateAPIToken")
+	res, err := t.next.CreateAPIToken(ctx, token, tokenHash)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAPITokens(ctx context.Context, adminID string) ([]models.APIToken, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAPITokens")
+	res, err := t.next.GetAPITokens(ctx, adminID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) AuthenticateAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	ctx, span := tracing.Start(ctx, "database.AuthenticateAPIToken")
+	res, err := t.next.AuthenticateAPIToken(ctx, tokenHash)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) RevokeAPIToken(ctx context.Context, adminID string, id string) error {
+	ctx, span := tracing.Start(ctx, "database.RevokeAPIToken")
+	err := t.next.RevokeAPIToken(ctx, adminID, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) TakeRateLimitToken(ctx context.Context, key string, burst int, rate float64, now time.Time) (bool, float64, error) {
+	ctx, span := tracing.Start(ctx, "database.TakeRateLimitToken")
+	allowed, tokens, err := t.next.TakeRateLimitToken(ctx, key, burst, rate, now)
+	tracing.End(span, err)
+	return allowed, tokens, err
+}
+
+func (t *traced) DeleteIdleRateLimitBuckets(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "database.DeleteIdleRateLimitBuckets")
+	res, err := t.next.DeleteIdleRateLimitBuckets(ctx, before)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) CreateAdminInvitation(ctx context.Context, invitation models.AdminInvitation, tokenHash string) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateAdminInvitation")
+	res, err := t.next.CreateAdminInvitation(ctx, invitation, tokenHash)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAdminInvitations(ctx context.Context, status string) ([]models.AdminInvitation, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAdminInvitations")
+	res, err := t.next.GetAdminInvitations(ctx, status)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) AcceptAdminInvitation(ctx context.Context, tokenHash string, accountEmail string, bind bool) (*models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.AcceptAdminInvitation")
+	res, err := t.next.AcceptAdminInvitation(ctx, tokenHash, accountEmail, bind)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) RevokeAdminInvitation(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "database.RevokeAdminInvitation")
+	err := t.next.RevokeAdminInvitation(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CreateEvent(ctx context.Context, event models.Event, adminID string) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateEvent")
+	res, err := t.next.CreateEvent(ctx, event, adminID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetEventByID(ctx context.Context, eventID string) (*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "database.GetEventByID")
+	res, err := t.next.GetEventByID(ctx, eventID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetAllEvents(ctx context.Context, page int, pageSize int) ([]models.Event, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAllEvents")
+	res, err := t.next.GetAllEvents(ctx, page, pageSize)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) AddEventImage(ctx context.Context, eventID string, image models.EventImage) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.AddEventImage")
+	res, err := t.next.AddEventImage(ctx, eventID, image)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetEventImages(ctx context.Context, eventID string) ([]models.EventImage, error) {
+	ctx, span := tracing.Start(ctx, "database.GetEventImages")
+	res, err := t.next.GetEventImages(ctx, eventID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) SetDisplayImage(ctx context.Context, eventID string, imageID string) error {
+	ctx, span := tracing.Start(ctx, "database.SetDisplayImage")
+	err := t.next.SetDisplayImage(ctx, eventID, imageID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) RemoveEventImage(ctx context.Context, eventID string, imageID string) error {
+	ctx, span := tracing.Start(ctx, "database.RemoveEventImage")
+	err := t.next.RemoveEventImage(ctx, eventID, imageID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) AddEventAuthor(ctx context.Context, eventID string, adminID string) error {
+	ctx, span := tracing.Start(ctx, "database.AddEventAuthor")
+	err := t.next.AddEventAuthor(ctx, eventID, adminID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) GetEventAuthors(ctx context.Context, eventID string) ([]models.Admin, error) {
+	ctx, span := tracing.Start(ctx, "database.GetEventAuthors")
+	res, err := t.next.GetEventAuthors(ctx, eventID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) RemoveEventAuthor(ctx context.Context, eventID string, adminID string) error {
+	ctx, span := tracing.Start(ctx, "database.RemoveEventAuthor")
+	err := t.next.RemoveEventAuthor(ctx, eventID, adminID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CreatePerformance(ctx context.Context, performance models.Performance) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreatePerformance")
+	res, err := t.next.CreatePerformance(ctx, performance)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetPerformance(ctx context.Context, id string) (*models.Performance, error) {
+	ctx, span := tracing.Start(ctx, "database.GetPerformance")
+	res, err := t.next.GetPerformance(ctx, id)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetPerformances(ctx context.Context, from time.Time, to time.Time, publicOnly bool) ([]models.Performance, error) {
+	ctx, span := tracing.Start(ctx, "database.GetPerformances")
+	res, err := t.next.GetPerformances(ctx, from, to, publicOnly)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UpdatePerformance(ctx context.Context, performance models.Performance) error {
+	ctx, span := tracing.Start(ctx, "database.UpdatePerformance")
+	err := t.next.UpdatePerformance(ctx, performance)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) DeletePerformance(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "database.DeletePerformance")
+	err := t.next.DeletePerformance(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CreateMember(ctx context.Context, member models.Member) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateMember")
+	res, err := t.next.CreateMember(ctx, member)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetMember(ctx context.Context, id string) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "database.GetMember")
+	res, err := t.next.GetMember(ctx, id)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetMembers(ctx context.Context, activeOnly bool) ([]models.Member, error) {
+	ctx, span := tracing.Start(ctx, "database.GetMembers")
+	res, err := t.next.GetMembers(ctx, activeOnly)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UpdateMember(ctx context.Context, member models.Member) error {
+	ctx, span := tracing.Start(ctx, "database.UpdateMember")
+	err := t.next.UpdateMember(ctx, member)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) DeleteMember(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "database.DeleteMember")
+	err := t.next.DeleteMember(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CreatePracticeSession(ctx context.Context, session models.PracticeSession) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreatePracticeSession")
+	res, err := t.next.CreatePracticeSession(ctx, session)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetPracticeSession(ctx context.Context, id string) (*models.PracticeSession, error) {
+	ctx, span := tracing.Start(ctx, "database.GetPracticeSession")
+	res, err := t.next.GetPracticeSession(ctx, id)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetPracticeSessions(ctx context.Context, from time.Time, to time.Time) ([]models.PracticeSession, error) {
+	ctx, span := tracing.Start(ctx, "database.GetPracticeSessions")
+	res, err := t.next.GetPracticeSessions(ctx, from, to)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UpdatePracticeSession(ctx context.Context, session models.PracticeSession) error {
+	ctx, span := tracing.Start(ctx, "database.UpdatePracticeSession")
+	err := t.next.UpdatePracticeSession(ctx, session)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) DeletePracticeSession(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "database.DeletePracticeSession")
+	err := t.next.DeletePracticeSession(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) RecordAttendance(ctx context.Context, sessionID string, adminID string, records []models.AttendanceRecord) error {
+	ctx, span := tracing.Start(ctx, "database.RecordAttendance")
+	err := t.next.RecordAttendance(ctx, sessionID, adminID, records)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) GetAttendanceReport(ctx context.Context, from time.Time, to time.Time) ([]models.AttendanceSummary, error) {
+	ctx, span := tracing.Start(ctx, "database.GetAttendanceReport")
+	res, err := t.next.GetAttendanceReport(ctx, from, to)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) CreateInventoryItem(ctx context.Context, item models.InventoryItem) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CreateInventoryItem")
+	res, err := t.next.CreateInventoryItem(ctx, item)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetInventoryItem(ctx context.Context, id string) (*models.InventoryItem, error) {
+	ctx, span := tracing.Start(ctx, "database.GetInventoryItem")
+	res, err := t.next.GetInventoryItem(ctx, id)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetInventoryItems(ctx context.Context, category string) ([]models.InventoryItem, error) {
+	ctx, span := tracing.Start(ctx, "database.GetInventoryItems")
+	res, err := t.next.GetInventoryItems(ctx, category)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) UpdateInventoryItem(ctx context.Context, item models.InventoryItem) error {
+	ctx, span := tracing.Start(ctx, "database.UpdateInventoryItem")
+	err := t.next.UpdateInventoryItem(ctx, item)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) DeleteInventoryItem(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "database.DeleteInventoryItem")
+	err := t.next.DeleteInventoryItem(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) CheckOutItem(ctx context.Context, checkout models.ItemCheckout) (string, error) {
+	ctx, span := tracing.Start(ctx, "database.CheckOutItem")
+	res, err := t.next.CheckOutItem(ctx, checkout)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) CheckInItem(ctx context.Context, itemID string, req models.CheckInRequest) error {
+	ctx, span := tracing.Start(ctx, "database.CheckInItem")
+	err := t.next.CheckInItem(ctx, itemID, req)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) GetItemCheckouts(ctx context.Context, itemID string) ([]models.ItemCheckout, error) {
+	ctx, span := tracing.Start(ctx, "database.GetItemCheckouts")
+	res, err := t.next.GetItemCheckouts(ctx, itemID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetOverdueCheckouts(ctx context.Context, asOf time.Time) ([]models.ItemCheckout, error) {
+	ctx, span := tracing.Start(ctx, "database.GetOverdueCheckouts")
+	res, err := t.next.GetOverdueCheckouts(ctx, asOf)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) CreatePendingSubscriber(ctx context.Context, email string, name string, tokenHash string, expiresAt time.Time) (bool, error) {
+	ctx, span := tracing.Start(ctx, "database.CreatePendingSubscriber")
+	res, err := t.next.CreatePendingSubscriber(ctx, email, name, tokenHash, expiresAt)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) GetConfirmedSubscribers(ctx context.Context) ([]models.Subscriber, error) {
+	ctx, span := tracing.Start(ctx, "database.GetConfirmedSubscribers")
+	res, err := t.next.GetConfirmedSubscribers(ctx)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t *traced) ConfirmSubscriber(ctx context.Context, tokenHash string) error {
+	ctx, span := tracing.Start(ctx, "database.ConfirmSubscriber")
+	err := t.next.ConfirmSubscriber(ctx, tokenHash)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) UnsubscribeSubscriber(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "database.UnsubscribeSubscriber")
+	err := t.next.UnsubscribeSubscriber(ctx, email)
+	tracing.End(span, err)
+	return err
+}
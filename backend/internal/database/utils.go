@@ -4,8 +4,14 @@ import (
 	"backend/internal/models"
 	"errors"
 	"html"
+	"math"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 )
 
 // pagination utils
@@ -19,6 +25,28 @@ func getOffset(page, pageSize int) int {
 	return (page - 1) * pageSize
 }
 
+// nullTime maps the zero time to NULL so optional range bounds can be passed as query params
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// sqlState returns the postgres error code of err or "" for other errors,
+// pgx and lib/pq report it with different types
+func sqlState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
 // data validation and sanitization
 
 func isValidEmail(email string) bool {
@@ -34,6 +62,34 @@ func (s *service) emailExists(email string) (bool, error) {
 }
 
 func SanitizeAdminInput(admin *models.Admin) error {
+	if err := sanitizeAdminFields(admin); err != nil {
+		return err
+	}
+	// validate admin status
+	if admin.Status != "active" && admin.Status != "inactive" && admin.Status != "hiatus" {
+		return errors.New("invalid admin status")
+	}
+	return nil
+}
+
+// sanitizeAdminUpdate sanitizes update, an update of the admin current. The
+// permanent founder can only change their name, a new status, email or
+// position would leave them unprotected.
+func sanitizeAdminUpdate(current models.Admin, update *models.Admin) error {
+	if current.Status != "permanent" {
+		return SanitizeAdminInput(update)
+	}
+	if err := sanitizeAdminFields(update); err != nil {
+		return err
+	}
+	if update.Status != current.Status || update.Email != current.Email || update.Position != current.Position {
+		return errors.New("cannot change a permanent admin")
+	}
+	return nil
+}
+
+// sanitizeAdminFields validates and normalises everything but the status
+func sanitizeAdminFields(admin *models.Admin) error {
 	if admin == nil {
 		return errors.New("admin is nil")
 	}
@@ -51,9 +107,228 @@ func SanitizeAdminInput(admin *models.Admin) error {
 	}
 	// escaping special characters for name to prevent XSS attacks
 	admin.Name = html.EscapeString(admin.Name)
-	// validate admin status
-	if admin.Status != "active" && admin.Status != "inactive" && admin.Status != "hiatus" {
-		return errors.New("invalid admin status")
+	return nil
+}
+
+func SanitizePerformanceInput(performance *models.Performance) error {
+	if performance == nil {
+		return errors.New("performance is nil")
+	}
+	performance.Title = strings.TrimSpace(performance.Title)
+	performance.Location = strings.TrimSpace(performance.Location)
+	performance.Description = strings.TrimSpace(performance.Description)
+	if performance.Title == "" {
+		return errors.New("missing title")
+	}
+	// length limits from database schema
+	if len(performance.Title) > 255 || len(performance.Location) > 255 || len(performance.Description) > 2000 {
+		return errors.New("input too long")
+	}
+	if performance.StartTime.IsZero() || !performance.EndTime.After(performance.StartTime) {
+		return errors.New("invalid performance time range")
+	}
+	// postgres would reject them as invalid input instead of a missing member
+	for _, id := range performance.MemberIDs {
+		if uuid.Validate(id) != nil {
+			return errors.New("invalid member id")
+		}
+	}
+	return nil
+}
+
+// MemberRoles are the positions a member can hold on the team
+var MemberRoles = []string{"head", "tail", "drummer", "cymbals", "gong"}
+
+func isValidMemberRole(role string) bool {
+	return contains(MemberRoles, role)
+}
+
+func SanitizeMemberInput(member *models.Member) error {
+	if member == nil {
+		return errors.New("member is nil")
+	}
+	member.Name = strings.TrimSpace(member.Name)
+	member.ChineseName = strings.TrimSpace(member.ChineseName)
+	member.Role = strings.ToLower(strings.TrimSpace(member.Role))
+	member.PhotoURL = strings.TrimSpace(member.PhotoURL)
+	member.Bio = strings.TrimSpace(member.Bio)
+	if member.Name == "" {
+		return errors.New("missing name")
+	}
+	// length limits from database schema
+	if len(member.Name) > 255 || len(member.ChineseName) > 255 || len(member.PhotoURL) > 255 || len(member.Bio) > 2000 {
+		return errors.New("input too long")
+	}
+	// escaping special characters for public facing text to prevent XSS attacks
+	member.Name = html.EscapeString(member.Name)
+	member.ChineseName = html.EscapeString(member.ChineseName)
+	member.Bio = html.EscapeString(member.Bio)
+	if !isValidMemberRole(member.Role) {
+		return errors.New("invalid member role")
+	}
+	if member.JoinDate.IsZero() {
+		member.JoinDate = time.Now()
+	}
+	if member.AdminID != nil && *member.AdminID == "" {
+		member.AdminID = nil
+	}
+	return nil
+}
+
+func SanitizePracticeSessionInput(session *models.PracticeSession) error {
+	if session == nil {
+		return errors.New("practice session is nil")
+	}
+	session.Location = strings.TrimSpace(session.Location)
+	session.Notes = strings.TrimSpace(session.Notes)
+	if session.Date.IsZero() {
+		return errors.New("missing date")
+	}
+	// length limits from database schema
+	if len(session.Location) > 255 || len(session.Notes) > 2000 {
+		return errors.New("input too long")
+	}
+	return nil
+}
+
+func isValidAttendanceStatus(status string) bool {
+	return status == "present" || status == "absent" || status == "excused"
+}
+
+// attendancePercentage rounds to two decimals, members with no countable sessions get 0
+func attendancePercentage(present, absent int) float64 {
+	if present+absent == 0 {
+		return 0
+	}
+	return math.Round(float64(present)/float64(present+absent)*10000) / 100
+}
+
+// ItemCategories and ItemConditions are the allowed values for inventory items
+var (
+	ItemCategories = []string{"lion_head", "drum", "cymbals", "gong", "costume", "other"}
+	ItemConditions = []string{"good", "fair", "needs_repair", "retired"}
+)
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidItemCondition(condition string) bool {
+	return contains(ItemConditions, condition)
+}
+
+func SanitizeInventoryItemInput(item *models.InventoryItem) error {
+	if item == nil {
+		return errors.New("inventory item is nil")
+	}
+	item.Name = strings.TrimSpace(item.Name)
+	item.Category = strings.ToLower(strings.TrimSpace(item.Category))
+	item.Condition = strings.ToLower(strings.TrimSpace(item.Condition))
+	item.ConditionNotes = strings.TrimSpace(item.ConditionNotes)
+	item.PhotoKey = strings.TrimSpace(item.PhotoKey)
+	if item.Name == "" {
+		return errors.New("missing name")
+	}
+	// length limits from database schema
+	if len(item.Name) > 255 || len(item.ConditionNotes) > 2000 || len(item.PhotoKey) > 255 {
+		return errors.New("input too long")
+	}
+	if !contains(ItemCategories, item.Category) {
+		return errors.New("invalid item category")
+	}
+	if item.Condition == "" {
+		item.Condition = "good"
+	}
+	if !isValidItemCondition(item.Condition) {
+		return errors.New("invalid item condition")
+	}
+	return nil
+}
+
+func SanitizeCheckoutInput(checkout *models.ItemCheckout) error {
+	if checkout == nil {
+		return errors.New("checkout is nil")
+	}
+	if checkout.PerformanceID != nil && *checkout.PerformanceID == "" {
+		checkout.PerformanceID = nil
+	}
+	if checkout.MemberID != nil && *checkout.MemberID == "" {
+		checkout.MemberID = nil
+	}
+	if checkout.PerformanceID == nil && checkout.MemberID == nil {
+		return errors.New("checkout requires a performance or member")
+	}
+	if checkout.DueAt.IsZero() || checkout.DueAt.Before(time.Now()) {
+		return errors.New("invalid due date")
+	}
+	checkout.Notes = strings.TrimSpace(checkout.Notes)
+	if len(checkout.Notes) > 2000 {
+		return errors.New("input too long")
+	}
+	return nil
+}
+
+// InvitationStatuses are derived from the invitation timestamps
+var InvitationStatuses = []string{"pending", "accepted", "expired", "revoked"}
+
+func SanitizeAdminInvitationInput(invitation *models.AdminInvitation) error {
+	if invitation == nil {
+		return errors.New("invitation is nil")
+	}
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+	invitation.Name = strings.TrimSpace(invitation.Name)
+	invitation.Position = strings.TrimSpace(invitation.Position)
+	if !isValidEmail(invitation.Email) {
+		return errors.New("invalid email format")
+	}
+	if invitation.Name == "" {
+		return errors.New("missing name")
+	}
+	// length limits from database schema
+	if len(invitation.Name) > 255 || len(invitation.Position) > 255 {
+		return errors.New("input too long")
+	}
+	return nil
+}
+
+// APITokenResources are the /api route groups tokens can be scoped to,
+// a scope is <resource>:read or <resource>:write, write implies read
+var APITokenResources = []string{"admins", "events", "members", "performances", "practices", "inventory", "calendar"}
+
+func IsAPITokenResource(resource string) bool {
+	return contains(APITokenResources, resource)
+}
+
+// MaxAPITokenLifetime caps how long a personal access token can live
+const MaxAPITokenLifetime = 365 * 24 * time.Hour
+
+func SanitizeAPITokenInput(token *models.APIToken) error {
+	if token == nil {
+		return errors.New("api token is nil")
+	}
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Name == "" {
+		return errors.New("missing name")
+	}
+	if len(token.Name) > 255 {
+		return errors.New("input too long")
+	}
+	if len(token.Scopes) == 0 {
+		return errors.New("missing scopes")
+	}
+	for _, scope := range token.Scopes {
+		resource, access, _ := strings.Cut(scope, ":")
+		if !IsAPITokenResource(resource) || (access != "read" && access != "write") {
+			return errors.New("invalid scope")
+		}
+	}
+	if !token.ExpiresAt.After(time.Now()) || token.ExpiresAt.After(time.Now().Add(MaxAPITokenLifetime)) {
+		return errors.New("invalid expiration")
 	}
 	return nil
 }
@@ -1,24 +1,67 @@
 package email
 
 import (
-	"os"
+	"backend/internal/config"
+	"context"
+	"errors"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
-func SendEmail(name, fromEmail, subject, message string) error {
+// Service sends the emails of the app through the configured SMTP server
+type Service interface {
+	SendEmail(name, fromEmail, subject, message string) error
+	SendSubscriptionConfirmation(to, name, confirmURL string) error
+	SendAdminInvitation(to, name, inviterName, acceptURL, bindURL string, expiresAt time.Time) error
+	SendNewsletter(ctx context.Context, newsletter Newsletter, recipients []NewsletterRecipient, batchSize int, pause time.Duration) (int, error)
+	Ping(ctx context.Context) error
+}
+
+type service struct {
+	cfg config.Email
+}
+
+func NewService(cfg config.Email) Service {
+	return &service{cfg: cfg}
+}
+
+func (s *service) newDialer() *gomail.Dialer {
+	return gomail.NewDialer(s.cfg.Host, s.cfg.Port, s.cfg.User, s.cfg.Password)
+}
+
+// SendEmail forwards a contact form submission to the team inbox
+func (s *service) SendEmail(name, fromEmail, subject, message string) error {
 	m := gomail.NewMessage()
-	m.SetHeader("From", os.Getenv("EMAIL_USER"))
-	m.SetHeader("To", os.Getenv("EMAIL_USER"))
+	m.SetHeader("From", s.cfg.User)
+	m.SetHeader("To", s.cfg.User)
 	m.SetHeader("Subject", subject)
 	m.SetBody("text/plain", "Message from "+name+" ("+fromEmail+"):\n\n"+message)
 
-	// setup auth info
-	d := gomail.NewDialer(
-		os.Getenv("EMAIL_HOST"), 587,
-		os.Getenv("EMAIL_USER"), os.Getenv("EMAIL_PASSWORD"),
-	)
-
 	// send the email
-	return d.DialAndSend(m)
+	return s.newDialer().DialAndSend(m)
+}
+
+// Ping connects and authenticates to the SMTP server without sending anything
+func (s *service) Ping(ctx context.Context) error {
+	if s.cfg.Host == "" {
+		return errors.New("EMAIL_HOST is not set")
+	}
+
+	// gomail can't be canceled, a late result is simply dropped
+	result := make(chan error, 1)
+	go func() {
+		conn, err := s.newDialer().Dial()
+		if err == nil {
+			err = conn.Close()
+		}
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,48 @@
+package email
+
+import (
+	"bytes"
+	texttemplate "text/template"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// SendAdminInvitation mails the links an invitee opens to become an admin,
+// bindURL accepts with a Google account that uses another email address.
+func (s *service) SendAdminInvitation(to, name, inviterName, acceptURL, bindURL string, expiresAt time.Time) error {
+	var body bytes.Buffer
+	if err := invitationTemplate.Execute(&body, map[string]string{
+		"Name":      name,
+		"Inviter":   inviterName,
+		"AcceptURL": acceptURL,
+		"BindURL":   bindURL,
+		"ExpiresAt": expiresAt.Format("Monday, January 2, 2006 at 3:04 PM MST"),
+	}); err != nil {
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.cfg.User)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "You've been invited to become a Jiating admin")
+	m.SetBody("text/plain", body.String())
+
+	return s.newDialer().DialAndSend(m)
+}
+
+var invitationTemplate = texttemplate.Must(texttemplate.New("invitation").Parse(
+	`Hi {{.Name}},
+
+{{.Inviter}} invited you to help manage the Jiating website. Open the link below
+and sign in with your Google account to accept:
+
+{{.AcceptURL}}
+
+To sign in with a Google account that uses a different email address, use
+this link instead:
+
+{{.BindURL}}
+
+The invitation can only be used once and expires on {{.ExpiresAt}}.
+`))
@@ -0,0 +1,165 @@
+package email
+
+import (
+	"backend/internal/models"
+	"backend/loggers"
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// DialFunc opens an SMTP connection, batches reuse one connection
+type DialFunc func() (gomail.SendCloser, error)
+
+// SendSubscriptionConfirmation sends the double opt-in email to a new subscriber.
+func (s *service) SendSubscriptionConfirmation(to, name, confirmURL string) error {
+	var body bytes.Buffer
+	if err := confirmationTemplate.Execute(&body, map[string]string{
+		"Name":       name,
+		"ConfirmURL": confirmURL,
+	}); err != nil {
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.cfg.User)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Confirm your subscription to Jiating news")
+	m.SetBody("text/plain", body.String())
+
+	return s.newDialer().DialAndSend(m)
+}
+
+// NewsletterRecipient is a confirmed subscriber along with their personal unsubscribe link
+type NewsletterRecipient struct {
+	Email          string
+	UnsubscribeURL string
+}
+
+// Newsletter is a rendered newsletter, shared by every recipient
+type Newsletter struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// RenderEventNewsletter renders the summary of an event for subscribers.
+func RenderEventNewsletter(event models.Event, eventURL string) (Newsletter, error) {
+	data := map[string]interface{}{
+		"Title":       event.EventTitle,
+		"Date":        event.Date.Format("Monday, January 2, 2006"),
+		"Description": event.Description,
+		"URL":         eventURL,
+	}
+
+	var html, text bytes.Buffer
+	if err := newsletterHTMLTemplate.Execute(&html, data); err != nil {
+		return Newsletter{}, err
+	}
+	if err := newsletterTextTemplate.Execute(&text, data); err != nil {
+		return Newsletter{}, err
+	}
+
+	return Newsletter{
+		Subject: fmt.Sprintf("Jiating: %s", event.EventTitle),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// NewsletterMessages builds one message per recipient so each carries its own
+// one-click unsubscribe headers (RFC 8058).
+func NewsletterMessages(from string, newsletter Newsletter, recipients []NewsletterRecipient) []*gomail.Message {
+	messages := make([]*gomail.Message, 0, len(recipients))
+	for _, r := range recipients {
+		m := gomail.NewMessage()
+		m.SetHeader("From", from)
+		m.SetHeader("To", r.Email)
+		m.SetHeader("Subject", newsletter.Subject)
+		m.SetHeader("List-Unsubscribe", "<"+r.UnsubscribeURL+">")
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+		m.SetBody("text/plain", newsletter.Text+"\n\nUnsubscribe: "+r.UnsubscribeURL)
+		m.AddAlternative("text/html", newsletter.HTML+
+			`<p style="font-size:12px"><a href="`+htmltemplate.HTMLEscapeString(r.UnsubscribeURL)+`">Unsubscribe</a></p>`)
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// SendNewsletter mails the newsletter to every recipient in throttled batches
+// using the configured SMTP server. It blocks until every batch is sent or ctx is done.
+func (s *service) SendNewsletter(ctx context.Context, newsletter Newsletter, recipients []NewsletterRecipient, batchSize int, pause time.Duration) (int, error) {
+	dialer := s.newDialer()
+	return SendInBatches(ctx, dialer.Dial, NewsletterMessages(s.cfg.User, newsletter, recipients), batchSize, pause)
+}
+
+// SendInBatches sends messages over one connection per batch, waiting pause
+// between batches to stay under provider rate limits. A failed recipient is
+// logged and skipped, a failed connection aborts the remaining batches.
+// It returns the number of messages sent.
+func SendInBatches(ctx context.Context, dial DialFunc, messages []*gomail.Message, batchSize int, pause time.Duration) (int, error) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	sent := 0
+	for start := 0; start < len(messages); start += batchSize {
+		if start > 0 {
+			select {
+			case <-ctx.Done():
+				return sent, ctx.Err()
+			case <-time.After(pause):
+			}
+		}
+
+		end := start + batchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+
+		conn, err := dial()
+		if err != nil {
+			return sent, fmt.Errorf("dialing smtp server: %w", err)
+		}
+		for _, m := range messages[start:end] {
+			if err := gomail.Send(conn, m); err != nil {
+				loggers.Error.Printf("sending newsletter to %v: %v", m.GetHeader("To"), err)
+				continue
+			}
+			sent++
+		}
+		conn.Close()
+	}
+	return sent, nil
+}
+
+var confirmationTemplate = texttemplate.Must(texttemplate.New("confirmation").Parse(
+	`Hi{{if .Name}} {{.Name}}{{end}},
+
+Thanks for following Jiating! Please confirm your subscription by opening the link below:
+
+{{.ConfirmURL}}
+
+If you didn't ask to subscribe you can ignore this email.
+`))
+
+var newsletterHTMLTemplate = htmltemplate.Must(htmltemplate.New("newsletter").Parse(
+	`<h1>{{.Title}}</h1>
+<p><strong>{{.Date}}</strong></p>
+<p>{{.Description}}</p>
+<p><a href="{{.URL}}">Read more</a></p>
+`))
+
+var newsletterTextTemplate = texttemplate.Must(texttemplate.New("newsletter").Parse(
+	`{{.Title}}
+{{.Date}}
+
+{{.Description}}
+
+Read more: {{.URL}}
+`))
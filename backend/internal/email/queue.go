@@ -0,0 +1,97 @@
+package email
+
+import (
+	"backend/loggers"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrQueueFull   = errors.New("email queue is full")
+	ErrQueueClosed = errors.New("email queue is closed")
+)
+
+// Job is a unit of background email work, e.g. sending a newsletter
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Queue runs email jobs one at a time in the background so requests don't
+// wait on the SMTP server. Close stops accepting jobs and lets the worker
+// drain what was already queued.
+type Queue struct {
+	jobs chan Job
+	done chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewQueue(size int) *Queue {
+	return &Queue{
+		jobs: make(chan Job, size),
+		done: make(chan struct{}),
+	}
+}
+
+// Enqueue adds a job without blocking
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Len is the number of jobs waiting to run
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Run processes jobs until the queue is closed and drained or ctx is done,
+// jobs still queued when ctx is done are dropped
+func (q *Queue) Run(ctx context.Context) error {
+	defer close(q.done)
+	for {
+		select {
+		case <-ctx.Done():
+			if n := q.Len(); n > 0 {
+				loggers.Error.Printf("email queue stopped with %d jobs left", n)
+			}
+			return ctx.Err()
+		case job, ok := <-q.jobs:
+			if !ok {
+				return nil
+			}
+			if err := job.Run(ctx); err != nil {
+				loggers.Error.Printf("email job %s: %v", job.Name, err)
+			}
+		}
+	}
+}
+
+// Close stops accepting jobs and waits until the queued ones ran or ctx is
+// done. Run must have been started.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
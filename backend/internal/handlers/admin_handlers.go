@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/internal/auth"
 	"backend/internal/database"
 	"backend/internal/models"
 	"backend/loggers"
@@ -18,24 +19,24 @@ import (
 func AdminDashboardHandler() http.HandlerFunc {
 	// TODO: currently unused consider removal
 	return func(w http.ResponseWriter, r *http.Request) {
-		loggers.Debug.Println("Retreiving users from context...")
+		loggers.Debug.Ctx(r.Context()).Println("Retreiving users from context...")
 		user := r.Context().Value("user")
 
 		if user == nil {
-			loggers.Debug.Println("User is not logged in")
+			loggers.Debug.Ctx(r.Context()).Println("User is not logged in")
 			http.Error(w, "You must be logged in to view this page", http.StatusForbidden)
 			return
 		}
-		loggers.Debug.Printf("User: %v\n", user)
+		loggers.Debug.Ctx(r.Context()).Printf("User: %v\n", user)
 	}
 }
 
-func CreateAdminHandler(s database.Service) http.HandlerFunc {
+func CreateAdminHandler(s database.AdminRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var admin models.Admin
 		err := json.NewDecoder(r.Body).Decode(&admin)
 		if err != nil {
-			loggers.Error.Printf("Error decoding json body: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
 			http.Error(w, "Bad Request", http.StatusBadRequest)
 			return
 		}
@@ -49,7 +50,7 @@ func CreateAdminHandler(s database.Service) http.HandlerFunc {
 
 		adminID, err := s.CreateAdmin(ctx, admin)
 		if err != nil {
-			loggers.Error.Printf("creating admin: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("creating admin: %v", err)
 			if errors.Is(err, context.Canceled) {
 				http.Error(w, "Request canceled", http.StatusRequestTimeout)
 				return
@@ -68,7 +69,16 @@ func CreateAdminHandler(s database.Service) http.HandlerFunc {
 	}
 }
 
-func GetAllAdminsHandler(fetchFunc database.AdminFetchFunc, s database.Service) http.HandlerFunc {
+// GetAllAdminsHandler lists a page of the admins filter selects, the total
+// count is of the same admins
+func GetAllAdminsHandler(s database.AdminRepository, filter database.AdminFilter) http.HandlerFunc {
+	fetchFunc := map[database.AdminFilter]database.AdminFetchFunc{
+		database.AdminsActive:        s.GetAllAdmins,
+		database.AdminsWithDeleted:   s.GetAllAdminsIncludingDeleted,
+		database.AdminsDeleted:       s.GetDeletedAdmins,
+		database.AdminsExceptFounder: s.GetAllAdminsExceptFounder,
+	}[filter]
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		pageStr := r.URL.Query().Get("page")
 		pageSizeStr := r.URL.Query().Get("pageSize")
@@ -86,29 +96,26 @@ func GetAllAdminsHandler(fetchFunc database.AdminFetchFunc, s database.Service)
 
 		admins, err := fetchFunc(ctx, page, pageSize)
 		if err != nil {
-			loggers.Error.Printf("reading admins: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("reading admins: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError) // TODO: write more descriptive error
 			return
 		}
 
 		if len(admins) == 0 {
-			loggers.Error.Printf("No admins found")
+			loggers.Error.Ctx(r.Context()).Printf("No admins found")
 			w.WriteHeader(http.StatusNotFound)
 			json.NewEncoder(w).Encode(map[string]string{"message": "No admins found"})
 			return
 		}
 
-		totalCount, err := s.GetAdminCount(r.Context())
+		totalCount, err := s.CountAdmins(ctx, filter)
 		if err != nil {
-			loggers.Error.Printf("getting total admin count: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("getting total admin count: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
 
-		response := struct {
-			Admins     []models.Admin `json:"admins"`
-			TotalCount int            `json:"totalCount"`
-		}{
+		response := models.AdminList{
 			Admins:     admins,
 			TotalCount: totalCount,
 		}
@@ -116,12 +123,42 @@ func GetAllAdminsHandler(fetchFunc database.AdminFetchFunc, s database.Service)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			loggers.Error.Printf("Error writing response: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// ListsDeletedAdmins reports whether r asks ListAdminsHandler for deleted
+// admins, route it through the auth middleware when it does
+func ListsDeletedAdmins(r *http.Request) bool {
+	filter := database.AdminFilter(r.URL.Query().Get("deleted"))
+	return filter != "" && filter != database.AdminsActive
+}
+
+// ListAdminsHandler picks which admins to list from the deleted query param:
+// exclude (default), include or only. Deleted admins are only listed to
+// signed in admins.
+func ListAdminsHandler(s database.AdminRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := database.AdminFilter(r.URL.Query().Get("deleted"))
+		switch filter {
+		case "":
+			filter = database.AdminsActive
+		case database.AdminsActive:
+		case database.AdminsWithDeleted, database.AdminsDeleted:
+			if _, ok := auth.AdminIDFromContext(r.Context()); !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		default:
+			http.Error(w, "invalid deleted filter", http.StatusBadRequest)
+			return
 		}
+		GetAllAdminsHandler(s, filter).ServeHTTP(w, r)
 	}
 }
 
-func GetAdminHandler(s database.Service) http.HandlerFunc {
+func GetAdminHandler(s database.AdminRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		param := chi.URLParam(r, "param") // either ID or email
 		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
@@ -134,7 +171,7 @@ func GetAdminHandler(s database.Service) http.HandlerFunc {
 		admin, err := s.GetAdmin(ctx, fieldName, param)
 
 		if err != nil {
-			loggers.Error.Printf("Error getting admin: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error getting admin: %v", err)
 			var errMsg string
 			if err.Error() == "admin not found" {
 				errMsg = "Admin not found"
@@ -150,19 +187,19 @@ func GetAdminHandler(s database.Service) http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(admin); err != nil {
-			loggers.Error.Printf("Error writing response: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
 		}
 	}
 }
 
-func GetAdminCountHandler(s database.Service) http.HandlerFunc {
+func GetAdminCountHandler(s database.AdminRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 		defer cancel()
 
 		count, err := s.GetAdminCount(ctx)
 		if err != nil {
-			loggers.Error.Printf("getting admin count: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("getting admin count: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
@@ -170,18 +207,18 @@ func GetAdminCountHandler(s database.Service) http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(map[string]int{"count": count}); err != nil {
-			loggers.Error.Printf("Error writing response: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
 		}
 	}
 }
 
-func UpdateAdminHandler(s database.Service) http.HandlerFunc {
+func UpdateAdminHandler(s database.AdminRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// parse admin id from url
 		adminID := chi.URLParam(r, "id")
-		loggers.Debug.Print("adminID: ", adminID)
+		loggers.Debug.Ctx(r.Context()).Print("adminID: ", adminID)
 		if adminID == "" {
-			loggers.Error.Printf("Admin ID not provided")
+			loggers.Error.Ctx(r.Context()).Printf("Admin ID not provided")
 			http.Error(w, "missing admin ID", http.StatusBadRequest)
 			return
 		}
@@ -197,30 +234,86 @@ func UpdateAdminHandler(s database.Service) http.HandlerFunc {
 		// use database service to update admin
 		err = s.UpdateAdmin(r.Context(), admin)
 		if err != nil {
-			if err.Error() == "email already exists" {
-				http.Error(w, err.Error(), http.StatusConflict)
+			loggers.Error.Ctx(r.Context()).Printf("updating admin: %v", err)
+			http.Error(w, err.Error(), determineAdminUpdateStatusCode(err))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Admin updated successfully"})
+	}
+}
+
+// DeleteAdminHandler soft deletes an admin and signs them out everywhere.
+// Admins can't delete themselves, someone else has to.
+// optional query params: reassign_to (id or email of the admin that takes over their events)
+func DeleteAdminHandler(s database.AdminRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param := chi.URLParam(r, "param") // either ID or email
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		fieldName := "email"
+		if isValidUUID(param) {
+			fieldName = "id"
+		}
+
+		if adminID, ok := auth.AdminIDFromContext(r.Context()); ok {
+			self := fieldName == "id" && param == adminID
+			if fieldName == "email" {
+				me, err := s.GetAdmin(ctx, "id", adminID)
+				if err != nil {
+					loggers.Error.Ctx(r.Context()).Printf("getting signed in admin: %v", err)
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+				self = strings.EqualFold(me.Email, param)
+			}
+			if self {
+				http.Error(w, "cannot delete yourself", http.StatusForbidden)
 				return
-			} else {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
+		}
+
+		admin, err := s.DeleteAdmin(ctx, fieldName, param, r.URL.Query().Get("reassign_to"))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("deleting admin: %v", err)
+			http.Error(w, err.Error(), determineAdminDeletionStatusCode(err))
 			return
 		}
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Admin updated successfully"})
+		json.NewEncoder(w).Encode(map[string]string{"message": "Admin deleted successfully", "id": admin.ID})
 	}
 }
 
-func DeleteAdminHandler(s database.Service) http.HandlerFunc {
+func RestoreAdminHandler(s database.AdminRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		//param := chi.URLParam(r, "param") // either ID or email
-		//ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
-		//defer cancel()
+		param := chi.URLParam(r, "param") // either ID or email
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		fieldName := "email"
+		if isValidUUID(param) {
+			fieldName = "id"
+		}
+
+		admin, err := s.RestoreAdmin(ctx, fieldName, param)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("restoring admin: %v", err)
+			http.Error(w, err.Error(), determineAdminDeletionStatusCode(err))
+			return
+		}
 
-		// TODO refactor this to use a single function
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(admin); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
 	}
 }
 
-// func AssociateAdminWithEventHandler(s database.Service) http.HandlerFunc {
+// func AssociateAdminWithEventHandler(s database.AdminRepository) http.HandlerFunc {
 // 	return func(w http.ResponseWriter, r *http.Request) {
 // 		// decode json  into EventAuthor struct
 // 		var eventAuthor models.EventAuthor
@@ -244,53 +337,3 @@ func DeleteAdminHandler(s database.Service) http.HandlerFunc {
 //			w.Write([]byte("Admin associated with event successfully"))
 //		}
 //	}
-
-// func DeleteAdminByIDHandler(s database.Service) http.HandlerFunc {
-// 	return func(w http.ResponseWriter, r *http.Request) {
-// 		// extract admin id from url
-// 		adminID := chi.URLParam(r, "adminID")
-// 		err := s.DeleteAdminByID(adminID)
-// 		if err != nil {
-// 			if err.Error() == "cannot delete a permanent admin" {
-// 				http.Error(w, "Forbidden", http.StatusForbidden)
-// 				return
-// 			}
-
-// 			loggers.Error.Printf("Error deleting admin: %v", err)
-// 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-// 			return
-// 		}
-
-// 		// success response
-// 		w.WriteHeader(http.StatusOK)
-// 		w.Write([]byte("Admin deleted successfully"))
-// 	}
-// }
-
-// func DeleteAdminByEmailHandler(s database.Service) http.HandlerFunc {
-// 	return func(w http.ResponseWriter, r *http.Request) {
-// 		// extract admin email from url and decode
-// 		adminEmail := chi.URLParam(r, "adminEmail")
-// 		decodedEmail, err := url.QueryUnescape(adminEmail)
-// 		if err != nil {
-// 			loggers.Error.Printf("Error decoding admin email: %v", err)
-// 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-// 			return
-// 		}
-// 		err = s.DeleteAdminByEmail(decodedEmail)
-// 		if err != nil {
-// 			if err.Error() == "cannot delete a permanent admin" {
-// 				http.Error(w, "Forbidden", http.StatusForbidden)
-// 				return
-// 			}
-
-// 			loggers.Error.Printf("Error deleting admin: %v", err)
-// 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-// 			return
-// 		}
-
-// 		// success response
-// 		w.WriteHeader(http.StatusOK)
-// 		w.Write([]byte("Admin deleted successfully"))
-// 	}
-// }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/tokens"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultAPITokenLifetimeDays = 90
+
+// sessionAdminID returns the admin of a browser session, tokens can't be
+// used to manage tokens so a leaked token can't mint new ones
+func sessionAdminID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if auth.AuthenticatedByToken(r.Context()) {
+		http.Error(w, "api tokens can't manage api tokens", http.StatusForbidden)
+		return "", false
+	}
+	adminID, ok := auth.AdminIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	return adminID, true
+}
+
+// CreateAPITokenHandler mints a personal access token for the signed in
+// admin, the token is only returned by this response
+func CreateAPITokenHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := sessionAdminID(w, r)
+		if !ok {
+			return
+		}
+
+		var req models.CreateAPITokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		if req.ExpiresInDays == 0 {
+			req.ExpiresInDays = defaultAPITokenLifetimeDays
+		}
+
+		raw, prefix, err := auth.NewAPIToken()
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("generating api token: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		token := models.APIToken{
+			AdminID:   adminID,
+			Name:      req.Name,
+			Prefix:    prefix,
+			Scopes:    req.Scopes,
+			ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CreateAPIToken(ctx, token, tokens.Hash(raw))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating api token: %v", err)
+			http.Error(w, err.Error(), determineAPITokenStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":    "Token created, copy it now as it won't be shown again",
+			"id":         id,
+			"token":      raw,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt,
+		})
+	}
+}
+
+func GetAPITokensHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := sessionAdminID(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		apiTokens, err := s.GetAPITokens(ctx, adminID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading api tokens: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(apiTokens); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func RevokeAPITokenHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := sessionAdminID(w, r)
+		if !ok {
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid api token ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.RevokeAPIToken(ctx, adminID, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("revoking api token: %v", err)
+			http.Error(w, err.Error(), determineAPITokenStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Token revoked successfully"})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/calendar"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// how far back the calendar feeds reach, older performances are dropped from subscriptions
+const calendarFeedLookback = 90 * 24 * time.Hour
+
+func CreatePerformanceHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var performance models.Performance
+		if err := json.NewDecoder(r.Body).Decode(&performance); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CreatePerformance(ctx, performance)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating performance: %v", err)
+			http.Error(w, err.Error(), determinePerformanceStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Performance created successfully", "id": id})
+	}
+}
+
+// optional query params: from, to (RFC3339 or YYYY-MM-DD)
+func GetPerformancesHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, ok := parseDateRange(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		performances, err := s.GetPerformances(ctx, from, to, false)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading performances: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(performances); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func GetPerformanceHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid performance ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		performance, err := s.GetPerformance(ctx, id)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting performance: %v", err)
+			http.Error(w, err.Error(), determinePerformanceStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(performance); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func UpdatePerformanceHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid performance ID", http.StatusBadRequest)
+			return
+		}
+
+		var performance models.Performance
+		if err := json.NewDecoder(r.Body).Decode(&performance); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		performance.ID = id
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.UpdatePerformance(ctx, performance); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("updating performance: %v", err)
+			http.Error(w, err.Error(), determinePerformanceStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Performance updated successfully"})
+	}
+}
+
+func DeletePerformanceHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid performance ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.DeletePerformance(ctx, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("deleting performance: %v", err)
+			http.Error(w, err.Error(), determinePerformanceStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Performance deleted successfully"})
+	}
+}
+
+// ========== iCalendar feeds ========== //
+
+// PublicCalendarFeedHandler serves the public performances as an .ics subscription
+func PublicCalendarFeedHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		performances, err := s.GetPerformances(ctx, time.Now().Add(-calendarFeedLookback), time.Time{}, true)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading public performances: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		writeCalendar(w, "Jiating Performances", performances)
+	}
+}
+
+// PrivateCalendarFeedHandler serves every performance, public or not, to the
+// holder of a feed token issued by CalendarFeedURLHandler. Calendar apps
+// can't send session cookies so the token in the URL is the credential.
+func (deps *HandlerDependencies) PrivateCalendarFeedHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the route is /private/{token} because tokens contain a dot, which
+		// ends a chi parameter, so the .ics extension is stripped here
+		token, ok := strings.CutSuffix(chi.URLParam(r, "token"), ".ics")
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		adminID, err := calendar.ParseFeedToken(deps.Config.CalendarFeedSecret, token)
+		if err != nil {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		// feeds stop working once the admin is removed
+		if _, err := s.GetAdmin(ctx, "id", adminID); err != nil {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		performances, err := s.GetPerformances(ctx, time.Now().Add(-calendarFeedLookback), time.Time{}, false)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading performances: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		writeCalendar(w, "Jiating Performances (Team)", performances)
+	}
+}
+
+// CalendarFeedURLHandler returns the private feed path for the logged in admin
+func (deps *HandlerDependencies) CalendarFeedURLHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		secret := deps.Config.CalendarFeedSecret
+		if secret == "" {
+			loggers.Error.Ctx(r.Context()).Println("CALENDAR_FEED_SECRET is not set, private calendar feed is disabled")
+			http.Error(w, "private calendar feed is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"path": "/api/v1/calendar/private/" + calendar.FeedToken(secret, adminID) + ".ics",
+		})
+	}
+}
+
+func writeCalendar(w http.ResponseWriter, name string, performances []models.Performance) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="jiating.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if err := calendar.WriteICS(w, name, performances); err != nil {
+		loggers.Error.Printf("Error writing calendar: %v", err)
+	}
+}
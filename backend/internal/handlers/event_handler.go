@@ -44,7 +44,7 @@ import (
 // 	}
 // }
 
-func CreateEventHandler(s database.Service) http.HandlerFunc {
+func CreateEventHandler(s database.EventRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -57,7 +57,7 @@ func CreateEventHandler(s database.Service) http.HandlerFunc {
 
 		var req models.CreateEventRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			loggers.Error.Printf("Error decoding request body: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding request body: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
@@ -81,7 +81,7 @@ func CreateEventHandler(s database.Service) http.HandlerFunc {
 		// call database service
 		eventID, err := s.CreateEvent(ctx, newEvent, req.AuthorID) // todo implement
 		if err != nil {
-			loggers.Error.Printf("Error creating event: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error creating event: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
@@ -192,7 +192,7 @@ func CreateEventHandler(s database.Service) http.HandlerFunc {
 // 		event := chi.URLParam(r, "event")
 // 		file := chi.URLParam(r, "file")
 
-// 		url, err := deps.S3Service.GenerateEventImageUploadURL(event, file, 900)
+// 		url, err := deps.S3Service.GenerateEventImageUploadURL(r.Context(), event, file, 900)
 // 		if err != nil {
 // 			loggers.Error.Printf("Error generating upload url: %v", err)
 // 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -212,7 +212,7 @@ func CreateEventHandler(s database.Service) http.HandlerFunc {
 // 		event := chi.URLParam(r, "event")
 // 		file := chi.URLParam(r, "file")
 
-// 		url, err := deps.S3Service.DevGenerateEventImageUploadURL(event, file, 900)
+// 		url, err := deps.S3Service.DevGenerateEventImageUploadURL(r.Context(), event, file, 900)
 // 		if err != nil {
 // 			loggers.Error.Printf("Error generating upload url: %v", err)
 // 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
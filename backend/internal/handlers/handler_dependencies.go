@@ -1,7 +1,15 @@
 package handlers
 
-import "backend/internal/s3service"
+import (
+	"backend/internal/config"
+	"backend/internal/email"
+	"backend/internal/s3service"
+)
 
 type HandlerDependencies struct {
+	Config    *config.Config
 	S3Service s3service.Service
+	Email     email.Service
+	// EmailQueue runs slow email work like newsletters in the background
+	EmailQueue *email.Queue
 }
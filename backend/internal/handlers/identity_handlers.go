@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMyIdentitiesHandler lists the login accounts linked to the signed in admin
+func GetMyIdentitiesHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		identities, err := s.GetAdminIdentities(ctx, adminID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading admin identities: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(identities); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// UnlinkMyIdentityHandler removes a login account from the signed in admin
+func UnlinkMyIdentityHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		err := s.UnlinkAdminIdentity(ctx, adminID, chi.URLParam(r, "provider"), chi.URLParam(r, "providerUserID"))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("unlinking admin identity: %v", err)
+			http.Error(w, err.Error(), determineIdentityStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Identity unlinked successfully"})
+	}
+}
@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/s3service"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func CreateInventoryItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var item models.InventoryItem
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CreateInventoryItem(ctx, item)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Inventory item created successfully", "id": id})
+	}
+}
+
+// optional query params: category
+func GetInventoryItemsHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		items, err := s.GetInventoryItems(ctx, r.URL.Query().Get("category"))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading inventory items: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(items); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func GetInventoryItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		item, err := s.GetInventoryItem(ctx, id)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(item); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func UpdateInventoryItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		var item models.InventoryItem
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		item.ID = id
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.UpdateInventoryItem(ctx, item); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("updating inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Inventory item updated successfully"})
+	}
+}
+
+func DeleteInventoryItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.DeleteInventoryItem(ctx, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("deleting inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Inventory item deleted successfully"})
+	}
+}
+
+// ========== check out / check in ========== //
+
+// CheckOutItemHandler lends an item out for a performance and/or to a member,
+// the logged in admin is recorded as the lender
+func CheckOutItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var checkout models.ItemCheckout
+		if err := json.NewDecoder(r.Body).Decode(&checkout); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		checkout.ItemID = itemID
+		checkout.CheckedOutBy = adminID
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CheckOutItem(ctx, checkout)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("checking out item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Item checked out successfully", "id": id})
+	}
+}
+
+func CheckInItemHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		var req models.CheckInRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.CheckInItem(ctx, itemID, req); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("checking in item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Item checked in successfully"})
+	}
+}
+
+func GetItemCheckoutsHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		checkouts, err := s.GetItemCheckouts(ctx, itemID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading item checkouts: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(checkouts); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// GetOverdueCheckoutsHandler lists items that are still out past their due date
+func GetOverdueCheckoutsHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		checkouts, err := s.GetOverdueCheckouts(ctx, time.Now())
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading overdue checkouts: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(checkouts); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// ========== photos ========== //
+
+// GetInventoryPhotoUploadURLHandler returns a presigned url to PUT the item
+// photo to. The item keeps its photo until ConfirmInventoryPhotoHandler is
+// called after the upload, so abandoned uploads aren't referenced.
+// required query params: file
+func (deps *HandlerDependencies) GetInventoryPhotoUploadURLHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+		filename, ok := inventoryPhotoFilename(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if _, err := s.GetInventoryItem(ctx, itemID); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		url, err := deps.S3Service.GenerateInventoryPhotoUploadURL(r.Context(), itemID, filename, 900)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error generating upload url: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"url": url, "key": s3service.InventoryPhotoKey(itemID, filename)})
+	}
+}
+
+// ConfirmInventoryPhotoHandler records the photo uploaded to the url of
+// GetInventoryPhotoUploadURLHandler on the item, once it is in the bucket.
+// required query params: file
+func (deps *HandlerDependencies) ConfirmInventoryPhotoHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+		filename, ok := inventoryPhotoFilename(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		item, err := s.GetInventoryItem(ctx, itemID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		key := s3service.InventoryPhotoKey(itemID, filename)
+		exists, err := deps.S3Service.InventoryPhotoExists(ctx, key)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error checking photo upload: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			http.Error(w, "photo has not been uploaded", http.StatusConflict)
+			return
+		}
+
+		item.PhotoKey = key
+		if err := s.UpdateInventoryItem(ctx, *item); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("updating inventory item photo: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(item); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// inventoryPhotoFilename reads the file query param, writing a 400 on failure
+func inventoryPhotoFilename(w http.ResponseWriter, r *http.Request) (string, bool) {
+	filename := path.Base(r.URL.Query().Get("file"))
+	if filename == "." || filename == "/" || strings.HasPrefix(filename, "..") {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return "", false
+	}
+	return filename, true
+}
+
+// GetInventoryPhotoHandler redirects to a short lived presigned url of the item photo
+func (deps *HandlerDependencies) GetInventoryPhotoHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID := chi.URLParam(r, "id")
+		if !isValidUUID(itemID) {
+			http.Error(w, "invalid inventory item ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		item, err := s.GetInventoryItem(ctx, itemID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting inventory item: %v", err)
+			http.Error(w, err.Error(), determineInventoryStatusCode(err))
+			return
+		}
+		if item.PhotoKey == "" {
+			http.Error(w, "item has no photo", http.StatusNotFound)
+			return
+		}
+
+		url, err := deps.S3Service.GetInventoryPhotoURL(r.Context(), item.PhotoKey, 900)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error generating photo url: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, url, http.StatusFound)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/tokens"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const invitationLifetime = 7 * 24 * time.Hour
+
+// CreateAdminInvitationHandler invites a new admin by email, the logged in
+// founder is recorded as the inviter
+func (deps *HandlerDependencies) CreateAdminInvitationHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var invitation models.AdminInvitation
+		if err := json.NewDecoder(r.Body).Decode(&invitation); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		invitation.InvitedBy = adminID
+		invitation.ExpiresAt = time.Now().Add(invitationLifetime)
+
+		token, err := auth.NewInvitationToken(deps.Config.Auth.InvitationSecret)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("generating invitation token: %v", err)
+			http.Error(w, "invitations are not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		inviter, err := s.GetAdmin(ctx, "id", adminID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting inviting admin: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		id, err := s.CreateAdminInvitation(ctx, invitation, tokens.Hash(token))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating admin invitation: %v", err)
+			http.Error(w, err.Error(), determineInvitationStatusCode(err))
+			return
+		}
+
+		acceptURL := deps.Config.PublicBaseURL + "/auth/invitations/accept?token=" + url.QueryEscape(token)
+		if err := deps.Email.SendAdminInvitation(invitation.Email, invitation.Name, inviter.Name,
+			acceptURL, acceptURL+"&bind=true", invitation.ExpiresAt); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("sending invitation email: %v", err)
+			// an invitation nobody received can't be accepted, don't leave it pending
+			if err := s.RevokeAdminInvitation(ctx, id); err != nil {
+				loggers.Error.Ctx(r.Context()).Printf("revoking unsent invitation: %v", err)
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invitation sent successfully", "id": id})
+	}
+}
+
+// optional query params: status (pending, accepted, expired, revoked)
+func GetAdminInvitationsHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		invitations, err := s.GetAdminInvitations(ctx, r.URL.Query().Get("status"))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading admin invitations: %v", err)
+			http.Error(w, err.Error(), determineInvitationStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(invitations); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func RevokeAdminInvitationHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid invitation ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.RevokeAdminInvitation(ctx, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("revoking admin invitation: %v", err)
+			http.Error(w, err.Error(), determineInvitationStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invitation revoked successfully"})
+	}
+}
@@ -13,7 +13,7 @@ func (deps *HandlerDependencies) GetPhotoshootYearsHandler() http.HandlerFunc {
 		years, err := deps.S3Service.GetPhotoshootYears(r.Context())
 		if err != nil {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			loggers.Error.Printf("Error getting years: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error getting years: %v", err)
 			return
 		}
 
@@ -25,11 +25,11 @@ func (deps *HandlerDependencies) GetPhotoshootYearsHandler() http.HandlerFunc {
 func (deps *HandlerDependencies) GetPhotoshootEventsHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		year := chi.URLParam(r, "year")
-		loggers.Debug.Printf("year: %v", year)
+		loggers.Debug.Ctx(r.Context()).Printf("year: %v", year)
 		events, err := deps.S3Service.GetPhotoshootEvents(r.Context(), year)
 		if err != nil {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			loggers.Error.Printf("Error getting events: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error getting events: %v", err)
 			return
 		}
 
@@ -42,11 +42,11 @@ func (deps *HandlerDependencies) ListPhotoshootPhotosHandler() http.HandlerFunc
 	return func(w http.ResponseWriter, r *http.Request) {
 		year := chi.URLParam(r, "year")
 		event := chi.URLParam(r, "event")
-		loggers.Debug.Printf("year: %v, event: %v", year, event)
+		loggers.Debug.Ctx(r.Context()).Printf("year: %v, event: %v", year, event)
 		photos, err := deps.S3Service.ListPhotoshootPhotos(r.Context(), year, event)
 		if err != nil {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			loggers.Error.Printf("Error getting photos: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error getting photos: %v", err)
 			return
 		}
 
@@ -59,11 +59,11 @@ func (deps *HandlerDependencies) GetPhotshootPhotosHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		year := chi.URLParam(r, "year")
 		event := chi.URLParam(r, "event")
-		loggers.Debug.Printf("year: %v, event: %v", year, event)
+		loggers.Debug.Ctx(r.Context()).Printf("year: %v, event: %v", year, event)
 		photos, err := deps.S3Service.GetPhotoshootPhotos(r.Context(), year, event)
 		if err != nil {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			loggers.Error.Printf("Error getting photos: %v", err)
+			loggers.Error.Ctx(r.Context()).Printf("Error getting photos: %v", err)
 			return
 		}
 
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// publicMember hides account details from the public team page
+func publicMember(m models.Member) models.Member {
+	m.AdminID = nil
+	return m
+}
+
+// GetTeamHandler lists active members for the public team page
+func GetTeamHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		members, err := s.GetMembers(ctx, true)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading members: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		for i := range members {
+			members[i] = publicMember(members[i])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(members); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// GetTeamMemberHandler returns a single active member for the public team page
+func GetTeamMemberHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid member ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		member, err := s.GetMember(ctx, id)
+		if err == nil && !member.IsActive {
+			http.Error(w, "member not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting member: %v", err)
+			http.Error(w, err.Error(), determineMemberStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(publicMember(*member)); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+// GetAllMembersHandler lists the full roster, including inactive members and admin links
+func GetAllMembersHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		members, err := s.GetMembers(ctx, false)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading members: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(members); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func CreateMemberHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var member models.Member
+		if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CreateMember(ctx, member)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating member: %v", err)
+			http.Error(w, err.Error(), determineMemberStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Member created successfully", "id": id})
+	}
+}
+
+func UpdateMemberHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid member ID", http.StatusBadRequest)
+			return
+		}
+
+		var member models.Member
+		if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		member.ID = id
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.UpdateMember(ctx, member); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("updating member: %v", err)
+			http.Error(w, err.Error(), determineMemberStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Member updated successfully"})
+	}
+}
+
+func DeleteMemberHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid member ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.DeleteMember(ctx, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("deleting member: %v", err)
+			http.Error(w, err.Error(), determineMemberStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Member deleted successfully"})
+	}
+}
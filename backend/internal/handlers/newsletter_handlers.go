@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"backend/internal/database"
+	"backend/internal/email"
+	"backend/internal/models"
+	"backend/internal/tokens"
+	"backend/loggers"
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	confirmTokenLifetime = 48 * time.Hour
+	newsletterBatchSize  = 25
+	newsletterBatchPause = 30 * time.Second
+	unsubscribePrefix    = "unsubscribe:"
+)
+
+// unsubscribeURL returns the signed one-click unsubscribe link of an email,
+// links don't expire so old newsletters keep working
+func (deps *HandlerDependencies) unsubscribeURL(address string) string {
+	token := tokens.Sign(deps.Config.NewsletterSecret, unsubscribePrefix+strings.ToLower(address))
+	return deps.Config.PublicBaseURL + "/api/v1/newsletter/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// SubscribeHandler starts the double opt-in, the same response is returned
+// whether or not the email was already subscribed
+func (deps *HandlerDependencies) SubscribeHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.SubscribeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		token, err := tokens.Random(32)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("generating confirmation token: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		alreadyConfirmed, err := s.CreatePendingSubscriber(ctx, req.Email, req.Name, tokens.Hash(token), time.Now().Add(confirmTokenLifetime))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating subscriber: %v", err)
+			http.Error(w, err.Error(), determineEmailStatusCode(err))
+			return
+		}
+
+		if !alreadyConfirmed {
+			confirmURL := deps.Config.PublicBaseURL + "/api/v1/newsletter/confirm?token=" + url.QueryEscape(token)
+			if err := deps.Email.SendSubscriptionConfirmation(strings.ToLower(strings.TrimSpace(req.Email)), req.Name, confirmURL); err != nil {
+				loggers.Error.Ctx(r.Context()).Printf("sending confirmation email: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Check your email to confirm your subscription"})
+	}
+}
+
+// ConfirmSubscriptionHandler is the link in the opt-in email, it redirects to the site
+func (deps *HandlerDependencies) ConfirmSubscriptionHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Redirect(w, r, deps.Config.FrontendURL+"/newsletter/invalid", http.StatusSeeOther)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.ConfirmSubscriber(ctx, tokens.Hash(token)); err != nil {
+			loggers.Debug.Ctx(r.Context()).Printf("confirming subscriber: %v", err)
+			http.Redirect(w, r, deps.Config.FrontendURL+"/newsletter/invalid", http.StatusSeeOther)
+			return
+		}
+
+		http.Redirect(w, r, deps.Config.FrontendURL+"/newsletter/confirmed", http.StatusSeeOther)
+	}
+}
+
+// unsubscribeAddress returns the email of the unsubscribe token in the query,
+// writing a 400 on failure
+func (deps *HandlerDependencies) unsubscribeAddress(w http.ResponseWriter, r *http.Request) (string, bool) {
+	payload, err := tokens.Verify(deps.Config.NewsletterSecret, r.URL.Query().Get("token"))
+	address, ok := strings.CutPrefix(payload, unsubscribePrefix)
+	if err != nil || !ok {
+		http.Error(w, "invalid unsubscribe link", http.StatusBadRequest)
+		return "", false
+	}
+	return address, true
+}
+
+// UnsubscribePageHandler is the link in newsletters. It only asks for
+// confirmation: mail scanners and link previews fetch links, so a GET
+// mustn't unsubscribe anyone.
+func (deps *HandlerDependencies) UnsubscribePageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address, ok := deps.unsubscribeAddress(w, r)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		unsubscribeTemplate.Execute(w, map[string]string{"Email": address, "Token": r.URL.Query().Get("token")})
+	}
+}
+
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(
+	`<!DOCTYPE html>
+<title>Unsubscribe</title>
+<h1>Unsubscribe from the Jiating newsletter?</h1>
+<p>{{.Email}} will no longer receive event newsletters.</p>
+<form method="post" action="?token={{.Token}}">
+<input type="hidden" name="from" value="page">
+<button type="submit">Unsubscribe</button>
+</form>
+`))
+
+// UnsubscribeHandler unsubscribes for the button of UnsubscribePageHandler,
+// which is redirected to the site, and for one-click unsubscribe requests
+// from mail clients (RFC 8058), which get JSON
+func (deps *HandlerDependencies) UnsubscribeHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address, ok := deps.unsubscribeAddress(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.UnsubscribeSubscriber(ctx, address); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("unsubscribing: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if r.PostFormValue("from") == "page" {
+			http.Redirect(w, r, deps.Config.FrontendURL+"/newsletter/unsubscribed", http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Unsubscribed successfully"})
+	}
+}
+
+// SendEventNewsletterHandler mails a summary of a published event to every
+// confirmed subscriber. Sending happens on the email queue in throttled
+// batches, the response only reports how many recipients were queued.
+func (deps *HandlerDependencies) SendEventNewsletterHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID := chi.URLParam(r, "id")
+		if !isValidUUID(eventID) {
+			http.Error(w, "invalid event ID", http.StatusBadRequest)
+			return
+		}
+		if deps.Config.NewsletterSecret == "" {
+			loggers.Error.Ctx(r.Context()).Println("NEWSLETTER_SECRET is not set, newsletters are disabled")
+			http.Error(w, "newsletter is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		event, err := s.GetEventByID(ctx, eventID)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting event: %v", err)
+			if err.Error() == "event not found" {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if event.IsDraft {
+			http.Error(w, "cannot send a newsletter for a draft event", http.StatusConflict)
+			return
+		}
+
+		subscribers, err := s.GetConfirmedSubscribers(ctx)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting subscribers: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		newsletter, err := email.RenderEventNewsletter(*event, deps.Config.FrontendURL+"/events/"+url.PathEscape(event.Slug))
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("rendering newsletter: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		recipients := make([]email.NewsletterRecipient, 0, len(subscribers))
+		for _, sub := range subscribers {
+			recipients = append(recipients, email.NewsletterRecipient{
+				Email:          sub.Email,
+				UnsubscribeURL: deps.unsubscribeURL(sub.Email),
+			})
+		}
+
+		// sent by the email queue worker so sending outlives the response
+		err = deps.EmailQueue.Enqueue(email.Job{
+			Name: "newsletter for event " + eventID,
+			Run: func(ctx context.Context) error {
+				sent, err := deps.Email.SendNewsletter(ctx, newsletter, recipients, newsletterBatchSize, newsletterBatchPause)
+				loggers.Info.Ctx(ctx).Printf("newsletter for event %s sent to %d/%d subscribers", eventID, sent, len(recipients))
+				return err
+			},
+		})
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("queueing newsletter for event %s: %v", eventID, err)
+			http.Error(w, "newsletter could not be queued, try again later", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":    "Newsletter queued",
+			"recipients": len(recipients),
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/loggers"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func CreatePracticeSessionHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var session models.PracticeSession
+		if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error decoding json body: %v", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		id, err := s.CreatePracticeSession(ctx, session)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("creating practice session: %v", err)
+			http.Error(w, err.Error(), determinePracticeStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Practice session created successfully", "id": id})
+	}
+}
+
+// optional query params: from, to (RFC3339 or YYYY-MM-DD)
+func GetPracticeSessionsHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, ok := parseDateRange(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		sessions, err := s.GetPracticeSessions(ctx, from, to)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading practice sessions: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(sessions); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func GetPracticeSessionHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid practice session ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		session, err := s.GetPracticeSession(ctx, id)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("getting practice session: %v", err)
+			http.Error(w, err.Error(), determinePracticeStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(session); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func UpdatePracticeSessionHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid practice session ID", http.StatusBadRequest)
+			return
+		}
+
+		var session models.PracticeSession
+		if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		session.ID = id
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.UpdatePracticeSession(ctx, session); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("updating practice session: %v", err)
+			http.Error(w, err.Error(), determinePracticeStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Practice session updated successfully"})
+	}
+}
+
+func DeletePracticeSessionHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !isValidUUID(id) {
+			http.Error(w, "invalid practice session ID", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.DeletePracticeSession(ctx, id); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("deleting practice session: %v", err)
+			http.Error(w, err.Error(), determinePracticeStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Practice session deleted successfully"})
+	}
+}
+
+// RecordAttendanceHandler accepts a list of {member_id, status} marks for a
+// session, the logged in admin is recorded as the author of the marks
+func RecordAttendanceHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "id")
+		if !isValidUUID(sessionID) {
+			http.Error(w, "invalid practice session ID", http.StatusBadRequest)
+			return
+		}
+
+		adminID, ok := auth.AdminIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var records []models.AttendanceRecord
+		if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		if err := s.RecordAttendance(ctx, sessionID, adminID, records); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("recording attendance: %v", err)
+			http.Error(w, err.Error(), determinePracticeStatusCode(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Attendance recorded successfully"})
+	}
+}
+
+// GetAttendanceReportHandler reports attendance per member over a date range.
+// optional query params: from, to (RFC3339 or YYYY-MM-DD), format (json or csv)
+func GetAttendanceReportHandler(s database.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, ok := parseDateRange(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		report, err := s.GetAttendanceReport(ctx, from, to)
+		if err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("reading attendance report: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="attendance.csv"`)
+			w.WriteHeader(http.StatusOK)
+			if err := writeAttendanceCSV(w, report); err != nil {
+				loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			loggers.Error.Ctx(r.Context()).Printf("Error writing response: %v", err)
+		}
+	}
+}
+
+func writeAttendanceCSV(w http.ResponseWriter, report []models.AttendanceSummary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"member_id", "name", "present", "absent", "excused", "percentage"})
+	for _, row := range report {
+		cw.Write([]string{
+			row.MemberID, csvText(row.Name),
+			strconv.Itoa(row.Present), strconv.Itoa(row.Absent), strconv.Itoa(row.Excused),
+			strconv.FormatFloat(row.Percentage, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText turns a stored text value into a CSV cell. Names are stored HTML
+// escaped, and spreadsheets run cells starting with a formula character, so
+// those are quoted with a leading ' as OWASP recommends.
+func csvText(value string) string {
+	value = html.UnescapeString(value)
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// parseDateRange reads the from and to query params, writing a 400 on failure
+func parseDateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	from, err := parseDateParam(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from date", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := parseDateParam(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to date", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
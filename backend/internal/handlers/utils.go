@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,17 @@ func isValidUUID(u string) bool {
 	return err == nil
 }
 
+// parseDateParam accepts an RFC3339 timestamp or a YYYY-MM-DD date, empty means unset
+func parseDateParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
 // status codes
 
 func determineEmailStatusCode(err error) int {
@@ -24,3 +36,115 @@ func determineEmailStatusCode(err error) int {
 		return http.StatusInternalServerError
 	}
 }
+
+func determinePerformanceStatusCode(err error) int {
+	switch err.Error() {
+	case "missing title", "input too long", "invalid performance time range", "invalid member id":
+		return http.StatusBadRequest
+	case "performance not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineMemberStatusCode(err error) int {
+	switch err.Error() {
+	case "missing name", "input too long", "invalid member role", "invalid admin id":
+		return http.StatusBadRequest
+	case "admin already linked to a member", "member is still referenced":
+		return http.StatusConflict
+	case "member not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determinePracticeStatusCode(err error) int {
+	switch err.Error() {
+	case "missing date", "input too long", "invalid attendance status", "invalid session, member or admin id":
+		return http.StatusBadRequest
+	case "practice session not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineInventoryStatusCode(err error) int {
+	switch err.Error() {
+	case "missing name", "input too long", "invalid item category", "invalid item condition",
+		"checkout requires a performance or member", "invalid due date", "invalid performance, member or admin id":
+		return http.StatusBadRequest
+	case "item is already checked out", "item is not checked out":
+		return http.StatusConflict
+	case "inventory item not found", "item not found or retired":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineInvitationStatusCode(err error) int {
+	switch err.Error() {
+	case "invalid email format", "missing name", "input too long", "invalid invitation status", "invalid admin id":
+		return http.StatusBadRequest
+	case "email already exists":
+		return http.StatusConflict
+	case "invitation not found or no longer pending":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineAdminUpdateStatusCode(err error) int {
+	switch err.Error() {
+	case "invalid email format", "input too long", "invalid admin status":
+		return http.StatusBadRequest
+	case "cannot change a permanent admin":
+		return http.StatusForbidden
+	case "email already exists":
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineAdminDeletionStatusCode(err error) int {
+	switch err.Error() {
+	case "invalid reassignment admin", "invalid admin field":
+		return http.StatusBadRequest
+	case "cannot delete a permanent admin":
+		return http.StatusForbidden
+	case "admin not found", "deleted admin not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineIdentityStatusCode(err error) int {
+	switch err.Error() {
+	case "cannot unlink the last identity":
+		return http.StatusConflict
+	case "identity not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func determineAPITokenStatusCode(err error) int {
+	switch err.Error() {
+	case "missing name", "input too long", "missing scopes", "invalid scope", "invalid expiration":
+		return http.StatusBadRequest
+	case "api token not found":
+		return http.StatusNotFound
+	case "invalid admin id": // deleted since signing in
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
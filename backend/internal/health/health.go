@@ -0,0 +1,180 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status of a single check or of the whole report
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDegraded Status = "degraded" // an optional dependency is down
+)
+
+// CheckFunc probes a dependency, details are reported as is (pool stats, versions)
+type CheckFunc func(ctx context.Context) (details map[string]interface{}, err error)
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    Status                 `json:"status"`
+	Critical  bool                   `json:"critical"`
+	LatencyMs float64                `json:"latencyMs"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Status    Status            `json:"status"`
+	CheckedAt time.Time         `json:"checkedAt"`
+	Checks    map[string]Result `json:"checks,omitempty"`
+}
+
+// Summary leaves out the checks, their errors and details describe the
+// infrastructure and are only for operators
+func (r Report) Summary() Report {
+	return Report{Status: r.Status, CheckedAt: r.CheckedAt}
+}
+
+// With returns a copy of the report that includes result under name, its
+// status counts towards the overall one like a registered check would
+func (r Report) With(name string, result Result) Report {
+	checks := make(map[string]Result, len(r.Checks)+1)
+	for n, res := range r.Checks {
+		checks[n] = res
+	}
+	checks[name] = result
+	r.Checks = checks
+
+	if result.Status == StatusDown {
+		if result.Critical {
+			r.Status = StatusDown
+		} else if r.Status == StatusUp {
+			r.Status = StatusDegraded
+		}
+	}
+	return r
+}
+
+// Checker runs the registered dependency checks concurrently, each bounded
+// by timeout. A failing critical check makes the report down, a failing
+// optional one only degraded.
+type Checker struct {
+	timeout time.Duration
+	checks  []check
+
+	// see CacheFor
+	ttl    time.Duration
+	mu     sync.Mutex
+	cached *Report
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Critical registers a dependency the app can't serve requests without
+func (c *Checker) Critical(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, critical: true, fn: fn})
+}
+
+// Optional registers a dependency only some features need
+func (c *Checker) Optional(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// CacheFor makes Run return the last report while it is younger than ttl,
+// so however often probes come, dependencies are checked once per ttl
+func (c *Checker) CacheFor(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+func (c *Checker) Run(ctx context.Context) Report {
+	if c.ttl <= 0 {
+		return c.runAll(ctx)
+	}
+
+	// concurrent callers wait for the run in progress instead of starting their own
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached == nil || time.Since(c.cached.CheckedAt) >= c.ttl {
+		report := c.runAll(ctx)
+		c.cached = &report
+	}
+	return *c.cached
+}
+
+func (c *Checker) runAll(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]Result, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			result := c.run(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[chk.name] = result
+			if result.Status == StatusDown {
+				if chk.critical {
+					report.Status = StatusDown
+				} else if report.Status == StatusUp {
+					report.Status = StatusDegraded
+				}
+			}
+		}(chk)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, chk check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := chk.fn(ctx)
+	result := Result{
+		Status:    StatusUp,
+		Critical:  chk.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// WriteReport responds 503 when the report is down, 200 otherwise
+func WriteReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,152 @@
+package lifecycle
+
+import (
+	"backend/loggers"
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Manager owns the background workers of the process and shuts everything
+// down in order when SIGINT/SIGTERM arrives or a worker fails.
+//
+// The process is ready once MarkReady is called, after the HTTP listener is
+// bound. Shutdown marks it not ready and waits the drain delay, so load
+// balancers polling readiness stop sending traffic before the listener
+// closes. It then runs the shutdown hooks in reverse registration order
+// (register the DB first and the HTTP server last so requests drain before
+// the pool closes) and finally cancels the context of workers that are still
+// running.
+type Manager struct {
+	ready      atomic.Bool
+	drainDelay time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	failed chan error
+
+	mu    sync.Mutex
+	hooks []hook
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+		failed: make(chan error, 1),
+	}
+}
+
+// Go runs a background worker until its context is canceled. A worker
+// returning an error shuts the whole process down.
+func (m *Manager) Go(name string, run func(ctx context.Context) error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := run(m.ctx); err != nil && !errors.Is(err, context.Canceled) {
+			select {
+			case m.failed <- fmt.Errorf("%s: %w", name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// OnShutdown registers a hook run during shutdown, hooks run in reverse order
+// of registration and share the shutdown timeout
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// SetDrainDelay sets how long Shutdown keeps serving after reporting not
+// ready, it should cover a few readiness polls of the load balancer
+func (m *Manager) SetDrainDelay(d time.Duration) {
+	m.drainDelay = d
+}
+
+// MarkReady reports the process ready, call it once the listener is bound
+func (m *Manager) MarkReady() {
+	m.ready.Store(true)
+	loggers.Info.Println("Ready to handle requests")
+}
+
+// Ready reports whether the process should receive traffic
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Run blocks until a signal arrives or a worker fails, then shuts down within
+// timeout. It returns the worker failure, if any.
+func (m *Manager) Run(timeout time.Duration) error {
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var cause error
+	select {
+	case <-signals.Done():
+		loggers.Info.Println("Shutdown signal received")
+	case cause = <-m.failed:
+		loggers.Error.Printf("Shutting down after failure: %v", cause)
+	}
+	// a second signal kills the process right away
+	stop()
+
+	if err := m.Shutdown(timeout); err != nil {
+		loggers.Error.Printf("Shutdown incomplete: %v", err)
+	}
+	return cause
+}
+
+// Shutdown stops the process as described on Manager
+func (m *Manager) Shutdown(timeout time.Duration) error {
+	m.ready.Store(false)
+	if m.drainDelay > 0 {
+		loggers.Info.Printf("Draining for %s before stopping...", m.drainDelay)
+		time.Sleep(m.drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		loggers.Info.Printf("Stopping %s...", hooks[i].name)
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+		}
+	}
+
+	// workers still running past their hooks are stopped hard
+	m.cancel()
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("workers did not stop before the timeout"))
+	}
+
+	return errors.Join(errs...)
+}
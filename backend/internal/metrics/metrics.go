@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "jiating"
+
+// Registry holds every metric of the app, served by Handler
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	s3Duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "s3_request_duration_seconds",
+		Help:      "Latency of s3service calls by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	s3Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_errors_total",
+		Help:      "Failed s3service calls by method.",
+	}, []string{"method"})
+
+	rateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by rate limiting by chi route pattern.",
+	}, []string{"route"})
+
+	deprecatedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deprecated_requests_total",
+		Help:      "Requests to deprecated routes by chi route pattern.",
+	}, []string{"route"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, s3Duration, s3Errors, rateLimitRejections,
+		deprecatedRequests,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RoutePattern is the chi pattern a request matched, e.g. /api/events/{id}.
+// Patterns keep label cardinality bounded, raw paths would not.
+func RoutePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// Middleware records the count and latency of every request, it must be
+// registered on the root router so the route pattern is complete once the
+// request was served
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := RoutePattern(r)
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveS3 records one s3service call
+func ObserveS3(method string, start time.Time, err error) {
+	s3Duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		s3Errors.WithLabelValues(method).Inc()
+	}
+}
+
+// RateLimitRejected counts a request turned away by rate limiting
+func RateLimitRejected(r *http.Request) {
+	rateLimitRejections.WithLabelValues(RoutePattern(r)).Inc()
+}
+
+// DeprecatedRequest counts a request to a deprecated route
+func DeprecatedRequest(r *http.Request) {
+	deprecatedRequests.WithLabelValues(RoutePattern(r)).Inc()
+}
+
+// RegisterDB exports the connection pool stats of db
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterEmailQueue exports the number of email jobs waiting to run
+func RegisterEmailQueue(depth func() int) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "email_queue_depth",
+		Help:      "Email jobs waiting to run.",
+	}, func() float64 { return float64(depth()) }))
+}
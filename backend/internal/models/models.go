@@ -60,6 +60,141 @@ type EventImage struct {
 	IsDisplay bool      `json:"is_display"` // if the image is the display image for the event, only one image can be display
 }
 
+type Performance struct {
+	ID          string    `json:"id"`          // primary key, UUID
+	CreatedAt   time.Time `json:"created_at"`  // time of creation
+	UpdatedAt   time.Time `json:"updated_at"`  // time of last update
+	Title       string    `json:"title"`       // title of the performance, e.g. "CNY Parade"
+	Description string    `json:"description"` // notes for the team or the public
+	Location    string    `json:"location"`    // venue or address
+	StartTime   time.Time `json:"start_time"`  // ISO8601
+	EndTime     time.Time `json:"end_time"`    // ISO8601, must be after start_time
+	IsPublic    bool      `json:"is_public"`   // public performances are published in the public calendar feed
+	MemberIDs   []string  `json:"member_ids"`  // ids of the members assigned to the performance
+}
+
+// Member is someone on the team, independent of whether they can log in
+type Member struct {
+	ID          string    `json:"id"`                 // primary key, UUID
+	CreatedAt   time.Time `json:"created_at"`         // time of creation
+	UpdatedAt   time.Time `json:"updated_at"`         // time of last update
+	Name        string    `json:"name"`               // name of the member
+	ChineseName string    `json:"chinese_name"`       // optional
+	Role        string    `json:"role"`               // head, tail, drummer, cymbals, gong
+	JoinDate    time.Time `json:"join_date"`          // date the member joined the team
+	IsActive    bool      `json:"is_active"`          // inactive members are hidden from the team page
+	PhotoURL    string    `json:"photo_url"`          // url of the member photo (s3)
+	Bio         string    `json:"bio"`                // short bio for the team page
+	AdminID     *string   `json:"admin_id,omitempty"` // optional link to the admin account of this member
+}
+
+type PracticeSession struct {
+	ID         string             `json:"id"`                   // primary key, UUID
+	CreatedAt  time.Time          `json:"created_at"`           // time of creation
+	UpdatedAt  time.Time          `json:"updated_at"`           // time of last update
+	Date       time.Time          `json:"date"`                 // ISO8601, when the practice starts
+	Location   string             `json:"location"`             // where the practice is held
+	Notes      string             `json:"notes"`                // optional notes, e.g. what was practiced
+	Attendance []AttendanceRecord `json:"attendance,omitempty"` // attendance marks, only populated for a single session
+}
+
+type AttendanceRecord struct {
+	SessionID  string    `json:"session_id"`  // foreign key to practice session
+	MemberID   string    `json:"member_id"`   // foreign key to member
+	Status     string    `json:"status"`      // present, absent, excused
+	RecordedBy string    `json:"recorded_by"` // foreign key to the admin who recorded the mark
+	RecordedAt time.Time `json:"recorded_at"` // time the mark was last recorded
+}
+
+// AttendanceSummary is a row of the attendance report for one member over a date range
+type AttendanceSummary struct {
+	MemberID   string  `json:"member_id"`
+	Name       string  `json:"name"`
+	Present    int     `json:"present"`
+	Absent     int     `json:"absent"`
+	Excused    int     `json:"excused"`
+	Percentage float64 `json:"percentage"` // present / (present + absent) * 100, excused sessions don't count against a member
+}
+
+type InventoryItem struct {
+	ID             string    `json:"id"`              // primary key, UUID
+	CreatedAt      time.Time `json:"created_at"`      // time of creation
+	UpdatedAt      time.Time `json:"updated_at"`      // time of last update
+	Name           string    `json:"name"`            // e.g. "Red Foshan head #2"
+	Category       string    `json:"category"`        // lion_head, drum, cymbals, gong, costume, other
+	Condition      string    `json:"condition"`       // good, fair, needs_repair, retired
+	ConditionNotes string    `json:"condition_notes"` // free text notes on damage, repairs, etc.
+	PhotoKey       string    `json:"photo_key"`       // s3 object key of the item photo, uploaded with a presigned url
+	CheckedOut     bool      `json:"checked_out"`     // read only, true if the item has an open checkout
+}
+
+// ItemCheckout records an item lent out for a performance and/or to a member
+type ItemCheckout struct {
+	ID            string     `json:"id"`                       // primary key, UUID
+	ItemID        string     `json:"item_id"`                  // foreign key to inventory item
+	PerformanceID *string    `json:"performance_id,omitempty"` // optional foreign key to performance
+	MemberID      *string    `json:"member_id,omitempty"`      // optional foreign key to member
+	CheckedOutBy  string     `json:"checked_out_by"`           // foreign key to the admin who lent out the item
+	CheckedOutAt  time.Time  `json:"checked_out_at"`           // time the item left
+	DueAt         time.Time  `json:"due_at"`                   // time the item is expected back
+	CheckedInAt   *time.Time `json:"checked_in_at"`            // nil while the item is out
+	Notes         string     `json:"notes"`                    // notes from check out and check in
+	ItemName      string     `json:"item_name,omitempty"`      // read only, populated in reports
+}
+
+type Subscriber struct {
+	ID             string     `json:"id"`              // primary key, UUID
+	CreatedAt      time.Time  `json:"created_at"`      // time of creation
+	UpdatedAt      time.Time  `json:"updated_at"`      // time of last update
+	Email          string     `json:"email"`           // unique
+	Name           string     `json:"name"`            // optional
+	Status         string     `json:"status"`          // pending, confirmed, unsubscribed
+	ConfirmedAt    *time.Time `json:"confirmed_at"`    // time the subscriber confirmed the double opt-in
+	UnsubscribedAt *time.Time `json:"unsubscribed_at"` // time the subscriber unsubscribed
+}
+
+type AdminIdentity struct {
+	AdminID        string     `json:"admin_id"`         // foreign key to admin
+	Provider       string     `json:"provider"`         // goth provider name, e.g. google, oidc
+	ProviderUserID string     `json:"provider_user_id"` // stable user ID at the provider
+	Email          string     `json:"email"`            // email reported by the provider at the last login
+	CreatedAt      time.Time  `json:"created_at"`       // time the identity was linked
+	LastLoginAt    *time.Time `json:"last_login_at"`    // time of the last login with this identity
+}
+
+type APIToken struct {
+	ID         string     `json:"id"`                   // primary key, UUID
+	CreatedAt  time.Time  `json:"created_at"`           // time of creation
+	AdminID    string     `json:"admin_id"`             // foreign key to the owning admin
+	Name       string     `json:"name"`                 // what the token is used for
+	Prefix     string     `json:"prefix"`               // first characters of the token, to recognize it
+	Scopes     []string   `json:"scopes"`               // <resource>:read or <resource>:write
+	ExpiresAt  time.Time  `json:"expires_at"`           // the token is rejected after this time
+	LastUsedAt *time.Time `json:"last_used_at"`         // time of the last authenticated request
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"` // time the token was revoked
+}
+
+type AdminInvitation struct {
+	ID              string     `json:"id"`                          // primary key, UUID
+	CreatedAt       time.Time  `json:"created_at"`                  // time of creation
+	UpdatedAt       time.Time  `json:"updated_at"`                  // time of last update
+	Email           string     `json:"email"`                       // address the invitation was sent to
+	Name            string     `json:"name"`                        // name of the future admin
+	Position        string     `json:"position"`                    // position of the future admin
+	InvitedBy       string     `json:"invited_by"`                  // foreign key to the inviting admin
+	ExpiresAt       time.Time  `json:"expires_at"`                  // the invitation can't be accepted after this time
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`       // time the invitation was accepted
+	AcceptedAdminID *string    `json:"accepted_admin_id,omitempty"` // admin created when accepting
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`        // time the invitation was revoked
+	Status          string     `json:"status"`                      // read only: pending, accepted, expired, revoked
+}
+
+// http responses
+type AdminList struct {
+	Admins     []Admin `json:"admins"`
+	TotalCount int     `json:"totalCount"` // all admins the listing filter selects, not only this page
+}
+
 // http requests
 type AdminUpdateData struct {
 	Name     string
@@ -75,3 +210,27 @@ type UpdateEventRequest struct {
 	NewDisplayImage string                 `json:"new_display_image_id"`
 	EditorAdminID   string                 `json:"editor_admin_id"`
 }
+
+type CheckInRequest struct {
+	Condition      string `json:"condition"`       // optional, updates the item condition
+	ConditionNotes string `json:"condition_notes"` // optional, updates the item condition notes
+	Notes          string `json:"notes"`           // optional, appended to the checkout notes
+}
+
+type ContactFormRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+type SubscribeRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // optional, defaults to 90
+}
@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// methodOrder lists operations of a path the way readers expect them
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+type docsOperation struct {
+	Method string
+	Path   string
+	*Operation
+	Body      template.HTML
+	Responses []docsResponse
+}
+
+type docsResponse struct {
+	Code        string
+	Description string
+	Content     template.HTML
+}
+
+type docsTag struct {
+	Name       string
+	Operations []docsOperation
+}
+
+type docsSchema struct {
+	Name string
+	*Schema
+}
+
+// DocsHandler serves the documentation page. It is rendered from the
+// embedded specification once, without scripts or anything loaded from a CDN.
+func DocsHandler() http.HandlerFunc {
+	page, err := renderDocs()
+	if err != nil {
+		// the specification is embedded, this is caught by the tests
+		panic(fmt.Sprintf("openapi: rendering docs: %v", err))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(page)
+	}
+}
+
+func renderDocs() ([]byte, error) {
+	doc, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// operations grouped by their first tag, in the order tags are declared
+	byTag := map[string]*docsTag{}
+	var tags []*docsTag
+	for _, tag := range doc.Tags {
+		byTag[tag.Name] = &docsTag{Name: tag.Name}
+		tags = append(tags, byTag[tag.Name])
+	}
+	for _, path := range sortedKeys(doc.Paths) {
+		for _, method := range methodOrder {
+			op, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+			tag := byTag[op.Tags[0]]
+			if tag == nil {
+				return nil, fmt.Errorf("%s %s: undeclared tag %q", method, path, op.Tags[0])
+			}
+			tag.Operations = append(tag.Operations, newDocsOperation(method, path, op))
+		}
+	}
+
+	var schemas []docsSchema
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		schemas = append(schemas, docsSchema{Name: name, Schema: doc.Components.Schemas[name]})
+	}
+
+	var buf bytes.Buffer
+	err = docsTemplate.Execute(&buf, map[string]interface{}{
+		"Doc":     doc,
+		"Tags":    tags,
+		"Schemas": schemas,
+	})
+	return buf.Bytes(), err
+}
+
+func newDocsOperation(method, path string, op *Operation) docsOperation {
+	d := docsOperation{Method: strings.ToUpper(method), Path: path, Operation: op}
+	if op.RequestBody != nil {
+		d.Body = content(op.RequestBody.Content)
+	}
+	for _, code := range sortedKeys(op.Responses) {
+		response := op.Responses[code]
+		if response.Ref != "" {
+			// shared error responses are plain text
+			name := response.Ref[strings.LastIndex(response.Ref, "/")+1:]
+			d.Responses = append(d.Responses, docsResponse{Code: code, Description: name})
+			continue
+		}
+		d.Responses = append(d.Responses, docsResponse{
+			Code: code, Description: response.Description, Content: content(response.Content),
+		})
+	}
+	return d
+}
+
+// content lists the schema of every media type
+func content(media map[string]*MediaType) template.HTML {
+	var parts []string
+	for _, mediaType := range sortedKeys(media) {
+		parts = append(parts, string(typeName(media[mediaType].Schema))+" <small>"+template.HTMLEscapeString(mediaType)+"</small>")
+	}
+	return template.HTML(strings.Join(parts, ", "))
+}
+
+// typeName describes a schema in a few words, linking to components
+func typeName(s *Schema) template.HTML {
+	if s == nil {
+		return ""
+	}
+	if name := s.RefName(); name != "" {
+		return template.HTML(fmt.Sprintf(`<a href="#schema-%s">%s</a>`, template.HTMLEscapeString(name), template.HTMLEscapeString(name)))
+	}
+	name := template.HTML(template.HTMLEscapeString(s.Type))
+	switch {
+	case s.Type == "array":
+		name = "array of " + typeName(s.Items)
+	case s.Format != "":
+		name += template.HTML(" (" + template.HTMLEscapeString(s.Format) + ")")
+	}
+	if len(s.Enum) > 0 {
+		name += template.HTML(": " + template.HTMLEscapeString(strings.Join(s.Enum, ", ")))
+	}
+	if s.Nullable {
+		name += ", nullable"
+	}
+	return name
+}
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"typeName":   typeName,
+	"sortedKeys": sortedKeys[*Schema],
+	"lower":      strings.ToLower,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Info.Title}} {{.Doc.Info.Version}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+code, .path { font-family: ui-monospace, monospace; }
+.op { border: 1px solid #ddd; border-radius: 4px; margin: 1rem 0; padding: 0.5rem 1rem; }
+.method { display: inline-block; min-width: 4.5rem; font-weight: bold; }
+.get { color: #1a7f37; } .post { color: #0969da; } .put { color: #9a6700; } .delete { color: #cf222e; }
+.auth { float: right; font-size: 0.8rem; color: #666; }
+table { border-collapse: collapse; width: 100%; margin: 0.5rem 0; }
+th, td { text-align: left; padding: 0.25rem 0.5rem; border-bottom: 1px solid #eee; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Doc.Info.Title}} <small>{{.Doc.Info.Version}}</small></h1>
+<p>{{.Doc.Info.Description}}</p>
+<p>The machine readable specification is at <a href="/api/openapi.json"><code>/api/openapi.json</code></a>.</p>
+<nav><ul>{{range .Tags}}{{if .Operations}}<li><a href="#tag-{{.Name}}">{{.Name}}</a></li>{{end}}{{end}}<li><a href="#schemas">schemas</a></li></ul></nav>
+{{range .Tags}}{{if .Operations}}
+<h2 id="tag-{{.Name}}">{{.Name}}</h2>
+{{range .Operations}}
+<div class="op">
+<p>{{if .Security}}<span class="auth">{{range $i, $s := .Security}}{{if $i}} or {{end}}{{range $name, $_ := $s}}{{$name}}{{end}}{{end}}</span>{{end}}
+<span class="method {{lower .Method}}">{{.Method}}</span> <span class="path">{{.Path}}</span></p>
+<p><strong>{{.Summary}}</strong>{{if .Description}} {{.Description}}{{end}}</p>
+{{if .Parameters}}<table><tr><th>Parameter</th><th>In</th><th>Type</th><th>Description</th></tr>
+{{range .Parameters}}<tr><td><code>{{.Name}}</code>{{if .Required}} *{{end}}</td><td>{{.In}}</td><td>{{typeName .Schema}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Body}}<p>Request body: {{.Body}}</p>{{end}}
+<table><tr><th>Status</th><th>Response</th><th>Body</th></tr>
+{{range .Responses}}<tr><td>{{.Code}}</td><td>{{.Description}}</td><td>{{.Content}}</td></tr>
+{{end}}</table>
+</div>
+{{end}}{{end}}{{end}}
+<h2 id="schemas">schemas</h2>
+{{range .Schemas}}
+<h3 id="schema-{{.Name}}">{{.Name}}</h3>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<table><tr><th>Field</th><th>Type</th><th>Description</th></tr>
+{{$schema := .}}{{range $name := sortedKeys .Properties}}{{with index $schema.Properties $name}}<tr><td><code>{{$name}}</code></td><td>{{typeName .}}</td><td>{{.Description}}</td></tr>
+{{end}}{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
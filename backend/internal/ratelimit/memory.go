@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"backend/internal/config"
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in process, limits aren't shared between
+// instances. At most maxKeys buckets are kept, the least recently used one
+// is evicted to make room, which at worst gives that client a fresh bucket.
+type MemoryStore struct {
+	mu      sync.Mutex
+	maxKeys int
+	// most recently used first
+	order   *list.List
+	buckets map[string]*list.Element
+}
+
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	return &MemoryStore{
+		maxKeys: maxKeys,
+		order:   list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryStore) Take(ctx context.Context, key string, policy config.RateLimitPolicy, now time.Time) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b *bucket
+	if element, ok := m.buckets[key]; ok {
+		m.order.MoveToFront(element)
+		b = element.Value.(*bucket)
+		if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+			b.tokens = math.Min(float64(policy.Requests), b.tokens+elapsed*rate(policy))
+		}
+	} else {
+		if m.order.Len() >= m.maxKeys {
+			oldest := m.order.Back()
+			m.order.Remove(oldest)
+			delete(m.buckets, oldest.Value.(*bucket).key)
+		}
+		b = &bucket{key: key, tokens: float64(policy.Requests)}
+		m.buckets[key] = m.order.PushFront(b)
+	}
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return decide(policy, allowed, b.tokens), nil
+}
+
+// Len returns the number of buckets kept
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/loggers"
+	"context"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every
+// instance behind the load balancer enforces the same limits
+type PostgresStore struct {
+	db database.Service
+	// idle buckets are deleted after this long, the longest policy window
+	idle time.Duration
+}
+
+func NewPostgresStore(db database.Service, cfg config.RateLimit) *PostgresStore {
+	idle := time.Hour
+	for _, policy := range cfg.Policies {
+		if policy.Window > idle {
+			idle = policy.Window
+		}
+	}
+	return &PostgresStore{db: db, idle: idle}
+}
+
+func (p *PostgresStore) Take(ctx context.Context, key string, policy config.RateLimitPolicy, now time.Time) (Decision, error) {
+	allowed, tokens, err := p.db.TakeRateLimitToken(ctx, key, policy.Requests, rate(policy), now)
+	if err != nil {
+		return Decision{}, err
+	}
+	return decide(policy, allowed, tokens), nil
+}
+
+// Run deletes idle buckets until ctx is cancelled, it's run by the lifecycle manager
+func (p *PostgresStore) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.idle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			deleted, err := p.db.DeleteIdleRateLimitBuckets(ctx, now.Add(-p.idle))
+			if err != nil {
+				loggers.Error.Ctx(ctx).Printf("deleting idle rate limit buckets: %v", err)
+				continue
+			}
+			loggers.Debug.Ctx(ctx).Printf("deleted %d idle rate limit buckets", deleted)
+		}
+	}
+}
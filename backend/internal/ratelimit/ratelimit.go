@@ -0,0 +1,172 @@
+// Package ratelimit limits requests per client and route. Clients are the
+// signed in admin or the client IP, read through trusted proxies. Every route
+// picks a named policy from the configuration, each has its own buckets.
+package ratelimit
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/metrics"
+	"backend/loggers"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decision is the outcome of taking a token from a client's bucket
+type Decision struct {
+	Allowed bool
+	// Limit is the number of requests allowed per window
+	Limit int
+	// Remaining requests the client can make right now
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, 0 when allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets, one per key
+type Store interface {
+	Take(ctx context.Context, key string, policy config.RateLimitPolicy, now time.Time) (Decision, error)
+}
+
+// rate is the refill rate of policy in tokens per second
+func rate(policy config.RateLimitPolicy) float64 {
+	return float64(policy.Requests) / policy.Window.Seconds()
+}
+
+// decide builds the Decision for a bucket left with tokens after a take
+func decide(policy config.RateLimitPolicy, allowed bool, tokens float64) Decision {
+	perSecond := rate(policy)
+	d := Decision{
+		Allowed:   allowed,
+		Limit:     policy.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Requests) - tokens) / perSecond * float64(time.Second)),
+	}
+	if !allowed {
+		d.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+	return d
+}
+
+type Limiter struct {
+	store    Store
+	policies map[string]config.RateLimitPolicy
+	trusted  []netip.Prefix
+	now      func() time.Time
+}
+
+func New(cfg config.RateLimit, store Store) *Limiter {
+	return &Limiter{
+		store:    store,
+		policies: cfg.Policies,
+		trusted:  cfg.TrustedProxies,
+		now:      time.Now,
+	}
+}
+
+// Limit returns middleware limiting a route by the named policy. Placed
+// after auth.AuthMiddleware it limits per admin, otherwise per client IP.
+// An unknown policy is a programming error and panics when routes are registered.
+func (l *Limiter) Limit(name string) func(http.Handler) http.Handler {
+	policy, ok := l.policies[name]
+	if !ok {
+		panic(fmt.Sprintf("ratelimit: unknown policy %q", name))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, err := l.store.Take(r.Context(), name+":"+l.clientKey(r), policy, l.now())
+			if err != nil {
+				// a broken store must not take the contact form down with it
+				loggers.Error.Ctx(r.Context()).Printf("rate limit store failed, allowing request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(seconds(decision.Reset)))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Requests, seconds(policy.Window)))
+
+			if !decision.Allowed {
+				metrics.RateLimitRejected(r)
+				h.Set("Retry-After", strconv.Itoa(seconds(decision.RetryAfter)))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds d up, a client retrying after the header value must succeed
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func (l *Limiter) clientKey(r *http.Request) string {
+	if adminID, ok := auth.AdminIDFromContext(r.Context()); ok {
+		return "admin:" + adminID
+	}
+	return "ip:" + ClientIP(r, l.trusted).String()
+}
+
+// ClientIP returns the address of the client. The peer address is used
+// unless it's a trusted proxy, then X-Forwarded-For is walked from the right
+// and the first address that isn't a trusted proxy is the client. Addresses
+// further left were added by the client itself and can't be believed.
+func ClientIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	peer := parseAddr(r.RemoteAddr)
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := parseAddr(strings.TrimSpace(forwarded[i]))
+		if !addr.IsValid() {
+			// garbage in the header, don't look past it
+			break
+		}
+		if !isTrusted(addr, trusted) {
+			return addr
+		}
+		peer = addr
+	}
+	// every hop was a trusted proxy, the leftmost one is the closest to the client
+	return peer
+}
+
+// parseAddr accepts an address with or without a port
+func parseAddr(value string) netip.Addr {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
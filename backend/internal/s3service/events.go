@@ -2,17 +2,17 @@ package s3service
 
 import (
 	"backend/loggers"
+	"context"
 	"fmt"
-	"os"
 	"time"
 )
 
-func (s *service) GenerateEventImageUploadURL(event, filename string, lifetimeSecs int64) (string, error) {
+func (s *service) GenerateEventImageUploadURL(ctx context.Context, event, filename string, lifetimeSecs int64) (string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
+	bucket := s.bucket
 	prefix := fmt.Sprintf("events/%s/%s", event, filename)
 
-	req, err := s.presigner.PutObject(bucket, prefix, lifetimeSecs)
+	req, err := s.presigner.PutObject(ctx, bucket, prefix, lifetimeSecs)
 	if err != nil {
 		return "", fmt.Errorf("failed to get presigned url: %v", err)
 	}
@@ -22,12 +22,12 @@ func (s *service) GenerateEventImageUploadURL(event, filename string, lifetimeSe
 	return req.URL, nil
 }
 
-func (s *service) DevGenerateEventImageUploadURL(event, filename string, lifetimeSecs int64) (string, error) {
+func (s *service) DevGenerateEventImageUploadURL(ctx context.Context, event, filename string, lifetimeSecs int64) (string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
+	bucket := s.bucket
 	prefix := fmt.Sprintf("testing/%s/%s", event, filename)
 
-	req, err := s.presigner.PutObject(bucket, prefix, lifetimeSecs)
+	req, err := s.presigner.PutObject(ctx, bucket, prefix, lifetimeSecs)
 	if err != nil {
 		return "", fmt.Errorf("failed to get presigned url: %v", err)
 	}
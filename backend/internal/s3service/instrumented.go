@@ -0,0 +1,117 @@
+package s3service
+
+import (
+	"backend/internal/metrics"
+	"backend/internal/tracing"
+	"context"
+	"time"
+)
+
+// instrumented records the latency and errors of every call to next, each
+// also gets a span
+type instrumented struct {
+	next Service
+}
+
+func instrument(next Service) Service {
+	return &instrumented{next: next}
+}
+
+func (i *instrumented) GetPhotoshootYears(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GetPhotoshootYears")
+	years, err := i.next.GetPhotoshootYears(ctx)
+	tracing.End(span, err)
+	metrics.ObserveS3("GetPhotoshootYears", start, err)
+	return years, err
+}
+
+func (i *instrumented) GetPhotoshootEvents(ctx context.Context, year string) ([]string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GetPhotoshootEvents")
+	events, err := i.next.GetPhotoshootEvents(ctx, year)
+	tracing.End(span, err)
+	metrics.ObserveS3("GetPhotoshootEvents", start, err)
+	return events, err
+}
+
+func (i *instrumented) ListPhotoshootPhotos(ctx context.Context, year, event string) ([]string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.ListPhotoshootPhotos")
+	photos, err := i.next.ListPhotoshootPhotos(ctx, year, event)
+	tracing.End(span, err)
+	metrics.ObserveS3("ListPhotoshootPhotos", start, err)
+	return photos, err
+}
+
+func (i *instrumented) GetPhotoshootPhotos(ctx context.Context, year, event string) ([]string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GetPhotoshootPhotos")
+	urls, err := i.next.GetPhotoshootPhotos(ctx, year, event)
+	tracing.End(span, err)
+	metrics.ObserveS3("GetPhotoshootPhotos", start, err)
+	return urls, err
+}
+
+func (i *instrumented) GenerateEventImageUploadURL(ctx context.Context, eventID, filename string, lifetimeSecs int64) (string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GenerateEventImageUploadURL")
+	url, err := i.next.GenerateEventImageUploadURL(ctx, eventID, filename, lifetimeSecs)
+	tracing.End(span, err)
+	metrics.ObserveS3("GenerateEventImageUploadURL", start, err)
+	return url, err
+}
+
+func (i *instrumented) DevGenerateEventImageUploadURL(ctx context.Context, eventID, filename string, lifetimeSecs int64) (string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.DevGenerateEventImageUploadURL")
+	url, err := i.next.DevGenerateEventImageUploadURL(ctx, eventID, filename, lifetimeSecs)
+	tracing.End(span, err)
+	metrics.ObserveS3("DevGenerateEventImageUploadURL", start, err)
+	return url, err
+}
+
+func (i *instrumented) GenerateInventoryPhotoUploadURL(ctx context.Context, itemID, filename string, lifetimeSecs int64) (string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GenerateInventoryPhotoUploadURL")
+	url, err := i.next.GenerateInventoryPhotoUploadURL(ctx, itemID, filename, lifetimeSecs)
+	tracing.End(span, err)
+	metrics.ObserveS3("GenerateInventoryPhotoUploadURL", start, err)
+	return url, err
+}
+
+func (i *instrumented) GetInventoryPhotoURL(ctx context.Context, key string, lifetimeSecs int64) (string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GetInventoryPhotoURL")
+	url, err := i.next.GetInventoryPhotoURL(ctx, key, lifetimeSecs)
+	tracing.End(span, err)
+	metrics.ObserveS3("GetInventoryPhotoURL", start, err)
+	return url, err
+}
+
+func (i *instrumented) InventoryPhotoExists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.InventoryPhotoExists")
+	exists, err := i.next.InventoryPhotoExists(ctx, key)
+	tracing.End(span, err)
+	metrics.ObserveS3("InventoryPhotoExists", start, err)
+	return exists, err
+}
+
+func (i *instrumented) GetPresignedURL(ctx context.Context, bucket, key string, lifetimeSecs int64) (string, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.GetPresignedURL")
+	url, err := i.next.GetPresignedURL(ctx, bucket, key, lifetimeSecs)
+	tracing.End(span, err)
+	metrics.ObserveS3("GetPresignedURL", start, err)
+	return url, err
+}
+
+func (i *instrumented) Ping(ctx context.Context) error {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "s3service.Ping")
+	err := i.next.Ping(ctx)
+	tracing.End(span, err)
+	metrics.ObserveS3("Ping", start, err)
+	return err
+}
@@ -0,0 +1,55 @@
+package s3service
+
+import (
+	"backend/loggers"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// InventoryPhotoKey returns the object key an inventory item photo is stored under
+func InventoryPhotoKey(itemID, filename string) string {
+	return fmt.Sprintf("inventory/%s/%s", itemID, filename)
+}
+
+// GenerateInventoryPhotoUploadURL returns a presigned url the client can PUT an item photo to.
+func (s *service) GenerateInventoryPhotoUploadURL(ctx context.Context, itemID, filename string, lifetimeSecs int64) (string, error) {
+	startTime := time.Now()
+	bucket := s.bucket
+
+	req, err := s.presigner.PutObject(ctx, bucket, InventoryPhotoKey(itemID, filename), lifetimeSecs)
+	if err != nil {
+		return "", fmt.Errorf("failed to get presigned url: %v", err)
+	}
+
+	elapsedTime := time.Since(startTime)
+	loggers.Performance.Printf("GenerateInventoryPhotoUploadURL took %s", elapsedTime)
+	return req.URL, nil
+}
+
+// GetInventoryPhotoURL returns a presigned url to view an item photo.
+func (s *service) GetInventoryPhotoURL(ctx context.Context, key string, lifetimeSecs int64) (string, error) {
+	return s.GetPresignedURL(ctx, s.bucket, key, lifetimeSecs)
+}
+
+// InventoryPhotoExists reports whether a photo was uploaded under key, items
+// only reference photos once the upload finished
+func (s *service) InventoryPhotoExists(ctx context.Context, key string) (bool, error) {
+	_, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,16 +1,16 @@
 package s3service
 
 import (
+	"backend/internal/tracing"
 	"backend/loggers"
 	"context"
 	"fmt"
-	"log"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go/aws"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // GetYears returns a list of years representing the available photoshoots in the S3 bucket.
@@ -18,15 +18,9 @@ import (
 // The years are returned as a slice of strings, or error if the operation failed.
 func (s *service) GetPhotoshootYears(ctx context.Context) ([]string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
 	prefix := "photoshoots/"
 
-	// call to list
-	output, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
-	})
+	output, err := s.list(ctx, prefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects from s3: %v", err)
 	}
@@ -39,7 +33,7 @@ func (s *service) GetPhotoshootYears(ctx context.Context) ([]string, error) {
 	}
 
 	elapsedTime := time.Since(startTime)
-	loggers.Performance.Printf("GetYears took %s", elapsedTime)
+	loggers.Performance.Ctx(ctx).Printf("GetYears took %s", elapsedTime)
 	return years, nil
 }
 
@@ -48,14 +42,9 @@ func (s *service) GetPhotoshootYears(ctx context.Context) ([]string, error) {
 // It returns a slice of strings representing the events and an error if any.
 func (s *service) GetPhotoshootEvents(ctx context.Context, year string) ([]string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
 	prefix := fmt.Sprintf("photoshoots/%s/", year)
 
-	output, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
-	})
+	output, err := s.list(ctx, prefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects from s3: %v", err)
 	}
@@ -68,7 +57,7 @@ func (s *service) GetPhotoshootEvents(ctx context.Context, year string) ([]strin
 	}
 
 	elapsedTime := time.Since(startTime)
-	log.Printf("GetEvents took %s", elapsedTime)
+	loggers.Performance.Ctx(ctx).Printf("GetEvents took %s", elapsedTime)
 
 	return events, nil
 }
@@ -78,14 +67,9 @@ func (s *service) GetPhotoshootEvents(ctx context.Context, year string) ([]strin
 // It returns a slice of strings containing the photo names and an error if any.
 func (s *service) ListPhotoshootPhotos(ctx context.Context, year, event string) ([]string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
 	prefix := fmt.Sprintf("photoshoots/%s/%s/", year, event)
 
-	output, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
-	})
+	output, err := s.list(ctx, prefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects from s3: %v", err)
 	}
@@ -97,7 +81,7 @@ func (s *service) ListPhotoshootPhotos(ctx context.Context, year, event string)
 	}
 
 	elapsedTime := time.Since(startTime)
-	loggers.Performance.Printf("ListPhotos took %s", elapsedTime)
+	loggers.Performance.Ctx(ctx).Printf("ListPhotos took %s", elapsedTime)
 	return photos, nil
 }
 
@@ -106,23 +90,25 @@ func (s *service) ListPhotoshootPhotos(ctx context.Context, year, event string)
 // It returns a slice of strings containing the photo URLs and an error if any.
 func (s *service) GetPhotoshootPhotos(ctx context.Context, year, event string) ([]string, error) {
 	startTime := time.Now()
-	bucket := os.Getenv("S3_BUCKET_NAME")
+	bucket := s.bucket
 	prefix := fmt.Sprintf("photoshoots/%s/%s/", year, event)
 
-	output, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
-	})
+	// separate spans show whether listing or presigning is slow
+	listCtx, span := tracing.Start(ctx, "s3.ListObjectsV2", attribute.String("s3.prefix", prefix))
+	output, err := s.list(listCtx, prefix)
+	tracing.End(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects from s3: %v", err)
 	}
 
+	_, span = tracing.Start(ctx, "s3.PresignGetObjects", attribute.Int("s3.objects", len(output.Contents)))
+	defer span.End()
+
 	var photoURLs []string
 	for _, content := range output.Contents {
-		request, err := s.presigner.GetObject(bucket, *content.Key, 900) // 900 seconds = 15 minutes
+		request, err := s.presigner.GetObject(ctx, bucket, *content.Key, 900) // 900 seconds = 15 minutes
 		if err != nil {
-			log.Printf("failed to create presigned URL for %s: %v", *content.Key, err)
+			loggers.Error.Ctx(ctx).Printf("failed to create presigned URL for %s: %v", *content.Key, err)
 			continue // log error and continue with the next object
 		}
 
@@ -130,6 +116,27 @@ func (s *service) GetPhotoshootPhotos(ctx context.Context, year, event string) (
 	}
 
 	elapsedTime := time.Since(startTime)
-	loggers.Performance.Printf("GetPhotos took %s", elapsedTime)
+	loggers.Performance.Ctx(ctx).Printf("GetPhotos took %s", elapsedTime)
 	return photoURLs, nil
 }
+
+// list returns every object and common prefix directly under prefix. A
+// single ListObjectsV2 call stops at 1000 entries, so it follows the
+// continuation tokens until the listing is complete.
+func (s *service) list(ctx context.Context, prefix string) (*s3.ListObjectsV2Output, error) {
+	all := &s3.ListObjectsV2Output{}
+	pages := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all.Contents = append(all.Contents, page.Contents...)
+		all.CommonPrefixes = append(all.CommonPrefixes, page.CommonPrefixes...)
+	}
+	return all, nil
+}
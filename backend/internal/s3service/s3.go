@@ -1,9 +1,9 @@
 package s3service
 
 import (
+	appconfig "backend/internal/config"
 	"backend/loggers"
 	"context"
-	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
@@ -21,59 +21,83 @@ type Service interface {
 	GetPhotoshootPhotos(ctx context.Context, year, event string) ([]string, error)
 
 	// events image upload using presigned urls
-	GenerateEventImageUploadURL(eventID, filename string, lifetimeSecs int64) (string, error)
-	DevGenerateEventImageUploadURL(eventID, filename string, lifetimeSecs int64) (string, error)
+	GenerateEventImageUploadURL(ctx context.Context, eventID, filename string, lifetimeSecs int64) (string, error)
+	DevGenerateEventImageUploadURL(ctx context.Context, eventID, filename string, lifetimeSecs int64) (string, error)
+
+	// inventory photos using presigned urls
+	GenerateInventoryPhotoUploadURL(ctx context.Context, itemID, filename string, lifetimeSecs int64) (string, error)
+	GetInventoryPhotoURL(ctx context.Context, key string, lifetimeSecs int64) (string, error)
+	InventoryPhotoExists(ctx context.Context, key string) (bool, error)
 
 	// generic
-	GetPresignedURL(bucket, key string, lifetimeSecs int64) (string, error)
+	GetPresignedURL(ctx context.Context, bucket, key string, lifetimeSecs int64) (string, error)
+	Ping(ctx context.Context) error
 }
 
 // S3ClientAPI defines the methods used from the S3 client.
 type S3ClientAPI interface {
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 }
 
 // PresignerAPI defines the methods used from the presigner.
 type PresignerAPI interface {
-	GetObject(bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error)
-	PutObject(bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error)
-	DeleteObject(bucketName string, objectKey string) (*v4.PresignedHTTPRequest, error)
+	GetObject(ctx context.Context, bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error)
+	PutObject(ctx context.Context, bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error)
+	DeleteObject(ctx context.Context, bucketName string, objectKey string) (*v4.PresignedHTTPRequest, error)
 }
 
 // service struct
 type service struct {
 	s3Client  S3ClientAPI
 	presigner PresignerAPI
+	bucket    string
 }
 
 // NewMockService creates a new instance of the service with provided mock clients
-func NewMockService(s3Client S3ClientAPI, presigner PresignerAPI) Service {
-	return &service{
+func NewMockService(s3Client S3ClientAPI, presigner PresignerAPI, bucket string) Service {
+	return instrument(&service{
 		s3Client:  s3Client,
 		presigner: presigner,
-	}
+		bucket:    bucket,
+	})
 }
 
 // NewService creates an instance intended for production
-func NewService() Service {
-	cfg, err := NewAWSConfig()
+func NewService(s3cfg appconfig.S3) Service {
+	s3Client, err := NewClient(s3cfg)
 	if err != nil {
 		loggers.Error.Printf("failed to load AWS config: %v", err)
 		return nil
 	}
-	s3Client := s3.NewFromConfig(cfg)
 	presigner := s3.NewPresignClient(s3Client)
-	return &service{
+	return instrument(&service{
 		s3Client:  s3Client,
 		presigner: &Presigner{PresignClient: presigner},
+		bucket:    s3cfg.Bucket,
+	})
+}
+
+// NewClient creates an S3 client for the configured region, or for the
+// configured endpoint when talking to an S3-compatible server
+func NewClient(s3cfg appconfig.S3) (*s3.Client, error) {
+	cfg, err := NewAWSConfig(s3cfg)
+	if err != nil {
+		return nil, err
 	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if s3cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s3cfg.Endpoint)
+			o.UsePathStyle = true
+		}
+	}), nil
 }
 
 // newAWSConfig creates and returns a new AWS configuration.
-func NewAWSConfig() (aws.Config, error) {
+func NewAWSConfig(s3cfg appconfig.S3) (aws.Config, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(os.Getenv("AWS_REGION")),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), "")),
+		config.WithRegion(s3cfg.Region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(s3cfg.AccessKeyID, s3cfg.SecretAccessKey, "")),
 	)
 	if err != nil {
 		loggers.Error.Printf("failed to load AWS config: %v", err)
@@ -82,10 +106,19 @@ func NewAWSConfig() (aws.Config, error) {
 	return cfg, nil
 }
 
-func (s *service) GetPresignedURL(bucket, key string, lifetimeSecs int64) (string, error) {
-	request, err := s.presigner.GetObject(bucket, key, lifetimeSecs)
+func (s *service) GetPresignedURL(ctx context.Context, bucket, key string, lifetimeSecs int64) (string, error) {
+	request, err := s.presigner.GetObject(ctx, bucket, key, lifetimeSecs)
 	if err != nil {
 		return "", err
 	}
 	return request.URL, nil
 }
+
+// Ping checks that the bucket is reachable with the configured credentials
+func (s *service) Ping(ctx context.Context) error {
+	_, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		MaxKeys: aws.Int32(1),
+	})
+	return err
+}
@@ -0,0 +1,112 @@
+package s3fake
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []listObject   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type listObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// listObjects answers ListObjectsV2. Keys sharing the part of their name up
+// to the delimiter are rolled up into one common prefix, which counts as one
+// entry towards the page size. The continuation token is the last entry of
+// the page, so the next page starts after it and after every key it rolled
+// up.
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucket string, objects map[string]*object) {
+	query := r.URL.Query()
+	result := listBucketResult{
+		Name:              bucket,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		StartAfter:        query.Get("start-after"),
+		ContinuationToken: query.Get("continuation-token"),
+		MaxKeys:           s.MaxKeys,
+	}
+	if value := query.Get("max-keys"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument", "max-keys must be a non-negative integer")
+			return
+		}
+		result.MaxKeys = min(n, s.MaxKeys)
+	}
+
+	after := result.StartAfter
+	if result.ContinuationToken != "" {
+		token, err := base64.RawURLEncoding.DecodeString(result.ContinuationToken)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect")
+			return
+		}
+		after = string(token)
+	}
+
+	last := ""
+	for _, key := range sortedKeys(objects) {
+		if !strings.HasPrefix(key, result.Prefix) || key <= after {
+			continue
+		}
+		entry, rolledUp := key, false
+		if result.Delimiter != "" {
+			rest := key[len(result.Prefix):]
+			if i := strings.Index(rest, result.Delimiter); i >= 0 {
+				entry, rolledUp = result.Prefix+rest[:i+len(result.Delimiter)], true
+			}
+		}
+		// the rest of a prefix rolled up on this or the previous page
+		if entry == last || (rolledUp && entry == after) {
+			continue
+		}
+		if result.KeyCount == result.MaxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+			break
+		}
+
+		if rolledUp {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: entry})
+		} else {
+			obj := objects[key]
+			result.Contents = append(result.Contents, listObject{
+				Key:          key,
+				LastModified: obj.modified.Format(time.RFC3339Nano),
+				ETag:         obj.etag,
+				Size:         len(obj.data),
+				StorageClass: "STANDARD",
+			})
+		}
+		result.KeyCount++
+		last = entry
+	}
+
+	writeXML(w, http.StatusOK, result)
+}
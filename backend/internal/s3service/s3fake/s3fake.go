@@ -0,0 +1,281 @@
+// Package s3fake is an in-process S3-compatible server for tests. It keeps
+// buckets in memory and speaks enough of the S3 REST API for s3service and
+// the SDK: path-style bucket and object requests, ListObjectsV2 with
+// delimiters and continuation tokens, and SigV4 verification of both signed
+// headers and presigned URLs, so a URL the service hands out is only accepted
+// if real S3 would accept it.
+package s3fake
+
+import (
+	"backend/internal/config"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Credentials the server accepts unless the fields are changed
+const (
+	Region          = "us-east-1"
+	AccessKeyID     = "s3fake-access-key"
+	SecretAccessKey = "s3fake-secret-key"
+)
+
+// maxKeys is the largest page S3 returns
+const maxKeys = 1000
+
+// Server is an S3-compatible http.Handler
+type Server struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// MaxKeys caps the entries per listing page below S3's 1000, tests lower
+	// it to exercise continuation tokens without thousands of objects
+	MaxKeys int
+	// Now is the clock signatures and expiries are checked against
+	Now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]map[string]*object
+	requestID atomic.Int64
+}
+
+type object struct {
+	data        []byte
+	contentType string
+	etag        string
+	modified    time.Time
+}
+
+// New returns a server without buckets that accepts the default credentials
+func New() *Server {
+	return &Server{
+		Region:          Region,
+		AccessKeyID:     AccessKeyID,
+		SecretAccessKey: SecretAccessKey,
+		MaxKeys:         maxKeys,
+		Now:             time.Now,
+		buckets:         map[string]map[string]*object{},
+	}
+}
+
+// Start serves a new server with the given bucket until the test ends, the
+// returned configuration points s3service at it
+func Start(t testing.TB, bucket string) (*Server, config.S3) {
+	t.Helper()
+	s := New()
+	s.CreateBucket(bucket)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, s.Config(srv.URL, bucket)
+}
+
+// Config returns the S3 configuration for a server listening on endpoint
+func (s *Server) Config(endpoint, bucket string) config.S3 {
+	return config.S3{
+		Region:          s.Region,
+		AccessKeyID:     s.AccessKeyID,
+		SecretAccessKey: s.SecretAccessKey,
+		Bucket:          bucket,
+		Endpoint:        endpoint,
+	}
+}
+
+// CreateBucket adds an empty bucket, existing buckets are left alone
+func (s *Server) CreateBucket(bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = map[string]*object{}
+	}
+}
+
+// PutObject stores data under key without going through http, the bucket is
+// created if needed
+func (s *Server) PutObject(bucket, key string, data []byte) {
+	s.CreateBucket(bucket)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key] = s.newObject(data, "")
+}
+
+// Object returns the data stored under key
+func (s *Server) Object(bucket, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.buckets[bucket][key]
+	if !ok {
+		return nil, false
+	}
+	return obj.data, true
+}
+
+// Keys returns the keys in a bucket in listing order
+func (s *Server) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.buckets[bucket])
+}
+
+// ServeHTTP handles path-style requests, /bucket and /bucket/key
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("x-amz-request-id", strconv.FormatInt(s.requestID.Add(1), 10))
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if apiErr := s.authenticate(r, body); apiErr != nil {
+		writeError(w, r, apiErr.status, apiErr.code, apiErr.message)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case bucket == "":
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", "listing buckets is not supported")
+	case key == "":
+		s.serveBucket(w, r, bucket)
+	default:
+		s.serveObject(w, r, bucket, key, body)
+	}
+}
+
+// ===== internal ===== //
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if r.Method == http.MethodPut {
+		s.CreateBucket(bucket)
+		w.Header().Set("Location", "/"+bucket)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodHead:
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		s.listObjects(w, r, bucket, objects)
+	case r.Method == http.MethodDelete:
+		if len(objects) > 0 {
+			writeError(w, r, http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty")
+			return
+		}
+		delete(s.buckets, bucket)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", r.Method+" on a bucket is not supported")
+	}
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		obj := s.newObject(body, r.Header.Get("Content-Type"))
+		objects[key] = obj
+		w.Header().Set("ETag", obj.etag)
+	case http.MethodGet, http.MethodHead:
+		obj, ok := objects[key]
+		if !ok {
+			writeError(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("ETag", obj.etag)
+		w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		// deleting a missing key succeeds on S3 too
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", r.Method+" on an object is not supported")
+	}
+}
+
+func (s *Server) newObject(data []byte, contentType string) *object {
+	if contentType == "" {
+		contentType = "binary/octet-stream"
+	}
+	sum := md5.Sum(data)
+	return &object{
+		data:        bytes.Clone(data),
+		contentType: contentType,
+		etag:        `"` + hex.EncodeToString(sum[:]) + `"`,
+		// S3 keeps last modified to the second in headers
+		modified: s.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func sortedKeys(objects map[string]*object) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// writeError answers like S3, HEAD responses carry no body so clients only
+// see the status
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeXML(w, status, errorResponse{
+		Code:      code,
+		Message:   message,
+		Resource:  r.URL.Path,
+		RequestID: w.Header().Get("x-amz-request-id"),
+	})
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	out, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("encoding response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
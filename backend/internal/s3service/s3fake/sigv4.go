@@ -0,0 +1,223 @@
+package s3fake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// maxClockSkew is how far the date of a signed request may be off
+	maxClockSkew = 15 * time.Minute
+	// maxExpires is the longest lifetime S3 allows a presigned URL, a week
+	maxExpires = 7 * 24 * 60 * 60
+)
+
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func accessDenied(message string) *apiError {
+	return &apiError{http.StatusForbidden, "AccessDenied", message}
+}
+
+// signature is what a request claims about how it was signed, from either
+// the Authorization header or the X-Amz-* query parameters of a presigned URL
+type signature struct {
+	credential    string
+	signedHeaders []string
+	signature     string
+	date          string
+	payloadHash   string
+	presigned     bool
+}
+
+// authenticate verifies the SigV4 signature of a request, nil means the
+// request may proceed
+func (s *Server) authenticate(r *http.Request, body []byte) *apiError {
+	sig, apiErr := parseSignature(r)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	// the credential scope is accesskey/date/region/service/aws4_request
+	scope := strings.SplitN(sig.credential, "/", 2)
+	if len(scope) != 2 {
+		return &apiError{http.StatusBadRequest, "AuthorizationHeaderMalformed", "malformed credential " + sig.credential}
+	}
+	if scope[0] != s.AccessKeyID {
+		return &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records."}
+	}
+	parts := strings.Split(scope[1], "/")
+	if len(parts) != 4 || parts[2] != "s3" || parts[3] != "aws4_request" || !strings.HasPrefix(sig.date, parts[0]) {
+		return &apiError{http.StatusBadRequest, "AuthorizationHeaderMalformed", "malformed credential scope " + scope[1]}
+	}
+	if parts[1] != s.Region {
+		return &apiError{http.StatusBadRequest, "AuthorizationHeaderMalformed", "the region '" + parts[1] + "' is wrong; expecting '" + s.Region + "'"}
+	}
+
+	signedAt, err := time.Parse(amzDateFormat, sig.date)
+	if err != nil {
+		return accessDenied("X-Amz-Date must be in the ISO8601 basic format")
+	}
+	now := s.Now()
+	if sig.presigned {
+		expires, err := strconv.Atoi(r.URL.Query().Get("X-Amz-Expires"))
+		if err != nil || expires < 1 || expires > maxExpires {
+			return &apiError{http.StatusBadRequest, "AuthorizationQueryParametersError", "X-Amz-Expires must be between 1 and 604800 seconds"}
+		}
+		if now.After(signedAt.Add(time.Duration(expires) * time.Second)) {
+			return accessDenied("Request has expired")
+		}
+		if signedAt.After(now.Add(maxClockSkew)) {
+			return accessDenied("Request is not valid yet")
+		}
+	} else if d := now.Sub(signedAt); d > maxClockSkew || d < -maxClockSkew {
+		return &apiError{http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the current time is too large."}
+	}
+
+	if sig.payloadHash != unsignedPayload && !strings.HasPrefix(sig.payloadHash, "STREAMING-") {
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != sig.payloadHash {
+			return &apiError{http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."}
+		}
+	}
+
+	canonical := strings.Join([]string{
+		r.Method,
+		escape(r.URL.Path, false),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders(r, sig.signedHeaders),
+		strings.Join(sig.signedHeaders, ";"),
+		sig.payloadHash,
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{algorithm, sig.date, scope[1], hex.EncodeToString(hashed[:])}, "\n")
+
+	key := []byte("AWS4" + s.SecretAccessKey)
+	for _, part := range parts {
+		key = hmacSHA256(key, part)
+	}
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(sig.signature)) {
+		return &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided. Check your key and signing method."}
+	}
+	return nil
+}
+
+func parseSignature(r *http.Request) (signature, *apiError) {
+	query := r.URL.Query()
+	if query.Has("X-Amz-Signature") {
+		if query.Get("X-Amz-Algorithm") != algorithm {
+			return signature{}, &apiError{http.StatusBadRequest, "AuthorizationQueryParametersError", "X-Amz-Algorithm only supports " + algorithm}
+		}
+		return signature{
+			credential:    query.Get("X-Amz-Credential"),
+			signedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+			signature:     query.Get("X-Amz-Signature"),
+			date:          query.Get("X-Amz-Date"),
+			payloadHash:   unsignedPayload,
+			presigned:     true,
+		}, nil
+	}
+
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return signature{}, accessDenied("Anonymous access is not allowed")
+	}
+	fields, ok := strings.CutPrefix(auth, algorithm+" ")
+	if !ok {
+		return signature{}, &apiError{http.StatusBadRequest, "InvalidArgument", "Unsupported Authorization Type"}
+	}
+	sig := signature{
+		date:        r.Header.Get("X-Amz-Date"),
+		payloadHash: r.Header.Get("X-Amz-Content-Sha256"),
+	}
+	for _, field := range strings.Split(fields, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch name {
+		case "Credential":
+			sig.credential = value
+		case "SignedHeaders":
+			sig.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			sig.signature = value
+		}
+	}
+	if sig.payloadHash == "" {
+		return signature{}, &apiError{http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: x-amz-content-sha256"}
+	}
+	return sig, nil
+}
+
+// canonicalQuery sorts the parameters by name and value and encodes them
+// strictly, leaving out the signature itself
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		if name == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			params = append(params, escape(name, true)+"="+escape(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaders lists the signed headers as lowercase name:value lines
+// with whitespace collapsed. Go moves Host and Content-Length out of the
+// header map, so they are taken from the request.
+func canonicalHeaders(r *http.Request, names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		var values []string
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		default:
+			values = r.Header.Values(name)
+		}
+		for i, value := range values {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+		b.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	return b.String()
+}
+
+// escape percent-encodes everything but unreserved characters, slashes are
+// kept in paths and encoded in query parameters
+func escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"backend/internal/database"
+	"backend/internal/health"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// healthCheckTimeout bounds each dependency check, probes usually time out at a few seconds
+	healthCheckTimeout = 2 * time.Second
+	// healthCacheTTL is how long a dependency report is reused, the health
+	// routes are public and each run dials postgres, S3 and SMTP
+	healthCacheTTL = 5 * time.Second
+)
+
+// newHealthChecker registers the dependencies /health/ready reports on.
+// Postgres and its schema are required to serve anything, S3 and SMTP only
+// back some features so they degrade the report instead of failing it.
+func (s *Server) newHealthChecker() *health.Checker {
+	checker := health.NewChecker(healthCheckTimeout)
+	checker.CacheFor(healthCacheTTL)
+
+	checker.Critical("postgres", func(ctx context.Context) (map[string]interface{}, error) {
+		err := s.db.Ping(ctx)
+		stats := s.db.Stats()
+		return map[string]interface{}{
+			"openConnections":   stats.OpenConnections,
+			"inUse":             stats.InUse,
+			"idle":              stats.Idle,
+			"maxOpen":           stats.MaxOpenConnections,
+			"waitCount":         stats.WaitCount,
+			"waitDurationMs":    stats.WaitDuration.Milliseconds(),
+			"maxIdleClosed":     stats.MaxIdleClosed,
+			"maxLifetimeClosed": stats.MaxLifetimeClosed,
+		}, err
+	})
+
+	checker.Critical("migrations", func(ctx context.Context) (map[string]interface{}, error) {
+		version, err := s.db.GetSchemaVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]interface{}{"version": version, "expected": database.SchemaVersion}
+		if version < database.SchemaVersion {
+			return details, fmt.Errorf("schema version %d is behind %d", version, database.SchemaVersion)
+		}
+		return details, nil
+	})
+
+	checker.Optional("s3", func(ctx context.Context) (map[string]interface{}, error) {
+		if s.s3 == nil {
+			return nil, errors.New("S3 client is not configured")
+		}
+		return map[string]interface{}{"bucket": s.config.S3.Bucket}, s.s3.Ping(ctx)
+	})
+
+	checker.Optional("smtp", func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"host":       s.config.Email.Host,
+			"queueDepth": s.emailQueue.Len(),
+		}, s.email.Ping(ctx)
+	})
+
+	return checker
+}
+
+// livenessHandler only reports that the process serves requests, it never
+// checks dependencies so an outage doesn't get every instance restarted
+func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	health.WriteReport(w, health.Report{
+		Status:    health.StatusUp,
+		CheckedAt: time.Now().UTC(),
+		Checks: map[string]health.Result{
+			"process": {
+				Status:  health.StatusUp,
+				Details: map[string]interface{}{"uptimeSeconds": int(time.Since(s.startedAt).Seconds())},
+			},
+		},
+	})
+}
+
+// readinessHandler responds 503 while a critical dependency is down or the
+// process isn't ready. Only callers with METRICS_TOKEN get the checks, others
+// the overall status.
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := s.readiness(r.Context())
+	if !s.hasMetricsToken(r) {
+		report = report.Summary()
+	}
+	health.WriteReport(w, report)
+}
+
+// legacyHealthHandler serves /health for existing monitors, the readiness
+// status without checks so it stays cheap for anyone to poll
+func (s *Server) legacyHealthHandler(w http.ResponseWriter, r *http.Request) {
+	health.WriteReport(w, s.readiness(r.Context()).Summary())
+}
+
+// readiness adds the lifecycle to the cached dependency report, it is read
+// on every request so draining shows right away
+func (s *Server) readiness(ctx context.Context) health.Report {
+	lifecycle := health.Result{Status: health.StatusUp, Critical: true}
+	if !s.lifecycle.Ready() {
+		lifecycle.Status = health.StatusDown
+		lifecycle.Error = "starting up or shutting down"
+	}
+	return s.health.Run(ctx).With("lifecycle", lifecycle)
+}
@@ -1,27 +1,42 @@
 package server
 
 import (
+	"backend/internal/auth"
+	"backend/internal/database"
 	"backend/internal/handlers"
-	"backend/internal/s3service"
+	"backend/internal/metrics"
+	"backend/internal/openapi"
+	"backend/internal/tracing"
+	"backend/internal/versioning"
+	"backend/loggers"
+	"crypto/subtle"
 	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/cors"
-	"golang.org/x/time/rate"
 )
 
-func (s *Server) RegisterRoutes() http.Handler {
+func (s *Server) RegisterRoutes() chi.Router {
 	// initalize chi router
 	r := chi.NewRouter()
 
+	// request ID and request scoped logger, first so every log line carries it
+	r.Use(loggers.RequestMiddleware)
+	// request counts and latency by route pattern
+	r.Use(metrics.Middleware)
+	// a span per request, database and S3 spans nest under it
+	r.Use(tracing.Middleware)
+
 	// enable CORS
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173"}, // React dev server
+		AllowedOrigins:   s.config.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", auth.CSRFHeader, loggers.RequestIDHeader},
+		ExposedHeaders:   []string{loggers.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not required but can be set
 	})
@@ -30,91 +45,231 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.Use(func(next http.Handler) http.Handler {
 		return c.Handler(next)
 	})
-	r.Use(middleware.Logger)
+	r.Use(s.auth.CSRFMiddleware)
 
 	// configure handler dependencies
-	s3service := s3service.NewService()
 	deps := &handlers.HandlerDependencies{
-		S3Service: s3service,
+		Config:     s.config,
+		S3Service:  s.s3,
+		Email:      s.email,
+		EmailQueue: s.emailQueue,
 	}
 
 	// public routes
 	r.Get("/", s.HelloWorldHandler)
-	r.Get("/health", s.healthHandler)
+	// kept for existing monitors, the status of /health/ready without checks
+	r.Get("/health", s.legacyHealthHandler)
+	// liveness: the process is up, restart it when this fails
+	r.Get("/health/live", s.livenessHandler)
+	// readiness: send traffic only while this succeeds, METRICS_TOKEN gets every dependency
+	r.Get("/health/ready", s.readinessHandler)
+	// prometheus scrape endpoint, requires METRICS_TOKEN as bearer token when set
+	r.Get("/metrics", s.metricsHandler)
 
 	// authentication Routes
 	r.Route("/auth", func(r chi.Router) {
+		// consent screen of the dev provider, 404 unless enabled
+		r.Get("/dev/authorize", s.auth.DevAuthorizeHandler())
 		r.Get("/{provider}/callback", s.auth.GetAuthCallbackHandler())
 		r.Get("/logout/{provider}", s.auth.LogoutHandler())
 		r.Get("/{provider}", s.auth.BeginAuthHandler())
 
 		// session related routes
 		r.Get("/session-info", s.auth.SessionInfoHandler())
+		r.Get("/csrf-token", s.auth.CSRFTokenHandler())
+
+		// invitation links sent by email, redirects to the OAuth flow
+		r.Get("/invitations/accept", s.auth.AcceptInvitationHandler())
+
+		// link another login account to the signed in admin
+		r.With(s.auth.AuthMiddleware).Get("/identities/link/{provider}", s.auth.LinkIdentityHandler())
 	})
 
 	// api routes
 	r.Route("/api", func(r chi.Router) {
-		// admin routes
-		r.Route("/admins", func(r chi.Router) {
-			// TODO: add auth middleware after testing
-			r.With().Post("/", handlers.CreateAdminHandler(s.db))
-			// optional query params: page, pageSize
-			r.With().Get("/", handlers.GetAllAdminsHandler(s.db.GetAllAdmins, s.db))
-			r.With().Get("/except-founder", handlers.GetAllAdminsHandler(s.db.GetAllAdminsExceptFounder, s.db))
-			// param -> id or email, usage: pass in id or email as param
-			r.With().Get("/{param}", handlers.GetAdminHandler(s.db))
-			r.With().Get("/count", handlers.GetAdminCountHandler(s.db))
-
-			r.With().Put("/{id}", handlers.UpdateAdminHandler(s.db))
-
-			r.With().Delete("/{param}", handlers.DeleteAdminHandler(s.db)) //TODO implement
-
-			// //r.With().Post("/associate-with-event", handlers.AssociateAdminWithEventHandler(s.db))
-			// r.With().Delete("/delete-by-id/{adminID}", handlers.DeleteAdminByIDHandler(s.db))
-			// r.With().Delete("/delete-by-email/{adminEmail}", handlers.DeleteAdminByEmailHandler(s.db))
-			//r.With().Post("/update", handlers.UpdateAdminHandler(s.db))
+		// OpenAPI specification of every /api/v1 route, keep it in sync
+		r.Get("/openapi.json", openapi.Handler())
+		r.Get("/docs", openapi.DocsHandler())
+
+		// the current version, new routes go here
+		r.Route("/v1", func(r chi.Router) {
+			s.registerAPIV1(r, deps)
+		})
+
+		// the routes from before versioning, kept until unversionedAPI.Sunset.
+		// Links in sent newsletters and subscribed calendars point here, keep
+		// /newsletter/unsubscribe and /calendar/private when removing them.
+		r.Group(func(r chi.Router) {
+			r.Use(unversionedAPI.Middleware)
+			s.registerAPIV1(r, deps)
 		})
+	})
+
+	return r
+}
 
-		// event routes
-		r.Route("/events", func(r chi.Router) {
-			// todo add auth middleware after testing
-			// admin only functions
-			//r.With().Post("/update/{eventID}", handlers.UpdateEventByIDHandler(s.db))
-			r.With().Post("/create", handlers.CreateEventHandler(s.db))
+// unversionedAPI deprecates /api/... in favor of /api/v1/...
+var unversionedAPI = versioning.Deprecation{
+	Since:     time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	Sunset:    time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+	Successor: versioning.ReplacePrefix("/api/", "/api/v1/"),
+}
+
+// registerAPIV1 registers the routes of /api/v1. A breaking change to a route
+// goes into a new version, register it with the old one marked deprecated.
+func (s *Server) registerAPIV1(r chi.Router, deps *handlers.HandlerDependencies) {
+	// admin routes
+	r.Route("/admins", func(r chi.Router) {
+		// admins are added by the founder, usually through an invitation
+		r.With(s.auth.AuthMiddleware, s.auth.FounderMiddleware).Post("/", handlers.CreateAdminHandler(s.db))
+		// optional query params: page, pageSize, deleted (exclude, include, only),
+		// deleted admins are only listed to signed in admins
+		r.With(middleware.Maybe(s.auth.AuthMiddleware, handlers.ListsDeletedAdmins)).Get("/", handlers.ListAdminsHandler(s.db))
+		r.With().Get("/except-founder", handlers.GetAllAdminsHandler(s.db, database.AdminsExceptFounder))
+		// param -> id or email, usage: pass in id or email as param
+		r.With().Get("/{param}", handlers.GetAdminHandler(s.db))
+		r.With().Get("/count", handlers.GetAdminCountHandler(s.db))
+
+		r.With(s.auth.AuthMiddleware).Put("/{id}", handlers.UpdateAdminHandler(s.db))
 
-			// public event functions
-			//r.Get("/get-authors/{eventID}", handlers.GetAuthorsByEventID(s.db))
-			//r.Get("/get/{eventID}", handlers.GetEventByIDHandler(s.db))
-			//r.Get("/get-last-seven", handlers.GetLastSevenPublishedEventsHandler(s.db))
+		// optional query params: reassign_to
+		r.With(s.auth.AuthMiddleware).Delete("/{param}", handlers.DeleteAdminHandler(s.db))
+		r.With(s.auth.AuthMiddleware).Post("/{param}/restore", handlers.RestoreAdminHandler(s.db))
+
+		// login accounts of the signed in admin
+		r.Route("/me/identities", func(r chi.Router) {
+			r.Use(s.auth.AuthMiddleware)
+			r.Get("/", handlers.GetMyIdentitiesHandler(s.db))
+			r.Delete("/{provider}/{providerUserID}", handlers.UnlinkMyIdentityHandler(s.db))
+		})
 
-			//event s3 routes for images
-			//r.Post("/upload/{event}/{file}", deps.DevGetPresignedUploadURLHandler())
+		// personal access tokens of the signed in admin, for scripts
+		r.Route("/me/tokens", func(r chi.Router) {
+			r.Use(s.auth.AuthMiddleware)
+			r.Post("/", handlers.CreateAPITokenHandler(s.db))
+			r.Get("/", handlers.GetAPITokensHandler(s.db))
+			r.Delete("/{id}", handlers.RevokeAPITokenHandler(s.db))
 		})
 
-		// media photo routes
-		r.Route("/photoshoots", func(r chi.Router) {
-			r.Get("/years", deps.GetPhotoshootYearsHandler())
-			r.Get("/events/{year}", deps.GetPhotoshootEventsHandler())
-			r.Get("/list/{year}/{event}", deps.ListPhotoshootPhotosHandler())
-			r.Get("/photos/{year}/{event}", deps.GetPhotshootPhotosHandler())
+		// invitations, only the founder can invite admins
+		r.Route("/invitations", func(r chi.Router) {
+			r.Use(s.auth.AuthMiddleware, s.auth.FounderMiddleware)
+			r.Post("/", deps.CreateAdminInvitationHandler(s.db))
+			// optional query params: status
+			r.Get("/", handlers.GetAdminInvitationsHandler(s.db))
+			r.Delete("/{id}", handlers.RevokeAdminInvitationHandler(s.db))
 		})
 
-		// email routes
-		r.With(RateLimitMiddleware).Post("/send-email", handlers.ContactFormSubmissionHandler())
+		// //r.With().Post("/associate-with-event", handlers.AssociateAdminWithEventHandler(s.db))
+		//r.With().Post("/update", handlers.UpdateAdminHandler(s.db))
 	})
 
-	return r
-}
+	// event routes
+	r.Route("/events", func(r chi.Router) {
+		// todo add auth middleware after testing
+		// admin only functions
+		//r.With().Post("/update/{eventID}", handlers.UpdateEventByIDHandler(s.db))
+		r.With().Post("/create", handlers.CreateEventHandler(s.db))
+		r.With(s.auth.AuthMiddleware, s.limiter.Limit("newsletter-send")).Post("/{id}/newsletter", deps.SendEventNewsletterHandler(s.db))
+
+		// public event functions
+		//r.Get("/get-authors/{eventID}", handlers.GetAuthorsByEventID(s.db))
+		//r.Get("/get/{eventID}", handlers.GetEventByIDHandler(s.db))
+		//r.Get("/get-last-seven", handlers.GetLastSevenPublishedEventsHandler(s.db))
+
+		//event s3 routes for images
+		//r.Post("/upload/{event}/{file}", deps.DevGetPresignedUploadURLHandler())
+	})
 
-var limiter = rate.NewLimiter(1, 2)
+	// member roster routes
+	r.Route("/members", func(r chi.Router) {
+		// public team page
+		r.Get("/", handlers.GetTeamHandler(s.db))
+		r.Get("/{id}", handlers.GetTeamMemberHandler(s.db))
 
-func RateLimitMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !limiter.Allow() {
-			http.Error(w, "Too many requests", http.StatusTooManyRequests)
-			return
-		}
-		next.ServeHTTP(w, r)
+		// admin only
+		r.Group(func(r chi.Router) {
+			r.Use(s.auth.AuthMiddleware)
+			r.Get("/all", handlers.GetAllMembersHandler(s.db))
+			r.Post("/", handlers.CreateMemberHandler(s.db))
+			r.Put("/{id}", handlers.UpdateMemberHandler(s.db))
+			r.Delete("/{id}", handlers.DeleteMemberHandler(s.db))
+		})
+	})
+
+	// performance routes, admin only
+	r.Route("/performances", func(r chi.Router) {
+		r.Use(s.auth.AuthMiddleware)
+		r.Post("/", handlers.CreatePerformanceHandler(s.db))
+		// optional query params: from, to
+		r.Get("/", handlers.GetPerformancesHandler(s.db))
+		r.Get("/{id}", handlers.GetPerformanceHandler(s.db))
+		r.Put("/{id}", handlers.UpdatePerformanceHandler(s.db))
+		r.Delete("/{id}", handlers.DeletePerformanceHandler(s.db))
+	})
+
+	// practice and attendance routes, admin only
+	r.Route("/practices", func(r chi.Router) {
+		r.Use(s.auth.AuthMiddleware)
+		r.Post("/", handlers.CreatePracticeSessionHandler(s.db))
+		// optional query params: from, to
+		r.Get("/", handlers.GetPracticeSessionsHandler(s.db))
+		// optional query params: from, to, format=csv
+		r.Get("/attendance-report", handlers.GetAttendanceReportHandler(s.db))
+		r.Get("/{id}", handlers.GetPracticeSessionHandler(s.db))
+		r.Put("/{id}", handlers.UpdatePracticeSessionHandler(s.db))
+		r.Delete("/{id}", handlers.DeletePracticeSessionHandler(s.db))
+		r.Put("/{id}/attendance", handlers.RecordAttendanceHandler(s.db))
+	})
+
+	// equipment and costume inventory routes, admin only
+	r.Route("/inventory", func(r chi.Router) {
+		r.Use(s.auth.AuthMiddleware)
+		r.Post("/", handlers.CreateInventoryItemHandler(s.db))
+		// optional query params: category
+		r.Get("/", handlers.GetInventoryItemsHandler(s.db))
+		r.Get("/overdue", handlers.GetOverdueCheckoutsHandler(s.db))
+		r.Get("/{id}", handlers.GetInventoryItemHandler(s.db))
+		r.Put("/{id}", handlers.UpdateInventoryItemHandler(s.db))
+		r.Delete("/{id}", handlers.DeleteInventoryItemHandler(s.db))
+
+		r.Post("/{id}/checkout", handlers.CheckOutItemHandler(s.db))
+		r.Post("/{id}/checkin", handlers.CheckInItemHandler(s.db))
+		r.Get("/{id}/checkouts", handlers.GetItemCheckoutsHandler(s.db))
+
+		// required query params: file. Post returns the upload url, put
+		// records the photo on the item once the upload finished
+		r.Post("/{id}/photo", deps.GetInventoryPhotoUploadURLHandler(s.db))
+		r.Put("/{id}/photo", deps.ConfirmInventoryPhotoHandler(s.db))
+		r.Get("/{id}/photo", deps.GetInventoryPhotoHandler(s.db))
+	})
+
+	// iCalendar feeds for phone/desktop calendar subscriptions
+	r.Route("/calendar", func(r chi.Router) {
+		r.Get("/public.ics", handlers.PublicCalendarFeedHandler(s.db))
+		// {token} includes the .ics extension, see PrivateCalendarFeedHandler
+		r.Get("/private/{token}", deps.PrivateCalendarFeedHandler(s.db))
+		r.With(s.auth.AuthMiddleware).Get("/feed-url", deps.CalendarFeedURLHandler())
+	})
+
+	// media photo routes
+	r.Route("/photoshoots", func(r chi.Router) {
+		r.Get("/years", deps.GetPhotoshootYearsHandler())
+		r.Get("/events/{year}", deps.GetPhotoshootEventsHandler())
+		r.Get("/list/{year}/{event}", deps.ListPhotoshootPhotosHandler())
+		r.Get("/photos/{year}/{event}", deps.GetPhotshootPhotosHandler())
+	})
+
+	// email routes, rate limited per client IP
+	r.With(s.limiter.Limit("contact")).Post("/send-email", deps.ContactFormSubmissionHandler())
+
+	// newsletter subscription routes
+	r.Route("/newsletter", func(r chi.Router) {
+		r.With(s.limiter.Limit("subscribe")).Post("/subscribe", deps.SubscribeHandler(s.db))
+		r.Get("/confirm", deps.ConfirmSubscriptionHandler(s.db))
+		// the link in newsletters asks to confirm, the post unsubscribes
+		r.Get("/unsubscribe", deps.UnsubscribePageHandler())
+		r.Post("/unsubscribe", deps.UnsubscribeHandler(s.db))
 	})
 }
 
@@ -130,7 +285,17 @@ func (s *Server) HelloWorldHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(jsonResp)
 }
 
-func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	jsonResp, _ := json.Marshal(s.db.Health())
-	_, _ = w.Write(jsonResp)
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.config.MetricsToken != "" && !s.hasMetricsToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	metrics.Handler().ServeHTTP(w, r)
+}
+
+// hasMetricsToken reports whether r carries METRICS_TOKEN as bearer token,
+// never true while it isn't set
+func (s *Server) hasMetricsToken(r *http.Request) bool {
+	token := s.config.MetricsToken
+	return token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
 }
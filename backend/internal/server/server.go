@@ -1,80 +1,91 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"strconv"
 	"time"
 
 	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/email"
+	"backend/internal/health"
+	"backend/internal/lifecycle"
+	"backend/internal/metrics"
+	"backend/internal/ratelimit"
 	"backend/internal/s3service"
 	"backend/loggers"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	_ "github.com/joho/godotenv/autoload"
+	"github.com/go-chi/chi/v5"
 )
 
+// emailQueueSize bounds the email jobs waiting to run, enqueueing fails past it
+const emailQueueSize = 100
+
 type Server struct {
 	port     int
+	config   *config.Config
 	db       database.Service
 	s3Client *s3.Client
 	auth     auth.Service
+	email    email.Service
+	s3       s3service.Service
+	limiter  *ratelimit.Limiter
+
+	lifecycle  *lifecycle.Manager
+	health     *health.Checker
+	startedAt  time.Time
+	emailQueue *email.Queue
 }
 
-func NewServer() *http.Server {
+// NewServer wires the services together. Their background workers and
+// shutdown hooks are registered with lc, the caller runs the returned server.
+func NewServer(cfg *config.Config, lc *lifecycle.Manager) *http.Server {
 
 	loggers.Info.Println("Starting server...")
 
-	port, err := strconv.Atoi(os.Getenv("PORT"))
-	if err != nil {
-		// no port provided, default to 8080
-		loggers.Info.Println("No port provided, defaulting to 8080")
-		port = 8080
-	}
-
 	// =========== Server requirements =========== //
 
 	loggers.Info.Println("Initializing S3 client...")
-	s3Client, err := newS3Client()
+	s3Client, err := newS3Client(cfg.S3)
 	if err != nil {
 		loggers.Error.Fatalf("failed to create S3 client: %v", err)
 	}
 
 	loggers.Info.Println("Initializing database...")
-	dbClient := database.New(nil) // Error is handled in database.New
-
-	loggers.Info.Println("Initializing auth service...")
-	authConfig, err := auth.LoadAuthConfig(dbClient)
+	db, err := database.Connect(cfg.Database)
 	if err != nil {
-		loggers.Error.Fatalf("Error creating auth config: %v", err)
+		loggers.Error.Fatalf("%v", err)
 	}
-	authService := auth.NewAuth(authConfig)
+	dbClient := database.New(db)
+	metrics.RegisterDB(db, cfg.Database.Name)
+	// registered first so it closes last, after requests and workers are done
+	lc.OnShutdown("database", func(ctx context.Context) error { return dbClient.Close() })
 
-	// =========== Server setup =========== //
-	NewServer := &Server{
-		port:     port,
-		db:       dbClient,
-		s3Client: s3Client,
-		auth:     authService,
-	}
+	loggers.Info.Println("Initializing auth service...")
+	authService := auth.NewAuth(auth.NewAuthConfig(cfg, dbClient))
+
+	loggers.Info.Println("Starting email queue...")
+	emailQueue := email.NewQueue(emailQueueSize)
+	lc.Go("email queue", emailQueue.Run)
+	metrics.RegisterEmailQueue(emailQueue.Len)
+	lc.OnShutdown("email queue", emailQueue.Close)
+
+	loggers.Info.Printf("Initializing %s rate limit store...", cfg.RateLimit.Store)
+	limiter := ratelimit.New(cfg.RateLimit, newRateLimitStore(cfg.RateLimit, dbClient, lc))
 
-	// seed the database
-	// if os.Getenv("ENV") == "dev" {
-	// 	// check if the database has already been seeded
-	// 	count, err := NewServer.db.GetAdminCount()
-	// 	if err != nil {
-	// 		loggers.Error.Fatalf("Error getting admin count: %v", err)
-	// 	}
-	// 	if count == 1 {
-	// 		loggers.Info.Println("Seeding the database...")
-	// 		err = misc.Seed(NewServer.db, 10, 30, 10)
-	// 		if err != nil {
-	// 			loggers.Error.Fatalf("Error seeding the database: %v", err)
-	// 		}
-	// 	}
-	// }
+	// =========== Server setup =========== //
+	NewServer := newServer(cfg, Services{
+		DB:         dbClient,
+		Auth:       authService,
+		Email:      email.NewService(cfg.Email),
+		S3:         s3service.NewService(cfg.S3),
+		EmailQueue: emailQueue,
+		Limiter:    limiter,
+	}, lc)
+	NewServer.s3Client = s3Client
 
 	loggers.Info.Println("Registering routes...")
 	// declare Server config
@@ -86,14 +97,59 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
-	loggers.Info.Println("Server is ready to handle requests at", server.Addr)
+	loggers.Info.Println("Server will handle requests at", server.Addr)
 	return server
 }
 
-func newS3Client() (*s3.Client, error) {
-	cfg, err := s3service.NewAWSConfig()
+// Services are the dependencies of the routes, NewServer connects them
+type Services struct {
+	DB         database.Service
+	Auth       auth.Service
+	Email      email.Service
+	S3         s3service.Service
+	EmailQueue *email.Queue
+	Limiter    *ratelimit.Limiter
+}
+
+func newServer(cfg *config.Config, svc Services, lc *lifecycle.Manager) *Server {
+	s := &Server{
+		port:    cfg.Port,
+		config:  cfg,
+		db:      svc.DB,
+		auth:    svc.Auth,
+		email:   svc.Email,
+		s3:      svc.S3,
+		limiter: svc.Limiter,
+
+		lifecycle:  lc,
+		emailQueue: svc.EmailQueue,
+		startedAt:  time.Now(),
+	}
+	s.health = s.newHealthChecker()
+	return s
+}
+
+// NewRouter returns the routes NewServer serves without connecting or
+// starting anything, tests use it to check every route against the spec
+func NewRouter(cfg *config.Config, svc Services, lc *lifecycle.Manager) chi.Router {
+	return newServer(cfg, svc, lc).RegisterRoutes()
+}
+
+func newS3Client(s3cfg config.S3) (*s3.Client, error) {
+	client, err := s3service.NewClient(s3cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %v", err)
 	}
-	return s3.NewFromConfig(cfg), nil
+	return client, nil
+}
+
+// newRateLimitStore shares limits through postgres when instances run behind a
+// load balancer, idle buckets are then cleaned up in the background
+func newRateLimitStore(cfg config.RateLimit, db database.Service, lc *lifecycle.Manager) ratelimit.Store {
+	if cfg.Store == "postgres" {
+		store := ratelimit.NewPostgresStore(db, cfg)
+		lc.Go("rate limit cleanup", store.Run)
+		return store
+	}
+	return ratelimit.NewMemoryStore(cfg.MaxKeys)
 }
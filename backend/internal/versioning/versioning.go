@@ -0,0 +1,75 @@
+// Package versioning marks API routes deprecated so clients can move to a
+// newer version at their own pace. Deprecated routes keep working and
+// announce their replacement and removal date with standard headers.
+package versioning
+
+import (
+	"backend/internal/metrics"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Deprecation describes routes kept for clients that haven't migrated yet
+type Deprecation struct {
+	// Since is when the routes were deprecated, sent as the Deprecation header (RFC 9745)
+	Since time.Time
+	// Sunset is when the routes will be removed, sent as the Sunset header (RFC 8594)
+	Sunset time.Time
+	// Successor returns the path replacing the requested one, optional
+	Successor func(r *http.Request) string
+}
+
+// Middleware adds the deprecation headers to every response of the routes it
+// wraps and counts their use, so the routes can be removed once unused
+func (d Deprecation) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Deprecation", fmt.Sprintf("@%d", d.Since.Unix()))
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		if d.Successor != nil {
+			if successor := d.Successor(r); successor != "" {
+				h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+		// counted after routing so the route pattern is known
+		metrics.DeprecatedRequest(r)
+	})
+}
+
+// ReplacePrefix returns a Successor moving requests under prefix to
+// replacement, e.g. ReplacePrefix("/api/", "/api/v1/")
+func ReplacePrefix(prefix, replacement string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			return ""
+		}
+		return replacement + rest
+	}
+}
+
+// TrimVersion removes a leading version segment like v1/ from path
+func TrimVersion(path string) string {
+	segment, rest, ok := strings.Cut(path, "/")
+	if ok && IsVersion(segment) {
+		return rest
+	}
+	return path
+}
+
+// IsVersion reports whether segment names an API version, v1, v2 and so on
+func IsVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, c := range segment[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
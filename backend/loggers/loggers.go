@@ -1,18 +1,25 @@
 package loggers
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"runtime"
-	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// CustomLogger keeps the printf style API of the original loggers on top of
+// log/slog. Every line becomes a structured record at the logger's level,
+// bound to a request context with Ctx it also carries the request ID, route
+// and admin ID.
 type CustomLogger struct {
-	*log.Logger
-	prefix string
+	level    slog.Level
+	category string
+	ctx      context.Context
 }
 
 var (
@@ -22,75 +29,130 @@ var (
 	Performance *CustomLogger
 )
 
-func NewCustomLogger(out *os.File, prefix string, flag int) *CustomLogger {
-	return &CustomLogger{
-		Logger: log.New(out, "", flag),
-		prefix: prefix,
+// Options configures the process logger
+type Options struct {
+	// Level is debug, info, warn or error
+	Level slog.Level
+	// Format is json or logfmt
+	Format string
+	// Zone timestamps are written in, UTC when nil
+	Zone *time.Location
+}
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	Info = &CustomLogger{level: slog.LevelInfo}
+	Error = &CustomLogger{level: slog.LevelError}
+	Debug = &CustomLogger{level: slog.LevelDebug}
+	Performance = &CustomLogger{level: slog.LevelDebug, category: "performance"}
+
+	Setup(os.Stderr, Options{Level: slog.LevelInfo, Format: "logfmt"})
+}
+
+// Setup replaces the process logger, call it once the configuration is loaded
+func Setup(out io.Writer, opts Options) {
+	zone := opts.Zone
+	if zone == nil {
+		zone = time.UTC
 	}
+
+	handlerOpts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     opts.Level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case len(groups) > 0:
+			case a.Key == slog.TimeKey:
+				a.Value = slog.TimeValue(a.Value.Time().In(zone))
+			case a.Key == slog.SourceKey:
+				// file:line is enough, full paths only add noise
+				if source, ok := a.Value.Any().(*slog.Source); ok {
+					a.Value = slog.StringValue(fmt.Sprintf("%s:%d", shortFile(source.File), source.Line))
+				}
+			}
+			return a
+		},
+	}
+
+	var handler slog.Handler
+	if opts.Format == "json" {
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(out, handlerOpts)
+	}
+	l := slog.New(&contextHandler{Handler: handler})
+	logger.Store(l)
+	slog.SetDefault(l)
 }
 
-func (l *CustomLogger) Output(calldepth int, s string) error {
-	return l.Logger.Output(calldepth+1, fmt.Sprintf("%s %s %s", getESTTime(), l.prefix, s))
+// Logger returns the structured logger for new code that logs key value
+// pairs, its *Context methods add the request attributes of RequestMiddleware
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// Ctx binds the logger to a request context
+func (l *CustomLogger) Ctx(ctx context.Context) *CustomLogger {
+	bound := *l
+	bound.ctx = ctx
+	return &bound
+}
+
+func (l *CustomLogger) log(format string, v ...interface{}) {
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	handler := logger.Load().Handler()
+	if !handler.Enabled(ctx, l.level) {
+		return
+	}
+
+	// skip runtime.Callers, log and the exported method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	msg := fmt.Sprintf(format, v...)
+	record := slog.NewRecord(time.Now(), l.level, strings.TrimSuffix(msg, "\n"), pcs[0])
+	if l.category != "" {
+		record.AddAttrs(slog.String("category", l.category))
+	}
+	handler.Handle(ctx, record)
 }
 
-// Overridden methods
 func (l *CustomLogger) Printf(format string, v ...interface{}) {
-	l.Output(2, fmt.Sprintf(format, v...))
+	l.log(format, v...)
 }
 
 func (l *CustomLogger) Print(v ...interface{}) {
-	l.Output(2, fmt.Sprint(v...))
+	l.log("%s", fmt.Sprint(v...))
 }
 
 func (l *CustomLogger) Println(v ...interface{}) {
-	l.Output(2, fmt.Sprintln(v...))
+	l.log("%s", fmt.Sprintln(v...))
 }
 
 func (l *CustomLogger) Fatalf(format string, v ...interface{}) {
-	l.Output(4, fmt.Sprintf(format, v...))
+	l.log(format, v...)
 	os.Exit(1)
 }
 
 func (l *CustomLogger) Fatal(v ...interface{}) {
-	l.Output(4, fmt.Sprint(v...))
+	l.log("%s", fmt.Sprint(v...))
 	os.Exit(1)
 }
 
 func (l *CustomLogger) Errorf(format string, v ...interface{}) {
-	l.Output(4, fmt.Sprintf(format, v...))
+	l.log(format, v...)
 }
 
 func (l *CustomLogger) Error(v ...interface{}) {
-	l.Output(4, fmt.Sprint(v...))
-}
-
-func init() {
-	logFormat := log.Lmsgprefix
-
-	Info = NewCustomLogger(os.Stdout, "| [INFO]: ", logFormat)
-	Error = NewCustomLogger(os.Stderr, "| [ERROR]: ", logFormat|log.Lshortfile)
-	Debug = NewCustomLogger(os.Stdout, "| [DEBUG]: ", logFormat)
-	Performance = NewCustomLogger(os.Stdout, "| [PERFORMANCE]: ", logFormat)
-}
-
-func getESTTime() string {
-	loc, err := time.LoadLocation("America/New_York")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return time.Now().In(loc).Format("2006-01-02 15:04:05 MST")
+	l.log("%s", fmt.Sprint(v...))
 }
 
-func getCallerInfo() string {
-	_, file, line, ok := runtime.Caller(2)
-	if !ok {
-		return "???"
+func shortFile(file string) string {
+	if i := strings.LastIndex(file, "/"); i >= 0 {
+		return file[i+1:]
 	}
-
-	shortFile := file
-	if lastIndex := strings.LastIndex(file, "/"); lastIndex > -1 {
-		shortFile = file[lastIndex+1:]
-	}
-
-	return shortFile + ":" + strconv.Itoa(line) + ": "
+	return file
 }
@@ -0,0 +1,128 @@
+package loggers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is read from trusted proxies and echoed on every response
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+// requestInfo is shared by every log line of a request, the admin is only
+// known once authentication ran further down the chain
+type requestInfo struct {
+	id     string
+	method string
+	path   string
+
+	mu      sync.Mutex
+	adminID string
+}
+
+// RequestMiddleware assigns every request an ID, from the X-Request-ID header
+// when it's sane, and logs the request once it was served
+func RequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		info := &requestInfo{id: id, method: r.Method, path: r.URL.Path}
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, info))
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		Logger().LogAttrs(r.Context(), level, "request",
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// SetAdminID adds the authenticated admin to the log lines of the request
+func SetAdminID(ctx context.Context, adminID string) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.mu.Lock()
+		info.adminID = adminID
+		info.mu.Unlock()
+	}
+}
+
+// RequestID returns the ID RequestMiddleware assigned, empty outside a request
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// contextHandler adds the request attributes found in the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		record.AddAttrs(
+			slog.String("request_id", info.id),
+			slog.String("method", info.method),
+			slog.String("path", info.path),
+		)
+		// the pattern is complete once routing reached the handler
+		if rctx := chi.RouteContext(ctx); rctx != nil {
+			if route := rctx.RoutePattern(); route != "" {
+				record.AddAttrs(slog.String("route", route))
+			}
+		}
+		info.mu.Lock()
+		adminID := info.adminID
+		info.mu.Unlock()
+		if adminID != "" {
+			record.AddAttrs(slog.String("admin_id", adminID))
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,164 @@
+package misc
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Fixtures is a generated data set. It's written to JSON so tests can load
+// the exact data a developer seeded, see testdata/fixtures.json.
+type Fixtures struct {
+	Seed   int64          `json:"seed"`
+	Admins []models.Admin `json:"admins"` // ids are assigned when the admins are created
+	Events []Event        `json:"events"`
+	// Photoshoots are the object keys of the photos, photoshoots/<year>/<event>/<photo>
+	Photoshoots []string `json:"photoshoots"`
+}
+
+// Event is an event with its authors, the first one created it
+type Event struct {
+	models.Event
+	AuthorEmails []string `json:"author_emails"`
+}
+
+// Storage is the part of the S3 client seeding uses, *s3.Client implements it
+type Storage interface {
+	s3.ListObjectsV2APIClient
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// LoadFixtures reads fixtures written by WriteFile
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// WriteFile writes the fixtures as indented JSON
+func (f *Fixtures) WriteFile(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Apply creates the admins, then the events with their images and authors.
+// Authors are looked up by email, so the founder and admins created earlier
+// can be named.
+func (f *Fixtures) Apply(ctx context.Context, repos database.Repositories) error {
+	ids := map[string]string{}
+	for _, admin := range f.Admins {
+		id, err := repos.Admins.CreateAdmin(ctx, admin)
+		if err != nil {
+			return fmt.Errorf("creating admin %s: %w", admin.Email, err)
+		}
+		ids[admin.Email] = id
+	}
+
+	for _, event := range f.Events {
+		var authorIDs []string
+		for _, email := range event.AuthorEmails {
+			id, ok := ids[email]
+			if !ok {
+				admin, err := repos.Admins.GetAdmin(ctx, "email", email)
+				if err != nil {
+					return fmt.Errorf("author %s of %s: %w", email, event.Slug, err)
+				}
+				id, ids[email] = admin.ID, admin.ID
+			}
+			authorIDs = append(authorIDs, id)
+		}
+		if len(authorIDs) == 0 {
+			return fmt.Errorf("event %s has no authors", event.Slug)
+		}
+
+		if _, err := repos.Events.CreateEvent(ctx, event.Event, authorIDs[0]); err != nil {
+			return fmt.Errorf("creating event %s: %w", event.Slug, err)
+		}
+		for _, id := range authorIDs[1:] {
+			if err := repos.Authors.AddEventAuthor(ctx, event.ID, id); err != nil {
+				return fmt.Errorf("adding author to %s: %w", event.Slug, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Upload puts a placeholder photo in the bucket for every photoshoot key
+func (f *Fixtures) Upload(ctx context.Context, storage Storage, bucket string) error {
+	for _, key := range f.Photoshoots {
+		_, err := storage.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(placeholderPhoto(key)),
+			ContentType: aws.String("image/jpeg"),
+		})
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// WipePhotoshoots deletes every object under photoshoots/, it returns how
+// many were deleted
+func WipePhotoshoots(ctx context.Context, storage Storage, bucket string) (int, error) {
+	deleted := 0
+	pages := s3.NewListObjectsV2Paginator(storage, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String("photoshoots/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return deleted, err
+		}
+		for _, obj := range page.Contents {
+			if _, err := storage.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: obj.Key}); err != nil {
+				return deleted, fmt.Errorf("deleting %s: %w", *obj.Key, err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ===== internal ===== //
+
+// placeholderPhoto is a small solid JPEG, its colour derived from the key so
+// photos of a shoot are told apart in the gallery
+func placeholderPhoto(key string) []byte {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	sum := h.Sum32()
+	fill := color.RGBA{R: uint8(sum), G: uint8(sum >> 8), B: uint8(sum >> 16), A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = fill.R, fill.G, fill.B, fill.A
+	}
+	var buf bytes.Buffer
+	// encoding to memory can't fail
+	jpeg.Encode(&buf, img, nil)
+	return buf.Bytes()
+}
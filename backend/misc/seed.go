@@ -1,109 +1,167 @@
 package misc
 
 import (
-	"backend/internal/database"
 	"backend/internal/models"
-	"context"
+	"fmt"
 	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
-func Seed(dbService database.Service, numAdmins, numEvents, maxImagesPerEvent int) error {
+// Options sizes a generated data set, the same options always generate the
+// same data
+type Options struct {
+	Seed              int64 // seeds the random source
+	Admins            int   // admins besides the founder
+	Events            int
+	MaxImagesPerEvent int
+	Photoshoots       int // photoshoot folders, spread over the years
+	MaxPhotosPerShoot int
+}
 
-	// Generate and insert Admins
-	var adminIDs []string
-	for i := 0; i < numAdmins; i++ {
-		admin := createRandomAdmin()
-		adminID, err := dbService.CreateAdmin(context.Background(), admin)
-		if err != nil {
-			return err
-		}
-		adminIDs = append(adminIDs, adminID)
+// DefaultOptions is a data set large enough to page through in the frontend
+func DefaultOptions() Options {
+	return Options{
+		Seed:              1,
+		Admins:            10,
+		Events:            30,
+		MaxImagesPerEvent: 6,
+		Photoshoots:       12,
+		MaxPhotosPerShoot: 8,
 	}
-
-	//TODO: Generate and insert Events
-	return nil
 }
 
-// func createRandomEventRequest(adminIDs []string, maxImagesPerEvent int) models.CreateEventRequest {
-
-// 	isDraft := rand.Intn(2) == 0 // 50% chance of being a draft
-// 	var published_at *time.Time
-
-// 	// a draft event has no published_at, otherwise it needds one
-// 	if !isDraft {
-// 		date := randomDate()
-// 		published_at = &date
-// 	}
-
-// 	return models.CreateEventRequest{
-// 		EventName:   fmt.Sprintf("Event %d", rand.Intn(100)),
-// 		Date:        randomDate(),
-// 		Description: fmt.Sprintf("Description for event %d", rand.Intn(100)),
-// 		Content:     fmt.Sprintf("Content for event %d", rand.Intn(100)),
-// 		IsDraft:     rand.Intn(2) == 0,
-// 		PublishedAt: published_at,
-// 		Images:      createRandomImages(maxImagesPerEvent),
-// 		AuthorIDs:   selectRandomAdmins(adminIDs),
-// 	}
-// }
-
-func createRandomAdmin() models.Admin {
-	return models.Admin{
-		Name:     randomString(10),
-		Email:    randomString(10) + "@example.com",
-		Position: randomString(10),
-		Status:   "active",
+// FounderEmail is the admin every database starts with, generated events may
+// name them as an author
+const FounderEmail = "jiating.lion.dragon@gmail.com"
+
+// epoch anchors every generated date, so fixtures don't change with the clock
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	firstNames = []string{"Mei", "Wei", "Jia", "Ling", "Hao", "Xin", "Yu", "Chen", "Ming", "Lan", "Kai", "Tao", "Hui", "An", "Jun", "Ying", "Bo", "Qing"}
+	lastNames  = []string{"Wong", "Chan", "Lee", "Ng", "Lau", "Cheung", "Tang", "Ho", "Lam", "Leung", "Yip", "Chow", "Fung", "Kwok", "Tsang", "Mak"}
+	positions  = []string{"President", "Vice President", "Treasurer", "Secretary", "Head Coach", "Performance Lead", "Equipment Manager", "Social Media", "Outreach", "Events Coordinator"}
+	statuses   = []string{"active", "active", "active", "active", "inactive", "hiatus"}
+
+	occasions = []string{"Lunar New Year Parade", "Mid-Autumn Festival", "Lantern Festival", "Dragon Boat Festival", "Grand Opening Blessing", "Wedding Procession", "Cultural Night", "Campus Showcase", "Spring Lion Dance Workshop", "Charity Gala"}
+	venues    = []string{"Chinatown", "City Hall Plaza", "the Student Union", "Harbourfront Centre", "the Community Centre", "Pacific Mall", "the Cultural Centre", "Riverside Park"}
+	alts      = []string{"lion dancers mid leap", "drummers keeping the beat", "the lion awakening ceremony", "crowd watching the performance", "lion eating the lettuce", "team photo after the show", "cymbals and gong section", "lion on the poles"}
+)
+
+// Generate builds a data set from opts, the founder isn't included because
+// every database already has them
+func Generate(opts Options) *Fixtures {
+	r := rand.New(rand.NewSource(opts.Seed))
+	f := &Fixtures{Seed: opts.Seed}
+
+	emails := map[string]bool{FounderEmail: true}
+	authors := []string{FounderEmail}
+	for i := 0; i < opts.Admins; i++ {
+		first, last := pick(r, firstNames), pick(r, lastNames)
+		email := strings.ToLower(first + "." + last + "@example.com")
+		for n := 2; emails[email]; n++ {
+			email = strings.ToLower(fmt.Sprintf("%s.%s%d@example.com", first, last, n))
+		}
+		emails[email] = true
+		admin := models.Admin{Name: first + " " + last, Email: email, Position: pick(r, positions), Status: pick(r, statuses)}
+		f.Admins = append(f.Admins, admin)
+		if admin.Status == "active" {
+			authors = append(authors, email)
+		}
 	}
-}
 
-func randomString(length int) string {
-	letters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+	slugs := map[string]bool{}
+	for i := 0; i < opts.Events; i++ {
+		f.Events = append(f.Events, generateEvent(r, opts, authors, slugs))
+	}
+	// newest first, like the events page
+	sort.SliceStable(f.Events, func(i, j int) bool { return f.Events[i].Date.After(f.Events[j].Date) })
+
+	for i := 0; i < opts.Photoshoots; i++ {
+		year := epoch.Year() - r.Intn(5)
+		folder := slugify(pick(r, occasions))
+		photos := 1 + r.Intn(max(opts.MaxPhotosPerShoot, 1))
+		for n := 1; n <= photos; n++ {
+			f.Photoshoots = append(f.Photoshoots, fmt.Sprintf("photoshoots/%d/%s/IMG_%04d.jpg", year, folder, n))
+		}
 	}
-	return string(b)
+	f.Photoshoots = dedupe(f.Photoshoots)
+	return f
 }
 
-// func selectRandomAdmins(adminIDs []string) []string {
-// 	rand.Shuffle(len(adminIDs), func(i, j int) {
-// 		adminIDs[i], adminIDs[j] = adminIDs[j], adminIDs[i]
-// 	})
+// ===== internal ===== //
 
-// 	numAdmins := rand.Intn(len(adminIDs)) + 1 // Ensure at least one admin is selected
-// 	return adminIDs[:numAdmins]
-// }
+func generateEvent(r *rand.Rand, opts Options, authors []string, slugs map[string]bool) Event {
+	occasion, venue := pick(r, occasions), pick(r, venues)
+	date := epoch.AddDate(0, 0, -r.Intn(5*365)).Add(time.Duration(10+r.Intn(10)) * time.Hour)
+	title := fmt.Sprintf("%s %d", occasion, date.Year())
 
-// func createRandomImages(max int) []models.EventImage {
-// 	var images []models.EventImage
-// 	numImages := rand.Intn(max + 1) // Number of images can be from 0 to max
-
-// 	for i := 0; i < numImages; i++ {
-// 		images = append(images, models.EventImage{
-// 			CreatedAt: time.Now(),
-// 			UpdatedAt: time.Now(),
-// 			ImageURL:  fmt.Sprintf("https://example.com/image%d.jpg", rand.Intn(100)),
-// 			IsDisplay: i == 0, // Make the first image the display image
-// 		})
-// 	}
-
-// 	return images
-// }
+	slug := slugify(title)
+	for n := 2; slugs[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", slugify(title), n)
+	}
+	slugs[slug] = true
+
+	event := Event{Event: models.Event{
+		ID:          newUUID(r),
+		EventTitle:  title,
+		Metatitle:   fmt.Sprintf("%s at %s | Jiating Lion Dance", occasion, venue),
+		Slug:        slug,
+		Date:        date,
+		Description: fmt.Sprintf("Jiating performs at the %s at %s.", occasion, venue),
+		Content:     fmt.Sprintf("Join us at %s on %s for drumming, lions and good fortune for the year ahead.", venue, date.Format("January 2, 2006")),
+		// most events are published, a few are still being written
+		IsDraft: r.Intn(5) == 0,
+	}}
+
+	images := r.Intn(opts.MaxImagesPerEvent + 1)
+	for n := 0; n < images; n++ {
+		event.Images = append(event.Images, models.EventImage{
+			ID:        newUUID(r),
+			CreatedAt: date.Add(time.Duration(n) * time.Minute),
+			ImageURL:  fmt.Sprintf("https://picsum.photos/seed/%s-%d/1200/800", slug, n+1),
+			AltText:   pick(r, alts),
+			IsDisplay: n == 0,
+		})
+	}
 
-// func randomDate() time.Time {
-// 	// Define the range for the random date
-// 	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) // Start date (e.g., Jan 1, 2020)
-// 	end := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC) // End date (e.g., Dec 31, 2023)
+	// the first author created the event, up to two more helped
+	order := r.Perm(len(authors))
+	for _, i := range order[:min(len(order), 1+r.Intn(3))] {
+		event.AuthorEmails = append(event.AuthorEmails, authors[i])
+	}
+	return event
+}
 
-// 	// Calculate total duration between start and end
-// 	totalDays := end.Sub(start).Hours() / 24
+func pick(r *rand.Rand, from []string) string {
+	return from[r.Intn(len(from))]
+}
 
-// 	// Generate a random number of days to add to start
-// 	daysToAdd := rand.Intn(int(totalDays))
+// newUUID draws a version 4 UUID from r rather than crypto/rand
+func newUUID(r *rand.Rand) string {
+	id, err := uuid.NewRandomFromReader(r)
+	if err != nil {
+		// reading from a math/rand source never fails
+		panic(err)
+	}
+	return id.String()
+}
 
-// 	// Add the random number of days to the start date
-// 	randomDate := start.AddDate(0, 0, daysToAdd)
+func slugify(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.NewReplacer("-", " ", "'", "").Replace(s))), "-")
+}
 
-// 	// Return the random date as a time.Time object
-// 	return randomDate
-// }
+func dedupe(keys []string) []string {
+	sort.Strings(keys)
+	out := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
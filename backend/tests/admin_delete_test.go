@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAdminForDeletion(t *testing.T, status string) (database.Service, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+		WithArgs("old@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name", "email", "position", "status"}).
+			AddRow("11111111-1111-1111-1111-111111111111", now, now, nil, "Old Admin", "old@gmail.com", "Treasurer", status))
+
+	return database.New(db), mock
+}
+
+func deleteAdminRequest(s database.Service, target string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Delete("/api/admins/{param}", handlers.DeleteAdminHandler(s))
+	req := httptest.NewRequest(http.MethodDelete, target, nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeleteAdminReassignsEvents(t *testing.T) {
+	s, mock := mockAdminForDeletion(t, "active")
+	mock.ExpectQuery("SELECT id FROM admins").
+		WithArgs("new@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("22222222-2222-2222-2222-222222222222"))
+	mock.ExpectExec("INSERT INTO event_authors").
+		WithArgs("22222222-2222-2222-2222-222222222222", "11111111-1111-1111-1111-111111111111").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM event_authors").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE admins SET").
+		WithArgs(sqlmock.AnyArg(), "11111111-1111-1111-1111-111111111111").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rec := deleteAdminRequest(s, "/api/admins/old@gmail.com?reassign_to=new@gmail.com")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAdminPreservesEvents(t *testing.T) {
+	s, mock := mockAdminForDeletion(t, "active")
+	mock.ExpectExec("UPDATE admins SET").
+		WithArgs(sqlmock.AnyArg(), "11111111-1111-1111-1111-111111111111").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rec := deleteAdminRequest(s, "/api/admins/old@gmail.com")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAdminRefusesFounder(t *testing.T) {
+	s, mock := mockAdminForDeletion(t, "permanent")
+	mock.ExpectRollback()
+
+	rec := deleteAdminRequest(s, "/api/admins/old@gmail.com")
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAdminsInvalidDeletedFilter(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admins?deleted=maybe", nil)
+	rec := httptest.NewRecorder()
+	handlers.ListAdminsHandler(database.New(db)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListDeletedAdminsRequiresSignIn(t *testing.T) {
+	r, mock := newConfiguredRouter(t)
+
+	for _, target := range []string{"/api/v1/admins?deleted=only", "/api/v1/admins?deleted=include", "/api/admins?deleted=only"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, target)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is listed")
+
+	expectTokenLookup(mock, "admins:read")
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+		WillReturnRows(sqlmock.NewRows(adminColumns).AddRow("admin-2", now, now, now, "Old Admin", "old@gmail.com", "Treasurer", "active"))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM admins WHERE deleted_at IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, tokenRequest(http.MethodGet, "/api/v1/admins?deleted=only", ""))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAdminRefusesSelf(t *testing.T) {
+	r, mock := newConfiguredRouter(t)
+	now := time.Now()
+
+	expectTokenLookup(mock, "admins:write")
+	mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+		WithArgs("admin-1").
+		WillReturnRows(sqlmock.NewRows(adminColumns).
+			AddRow("admin-1", now, now, nil, "Mei", "mei@gmail.com", "Treasurer", "active"))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, tokenRequest(http.MethodDelete, "/api/v1/admins/Mei@gmail.com", ""))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "cannot delete yourself", strings.TrimSpace(rec.Body.String()))
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is deleted")
+}
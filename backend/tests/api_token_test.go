@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"backend/internal/tokens"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIToken = "jt_test-token"
+
+func newTokenAuthRouter(t *testing.T) (http.Handler, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := database.New(db)
+	a := auth.NewAuth(&auth.AuthConfig{
+		Store:           sessions.NewCookieStore([]byte("test-session-key")),
+		DB:              s,
+		CallbackBaseURL: "http://localhost:3000",
+		Providers:       []string{"dev"},
+	})
+
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(a.AuthMiddleware)
+		writeAdminID := func(w http.ResponseWriter, r *http.Request) {
+			adminID, _ := auth.AdminIDFromContext(r.Context())
+			w.Write([]byte(adminID))
+		}
+		r.Get("/events/{id}", writeAdminID)
+		r.Get("/v1/events/{id}", writeAdminID)
+		r.Post("/events/{id}", func(w http.ResponseWriter, r *http.Request) {})
+		r.Post("/admins/me/tokens", handlers.CreateAPITokenHandler(s))
+	})
+	return r, mock
+}
+
+func expectTokenLookup(mock sqlmock.Sqlmock, scopes string) {
+	now := time.Now()
+	mock.ExpectQuery("UPDATE api_tokens t SET last_used_at").
+		WithArgs(sqlmock.AnyArg(), tokens.Hash(testAPIToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "admin_id", "name", "prefix", "scopes", "expires_at", "last_used_at"}).
+			AddRow("token-1", now, "admin-1", "import script", "jt_test-t", scopes, now.Add(time.Hour), now))
+}
+
+func bearerRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	return req
+}
+
+func TestBearerTokenWithScope(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	expectTokenLookup(mock, "events:read")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodGet, "/api/events/1"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "admin-1", rec.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBearerTokenScopeIgnoresVersion(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	expectTokenLookup(mock, "events:read")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodGet, "/api/v1/events/1"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "admin-1", rec.Body.String())
+}
+
+func TestBearerTokenWriteImpliesRead(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	expectTokenLookup(mock, "members:read,events:write")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodGet, "/api/events/1"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestBearerTokenInsufficientScope(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	expectTokenLookup(mock, "events:read")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodPost, "/api/events/1"))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+}
+
+func TestBearerTokenInvalid(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	mock.ExpectQuery("UPDATE api_tokens t SET last_used_at").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodGet, "/api/events/1"))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestBearerTokenCannotMintTokens(t *testing.T) {
+	r, mock := newTokenAuthRouter(t)
+	expectTokenLookup(mock, "admins:write")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, bearerRequest(http.MethodPost, "/api/admins/me/tokens"))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestSanitizeAPITokenInput(t *testing.T) {
+	valid := models.APIToken{Name: "import", Scopes: []string{"events:write"}, ExpiresAt: time.Now().Add(24 * time.Hour)}
+	assert.NoError(t, database.SanitizeAPITokenInput(&valid))
+
+	badScope := valid
+	badScope.Scopes = []string{"events:delete"}
+	assert.EqualError(t, database.SanitizeAPITokenInput(&badScope), "invalid scope")
+
+	tooLong := valid
+	tooLong.ExpiresAt = time.Now().Add(2 * database.MaxAPITokenLifetime)
+	assert.EqualError(t, database.SanitizeAPITokenInput(&tooLong), "invalid expiration")
+}
+
+func TestCreateAPITokenUnknownAdmin(t *testing.T) {
+	s, mock := newMockService(t)
+	token := models.APIToken{
+		AdminID: "11111111-1111-1111-1111-111111111111", Name: "import", Prefix: "jt_abcdefg",
+		Scopes: []string{"events:write"}, ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+
+	// pgx reports the admin being deleted meanwhile as a foreign key violation
+	mock.ExpectQuery("INSERT INTO api_tokens").
+		WithArgs(sqlmock.AnyArg(), token.AdminID, "import", "jt_abcdefg", "hash", "events:write", sqlmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "api_tokens_admin_id_fkey"})
+	_, err := s.CreateAPIToken(context.Background(), token, "hash")
+	assert.EqualError(t, err, "invalid admin id")
+
+	// other errors are passed on as they are
+	mock.ExpectQuery("INSERT INTO api_tokens").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	_, err = s.CreateAPIToken(context.Background(), token, "hash")
+	var pgErr *pgconn.PgError
+	assert.ErrorAs(t, err, &pgErr)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPITokenForRemovedAdmin(t *testing.T) {
+	setConfigEnv(t)
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, mock, store := newAppRouter(t, cfg)
+
+	// a signed in session that already fetched its csrf token
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admins/me/tokens", strings.NewReader(`{"name": "import", "scopes": ["events:write"]}`))
+	session, _ := store.Get(req, "session-name")
+	session.Values["userID"] = "user-1"
+	session.Values["adminID"] = "admin-1"
+	session.Values["csrfToken"] = "csrf-1"
+	saved := httptest.NewRecorder()
+	assert.NoError(t, session.Save(req, saved))
+	req.AddCookie(saved.Result().Cookies()[0])
+	req.Header.Set(auth.CSRFHeader, "csrf-1")
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("admin-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("INSERT INTO api_tokens").
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "invalid admin id", strings.TrimSpace(rec.Body.String()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAttendanceReport(t *testing.T) (database.Service, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT m.id, m.name").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "present", "absent", "excused"}).
+			AddRow("member-1", "Alice", 3, 1, 2).
+			AddRow("member-2", "Bob, Jr.", 0, 0, 1))
+
+	return database.New(db), mock
+}
+
+func TestGetAttendanceReport(t *testing.T) {
+	s, mock := mockAttendanceReport(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/practices/attendance-report?from=2024-01-01&to=2024-06-01", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetAttendanceReportHandler(s).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var report []models.AttendanceSummary
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Len(t, report, 2)
+	// excused sessions don't count against attendance
+	assert.Equal(t, 75.0, report[0].Percentage)
+	assert.Equal(t, 0.0, report[1].Percentage)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAttendanceReportCSV(t *testing.T) {
+	s, mock := mockAttendanceReport(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/practices/attendance-report?format=csv", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetAttendanceReportHandler(s).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Equal(t, []string{
+		"member_id,name,present,absent,excused,percentage",
+		"member-1,Alice,3,1,2,75.00",
+		`member-2,"Bob, Jr.",0,0,1,0.00`,
+	}, lines)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// names are stored HTML escaped and may start like a spreadsheet formula
+func TestGetAttendanceReportCSVCells(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT m.id, m.name").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "present", "absent", "excused"}).
+			AddRow("member-1", "Tom &amp; Jerry O&#39;Brien", 1, 0, 0).
+			AddRow("member-2", `=HYPERLINK("http://evil.example","x")`, 1, 0, 0).
+			AddRow("member-3", "@SUM(A1)", 1, 0, 0).
+			AddRow("member-4", "-1+2", 1, 0, 0))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/practices/attendance-report?format=csv", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetAttendanceReportHandler(database.New(db)).ServeHTTP(rec, req)
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	assert.NoError(t, err)
+	var names []string
+	for _, record := range records[1:] {
+		names = append(names, record[1])
+	}
+	assert.Equal(t, []string{
+		"Tom & Jerry O'Brien",
+		`'=HYPERLINK("http://evil.example","x")`,
+		"'@SUM(A1)",
+		"'-1+2",
+	}, names)
+}
+
+func TestGetAttendanceReportInvalidDate(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/practices/attendance-report?from=yesterday", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetAttendanceReportHandler(database.New(db)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
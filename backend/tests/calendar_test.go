@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"backend/internal/calendar"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteICS(t *testing.T) {
+	start := time.Date(2024, 2, 10, 18, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	performances := []models.Performance{
+		{
+			ID:          "perf-1",
+			Title:       "CNY Parade, Main St; lion dance",
+			Location:    "Main St",
+			Description: "Meet at 5pm\nBring drums",
+			StartTime:   start,
+			EndTime:     start.Add(2 * time.Hour),
+			IsPublic:    true,
+		},
+	}
+
+	var buf bytes.Buffer
+	err := calendar.WriteICS(&buf, "Jiating Performances", performances)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "UID:perf-1@jiating\r\n")
+	// times are converted to UTC
+	assert.Contains(t, out, "DTSTART:20240210T230000Z\r\n")
+	assert.Contains(t, out, "DTEND:20240211T010000Z\r\n")
+	// TEXT values are escaped
+	assert.Contains(t, out, `SUMMARY:CNY Parade\, Main St\; lion dance`)
+	assert.Contains(t, out, `DESCRIPTION:Meet at 5pm\nBring drums`)
+	assert.Contains(t, out, "CLASS:PUBLIC\r\n")
+}
+
+func TestWriteICSFoldsLongLines(t *testing.T) {
+	start := time.Now()
+	performances := []models.Performance{
+		{
+			ID:          "perf-2",
+			Title:       "Lunar New Year",
+			Description: strings.Repeat("舞獅", 60),
+			StartTime:   start,
+			EndTime:     start.Add(time.Hour),
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, calendar.WriteICS(&buf, "Jiating", performances))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line exceeds 75 octets: %q", line)
+	}
+	// unfolding restores the original value
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("舞獅", 60)+"\r\n")
+	assert.Contains(t, unfolded, "CLASS:PRIVATE\r\n")
+}
+
+func TestCalendarFeedToken(t *testing.T) {
+	token := calendar.FeedToken("secret", "admin-uuid")
+
+	adminID, err := calendar.ParseFeedToken("secret", token)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-uuid", adminID)
+
+	_, err = calendar.ParseFeedToken("other-secret", token)
+	assert.Error(t, err)
+
+	_, err = calendar.ParseFeedToken("", token)
+	assert.Error(t, err)
+
+	_, err = calendar.ParseFeedToken("secret", token+"x")
+	assert.Error(t, err)
+}
+
+func TestPrivateCalendarFeedURLIsServed(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("CALENDAR_FEED_SECRET", "feed-secret")
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, mock, store := newAppRouter(t, cfg)
+
+	// the signed in admin asks for their feed
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("admin-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/calendar/feed-url", nil)
+	req.AddCookie(signedInCookie(t, store))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if !assert.Equal(t, http.StatusOK, rec.Code) {
+		return
+	}
+	var body map[string]string
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	path := body["path"]
+	assert.True(t, strings.HasSuffix(path, ".ics"), path)
+
+	// the calendar app fetches it without a session, under both prefixes
+	for _, feed := range []string{path, strings.Replace(path, "/api/v1/", "/api/", 1)} {
+		now := time.Now()
+		mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+			WithArgs("admin-1").
+			WillReturnRows(sqlmock.NewRows(adminColumns).
+				AddRow("admin-1", now, now, nil, "Mei", "mei@gmail.com", "Member", "active"))
+		mock.ExpectQuery("FROM performances").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "title", "description", "location", "start_time", "end_time", "is_public"}).
+				AddRow("perf-1", now, now, "Team practice", "", "Gym", now, now.Add(time.Hour), false))
+		mock.ExpectQuery("SELECT member_id FROM performance_assignments").
+			WithArgs("perf-1").
+			WillReturnRows(sqlmock.NewRows([]string{"member_id"}))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, feed, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, feed)
+		assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "UID:perf-1@jiating\r\n")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// without the extension or with a forged token there is no feed
+	for _, feed := range []string{strings.TrimSuffix(path, ".ics"), "/api/v1/calendar/private/abc.def.ics"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, feed, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, feed)
+	}
+}
+
+func TestPerformanceRejectsMalformedMemberID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := database.New(db)
+
+	r := chi.NewRouter()
+	r.Post("/api/performances", handlers.CreatePerformanceHandler(s))
+	r.Put("/api/performances/{id}", handlers.UpdatePerformanceHandler(s))
+	body := `{"title": "Parade", "start_time": "2024-02-10T18:00:00Z", "end_time": "2024-02-10T20:00:00Z", "member_ids": ["mei"]}`
+
+	for method, target := range map[string]string{
+		http.MethodPost: "/api/performances",
+		http.MethodPut:  "/api/performances/11111111-1111-1111-1111-111111111111",
+	} {
+		rec := serve(r, method, target, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, method)
+		assert.Equal(t, "invalid member id\n", rec.Body.String(), method)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet(), "postgres isn't asked")
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+// setConfigEnv sets a minimal valid configuration, t.Setenv restores it after the test
+func setConfigEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"ENV":                        "",
+		"PORT":                       "3000",
+		"DB_HOST":                    "localhost",
+		"DB_PORT":                    "5432",
+		"DB_DATABASE":                "jiating",
+		"DB_USERNAME":                "admin",
+		"OAUTH_PROVIDERS":            "google",
+		"GOOGLE_CLIENT_ID":           "client-id",
+		"GOOGLE_CLIENT_SECRET":       "client-secret",
+		"SESSION_SECRET":             "test-session-secret-0123456789abcdef",
+		"COOKIE_SECURE":              "",
+		"COOKIE_SAMESITE":            "",
+		"FRONTEND_URL":               "",
+		"CORS_ALLOWED_ORIGINS":       "",
+		"CONFIG_FILE":                "",
+		"RATE_LIMITS":                "",
+		"RATE_LIMIT_STORE":           "",
+		"RATE_LIMIT_TRUSTED_PROXIES": "",
+		"S3_ENDPOINT":                "",
+		"SHUTDOWN_DRAIN_DELAY":       "",
+	} {
+		t.Setenv(key, value)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("FRONTEND_URL", "https://jiating.example.com/")
+
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, cfg.Port)
+	assert.Equal(t, "https://jiating.example.com", cfg.FrontendURL)
+	// CORS defaults to the frontend
+	assert.Equal(t, []string{"https://jiating.example.com"}, cfg.CORSOrigins)
+	assert.Equal(t, []string{"google"}, cfg.Auth.Providers)
+	assert.Equal(t, http.SameSiteLaxMode, cfg.Auth.CookieSameSite)
+	assert.Equal(t, 587, cfg.Email.Port)
+	assert.Equal(t, "postgres://admin:@localhost:5432/jiating?sslmode=disable", cfg.Database.DSN())
+	assert.Equal(t, 5*time.Second, cfg.ShutdownDrainDelay)
+}
+
+func TestLoadConfigDrainDelay(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("ENV", "dev")
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.ShutdownDrainDelay, "a dev server stops right away")
+
+	t.Setenv("SHUTDOWN_DRAIN_DELAY", "15s")
+	cfg, err = config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Second, cfg.ShutdownDrainDelay)
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	setConfigEnv(t)
+	os.Unsetenv("DB_HOST")
+	file := filepath.Join(t.TempDir(), "test.env")
+	assert.NoError(t, os.WriteFile(file, []byte("DB_HOST=file-host\nPORT=4000\nNEWSLETTER_SECRET=from-file\n"), 0o600))
+
+	cfg, err := config.Load([]string{"-config", file, "-port", "5000"})
+	assert.NoError(t, err)
+	assert.Equal(t, "file-host", cfg.Database.Host)    // only set in the file
+	assert.Equal(t, 5000, cfg.Port)                    // flag beats env and file
+	assert.Equal(t, "from-file", cfg.NewsletterSecret) // env is empty
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	setConfigEnv(t)
+
+	// the default .env is optional, a named file is not
+	_, err := config.Load(nil)
+	assert.NoError(t, err)
+	_, err = config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.env")})
+	assert.Error(t, err)
+}
+
+func TestLoadConfigListsEveryProblem(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("DB_HOST", "")
+	t.Setenv("OAUTH_PROVIDERS", "google,dev,github")
+	t.Setenv("GOOGLE_CLIENT_SECRET", "")
+	t.Setenv("COOKIE_SAMESITE", "none")
+	t.Setenv("FRONTEND_URL", "jiating.example.com")
+	t.Setenv("SESSION_SECRET", "")
+
+	_, err := config.Load(nil)
+	problems, ok := err.(config.Problems)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, config.Problems{
+		`PORT must be a port number, got "http"`,
+		`FRONTEND_URL must be an absolute URL, got "jiating.example.com"`,
+		`CORS_ALLOWED_ORIGINS must list absolute URLs, got "jiating.example.com"`,
+		"DB_HOST is required",
+		"the google provider requires GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET",
+		"the dev provider requires ENV=dev",
+		`OAUTH_PROVIDERS: unknown provider "github"`,
+		"COOKIE_SAMESITE=none requires COOKIE_SECURE=true",
+		"SESSION_SECRET must be at least 32 characters",
+	}, problems)
+}
+
+func TestLoadConfigSessionSecret(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("SESSION_SECRET", "too-short")
+	_, err := config.Load(nil)
+	assert.EqualError(t, err, config.Problems{"SESSION_SECRET must be at least 32 characters"}.Error())
+
+	// a dev server makes one up, sessions then end when it restarts
+	t.Setenv("ENV", "dev")
+	t.Setenv("SESSION_SECRET", "")
+	first, err := config.Load(nil)
+	assert.NoError(t, err)
+	second, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(first.Auth.SessionSecret), config.MinSessionSecretLength)
+	assert.NotEqual(t, first.Auth.SessionSecret, second.Auth.SessionSecret)
+}
+
+func TestAuthConfigSignsSessionsWithSessionSecret(t *testing.T) {
+	setConfigEnv(t)
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := auth.NewAuthConfig(cfg, nil).Store
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	session, _ := store.Get(req, "session-name")
+	session.Values["adminID"] = "admin-1"
+	assert.NoError(t, session.Save(req, rec))
+	cookie := rec.Result().Cookies()[0]
+
+	for secret, valid := range map[string]bool{
+		cfg.Auth.SessionSecret:                 true,
+		"random string for demo purposes only": false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookie)
+		session, err := sessions.NewCookieStore([]byte(secret)).Get(req, "session-name")
+		assert.Equal(t, valid, err == nil, "decoding with %q", secret)
+		assert.Equal(t, valid, session.Values["adminID"] == "admin-1")
+	}
+}
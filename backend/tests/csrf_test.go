@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCSRFRouter(t *testing.T) (http.Handler, *sessions.CookieStore) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := sessions.NewCookieStore([]byte("test-session-key"))
+	a := auth.NewAuth(&auth.AuthConfig{
+		Store:           store,
+		DB:              database.New(db),
+		CallbackBaseURL: "http://localhost:3000",
+		Providers:       []string{"dev"},
+	})
+
+	r := chi.NewRouter()
+	r.Use(a.CSRFMiddleware)
+	r.Get("/auth/csrf-token", a.CSRFTokenHandler())
+	r.Post("/api/events", func(w http.ResponseWriter, r *http.Request) {})
+	return r, store
+}
+
+// signedInCookie returns the cookie of a session that completed login
+func signedInCookie(t *testing.T, store *sessions.CookieStore) *http.Cookie {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	session, _ := store.Get(req, "session-name")
+	session.Values["userID"] = "user-1"
+	session.Values["adminID"] = "admin-1"
+	if err := session.Save(req, rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Result().Cookies()[0]
+}
+
+func TestCSRFRejectsSessionMutationWithoutToken(t *testing.T) {
+	r, store := newCSRFRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/events", nil)
+	req.AddCookie(signedInCookie(t, store))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestCSRFAcceptsIssuedToken(t *testing.T) {
+	r, store := newCSRFRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/csrf-token", nil)
+	req.AddCookie(signedInCookie(t, store))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body map[string]string
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.NotEmpty(t, body["csrfToken"])
+	// the token lives in the session, use the updated cookie
+	cookie := rec.Result().Cookies()[0]
+
+	req = httptest.NewRequest(http.MethodPost, "/api/events", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(auth.CSRFHeader, body["csrfToken"])
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/events", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(auth.CSRFHeader, "forged")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestCSRFSkipsAnonymousAndBearerRequests(t *testing.T) {
+	r, _ := newCSRFRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/events", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/events", nil)
+	req.Header.Set("Authorization", "Bearer jt_token")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"backend/internal/ctl"
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/models"
+	"backend/internal/s3service"
+	"backend/internal/s3service/s3fake"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCtl returns jiatingctl running against repos and an S3 fake
+func newCtl(t *testing.T, repos database.Repositories) (*ctl.App, *s3fake.Server, *bytes.Buffer) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	objects, err := s3service.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("creating S3 client: %v", err)
+	}
+	out := &bytes.Buffer{}
+	return &ctl.App{
+		Repos:   repos,
+		Storage: s3service.NewService(cfg),
+		Objects: objects,
+		Bucket:  testBucket,
+		Out:     out,
+		JSON:    true,
+	}, fake, out
+}
+
+// runCtl runs a command and decodes its JSON output into v
+func runCtl(t *testing.T, app *ctl.App, out *bytes.Buffer, v interface{}, args ...string) error {
+	t.Helper()
+	out.Reset()
+	err := app.Run(context.Background(), args)
+	if err == nil && v != nil {
+		assert.NoError(t, json.Unmarshal(out.Bytes(), v), out.String())
+	}
+	return err
+}
+
+func TestCtlAdmins(t *testing.T) {
+	app, _, out := newCtl(t, database.NewMemory())
+
+	var admin models.Admin
+	assert.NoError(t, runCtl(t, app, out, &admin, "admin", "create", "-name", "Mei", "-email", "mei@gmail.com", "-position", "Member"))
+	assert.Equal(t, "active", admin.Status)
+
+	assert.NoError(t, runCtl(t, app, out, &admin, "admin", "promote", "mei@gmail.com", "President"))
+	assert.Equal(t, "President", admin.Position)
+	assert.NoError(t, runCtl(t, app, out, &admin, "admin", "update", "-status", "hiatus", admin.ID))
+	assert.Equal(t, "President", admin.Position, "fields without flags are kept")
+	assert.Equal(t, "hiatus", admin.Status)
+
+	var admins []models.Admin
+	assert.NoError(t, runCtl(t, app, out, &admins, "admin", "list"))
+	assert.Len(t, admins, 2)
+
+	err := runCtl(t, app, out, nil, "admin", "delete", "jiating.lion.dragon@gmail.com")
+	assert.EqualError(t, err, "cannot delete a permanent admin")
+	assert.NoError(t, runCtl(t, app, out, &admin, "admin", "delete", "mei@gmail.com"))
+	assert.NotNil(t, admin.DeletedAt)
+	assert.NoError(t, runCtl(t, app, out, &admins, "admin", "list", "-deleted", "only"))
+	assert.Len(t, admins, 1)
+	var restored models.Admin
+	assert.NoError(t, runCtl(t, app, out, &restored, "admin", "restore", admin.ID))
+	assert.Equal(t, admin.ID, restored.ID)
+	assert.Nil(t, restored.DeletedAt)
+
+	assert.EqualError(t, runCtl(t, app, out, nil, "admin", "reset-founder"), "no database connection")
+
+	for _, args := range [][]string{{}, {"admin"}, {"admin", "fire"}, {"admin", "get"}, {"admin", "list", "-deleted", "maybe"}, {"launch"}} {
+		assert.True(t, ctl.IsUsageError(app.Run(context.Background(), args)), args)
+	}
+}
+
+func TestCtlTableOutput(t *testing.T) {
+	app, _, out := newCtl(t, database.NewMemory())
+	app.JSON = false
+
+	assert.NoError(t, app.Run(context.Background(), []string{"admin", "get", "jiating.lion.dragon@gmail.com"}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, []string{"ID", "EMAIL", "NAME", "POSITION", "STATUS", "DELETED"}, strings.Fields(lines[0]))
+		assert.Contains(t, lines[1], "jiating.lion.dragon@gmail.com")
+	}
+}
+
+func TestCtlEventExportImport(t *testing.T) {
+	ctx := context.Background()
+	fixtures := loadFixtures(t)
+	source := database.NewMemory()
+	assert.NoError(t, fixtures.Apply(ctx, source))
+	app, _, out := newCtl(t, source)
+
+	path := filepath.Join(t.TempDir(), "events.json")
+	assert.NoError(t, runCtl(t, app, out, nil, "event", "export", "-out", path))
+
+	// the target has the same admins under different ids
+	target := database.NewMemory()
+	for _, admin := range fixtures.Admins {
+		_, err := target.Admins.CreateAdmin(ctx, admin)
+		assert.NoError(t, err)
+	}
+	app.Repos = target
+	var result ctl.ImportResult
+	assert.NoError(t, runCtl(t, app, out, &result, "event", "import", path))
+	assert.Len(t, result.Imported, len(fixtures.Events))
+
+	var events []models.Event
+	assert.NoError(t, runCtl(t, app, out, &events, "event", "list", "-page-size", "100"))
+	assert.Len(t, events, len(fixtures.Events))
+	for _, want := range fixtures.Events {
+		authors, err := target.Authors.GetEventAuthors(ctx, want.ID)
+		assert.NoError(t, err)
+		assert.Len(t, authors, len(want.AuthorEmails), want.Slug)
+		images, err := target.Images.GetEventImages(ctx, want.ID)
+		assert.NoError(t, err)
+		assert.Len(t, images, len(want.Images), want.Slug)
+	}
+
+	assert.Error(t, runCtl(t, app, out, nil, "event", "import", path), "events exist already")
+	assert.NoError(t, runCtl(t, app, out, &result, "event", "import", "-skip-existing", path))
+	assert.Empty(t, result.Imported)
+	assert.Len(t, result.Skipped, len(fixtures.Events))
+}
+
+func TestCtlStorage(t *testing.T) {
+	app, fake, out := newCtl(t, database.NewMemory())
+	fake.PutObject(testBucket, "photoshoots/2024/cny/a.jpg", []byte("aaaa"))
+	fake.PutObject(testBucket, "photoshoots/2024/cny/b.jpg", []byte("bb"))
+	fake.PutObject(testBucket, "testing/1/new.jpg", []byte("new"))
+	fake.Now = func() time.Time { return time.Now().AddDate(0, -1, 0) }
+	fake.PutObject(testBucket, "testing/1/old.jpg", []byte("old"))
+	fake.Now = time.Now
+
+	var names []string
+	assert.NoError(t, runCtl(t, app, out, &names, "storage", "photoshoots"))
+	assert.Equal(t, []string{"2024"}, names)
+	assert.NoError(t, runCtl(t, app, out, &names, "storage", "photoshoots", "2024", "cny"))
+	assert.Equal(t, []string{"a.jpg", "b.jpg"}, names)
+
+	var usage []ctl.PrefixUsage
+	assert.NoError(t, runCtl(t, app, out, &usage, "storage", "usage"))
+	assert.Equal(t, []ctl.PrefixUsage{
+		{Prefix: "photoshoots/", Objects: 2, Bytes: 6},
+		{Prefix: "testing/", Objects: 2, Bytes: 6},
+	}, usage)
+
+	var pruned ctl.PruneResult
+	assert.NoError(t, runCtl(t, app, out, &pruned, "storage", "prune-testing", "-dry-run"))
+	assert.Equal(t, []string{"testing/1/old.jpg"}, pruned.Deleted)
+	assert.Len(t, fake.Keys(testBucket), 4, "dry runs delete nothing")
+	assert.NoError(t, runCtl(t, app, out, &pruned, "storage", "prune-testing"))
+	assert.Equal(t, []string{"testing/1/old.jpg"}, pruned.Deleted)
+	assert.NotContains(t, fake.Keys(testBucket), "testing/1/old.jpg")
+}
+
+func TestCtlHealthWithoutDatabase(t *testing.T) {
+	app, _, out := newCtl(t, database.NewMemory())
+
+	err := app.Run(context.Background(), []string{"health"})
+	assert.ErrorIs(t, err, ctl.ErrUnhealthy)
+	var health ctl.Health
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &health))
+	assert.False(t, health.OK)
+	assert.Equal(t, []ctl.Check{
+		{Name: "database", Error: "no database connection"},
+		{Name: "storage", OK: true, Detail: testBucket},
+	}, health.Checks)
+}
+
+func TestCtlMigrateFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS admins").WillReturnError(errors.New("permission denied for schema public"))
+
+	app, _, out := newCtl(t, database.NewMemory())
+	app.DB = db
+	err = runCtl(t, app, out, nil, "migrate", "up")
+	if assert.Error(t, err, "the migration fails instead of exiting") {
+		assert.Contains(t, err.Error(), "error creating admins table: permission denied for schema public")
+	}
+	assert.Empty(t, out.String(), "no status is printed")
+
+	assert.True(t, app.PrintError(err))
+	var failure ctl.Failure
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &failure))
+	assert.Equal(t, err.Error(), failure.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCtlWithPostgres(t *testing.T) {
+	db := pgtest.NewDatabase(t)
+	app, _, out := newCtl(t, database.NewRepositories(db))
+	app.DB = db
+
+	var status ctl.SchemaStatus
+	assert.NoError(t, runCtl(t, app, out, &status, "migrate", "up"))
+	assert.Equal(t, ctl.SchemaStatus{Version: database.SchemaVersion, Expected: database.SchemaVersion, Current: true}, status)
+
+	var health ctl.Health
+	assert.NoError(t, runCtl(t, app, out, &health, "health"))
+	assert.True(t, health.OK)
+
+	_, err := db.Exec(`UPDATE admins SET name = 'Someone', deleted_at = NOW() WHERE email = 'jiating.lion.dragon@gmail.com'`)
+	assert.NoError(t, err)
+	var founder models.Admin
+	assert.NoError(t, runCtl(t, app, out, &founder, "admin", "reset-founder"))
+	assert.Equal(t, "Jiating", founder.Name)
+	assert.Nil(t, founder.DeletedAt)
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDevAuthServer serves the auth routes with only the dev provider enabled
+// and a protected endpoint to check the session against
+func newDevAuthServer(t *testing.T) (*httptest.Server, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	r := chi.NewRouter()
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	store := sessions.NewCookieStore([]byte("test-session-key"))
+	store.Options.Path = "/"
+	a := auth.NewAuth(&auth.AuthConfig{
+		Store:           store,
+		DB:              database.New(db),
+		CallbackBaseURL: server.URL,
+		FrontendURL:     "http://localhost:5173",
+		Providers:       []string{"dev"},
+	})
+
+	r.Route("/auth", func(r chi.Router) {
+		r.Get("/dev/authorize", a.DevAuthorizeHandler())
+		r.Get("/{provider}/callback", a.GetAuthCallbackHandler())
+		r.Get("/{provider}", a.BeginAuthHandler())
+	})
+	r.With(a.AuthMiddleware).Get("/protected", func(w http.ResponseWriter, r *http.Request) {
+		adminID, _ := auth.AdminIDFromContext(r.Context())
+		w.Write([]byte(adminID))
+	})
+
+	return server, mock
+}
+
+func newCookieClient(t *testing.T, server *httptest.Server) *http.Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{
+		Jar: jar,
+		// stop at the redirect to the frontend dashboard
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Host != server.Listener.Addr().String() {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+// devAuthorizeURL begins the dev OAuth flow and returns the consent screen
+// url, carrying the oauth state, with the email to sign in as
+func devAuthorizeURL(t *testing.T, client *http.Client, server *httptest.Server, email string) string {
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+
+	begin, err := noRedirect.Get(server.URL + "/auth/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin.Body.Close()
+
+	authorizeURL, err := begin.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := authorizeURL.Query()
+	q.Set("email", email)
+	authorizeURL.RawQuery = q.Encode()
+	return authorizeURL.String()
+}
+
+func TestDevProviderLogin(t *testing.T) {
+	server, mock := newDevAuthServer(t)
+	client := newCookieClient(t, server)
+
+	now := time.Now()
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("dev", "dev:jiating.lion.dragon@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name", "email", "position", "status"}).
+			AddRow("11111111-1111-1111-1111-111111111111", now, now, nil, "Jiating", "jiating.lion.dragon@gmail.com", "Founder", "permanent"))
+	mock.ExpectExec("UPDATE admin_identities SET last_login_at").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// begin auth and pick the founder on the dev consent screen,
+	// which redirects back to the callback
+	res, err := client.Get(devAuthorizeURL(t, client, server, "jiating.lion.dragon@gmail.com"))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "http://localhost:5173/admin/dashboard", res.Header.Get("Location"))
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("11111111-1111-1111-1111-111111111111", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	protected, err := client.Get(server.URL + "/protected")
+	assert.NoError(t, err)
+	defer protected.Body.Close()
+	assert.Equal(t, http.StatusOK, protected.StatusCode)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDevProviderUnknownEmail(t *testing.T) {
+	server, mock := newDevAuthServer(t)
+	client := newCookieClient(t, server)
+
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("dev", "dev:stranger@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// the dev provider's emails count as verified, so the email is looked up
+	mock.ExpectQuery("NOT EXISTS").
+		WithArgs("stranger@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err := client.Get(devAuthorizeURL(t, client, server, "stranger@gmail.com"))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, "/login-unauthorized", res.Request.URL.Path)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/email"
+	"backend/internal/health"
+	"backend/internal/lifecycle"
+	"backend/internal/ratelimit"
+	"backend/internal/server"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func healthUp(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"version": 1}, nil
+}
+
+func healthDown(ctx context.Context) (map[string]interface{}, error) {
+	return nil, errors.New("connection refused")
+}
+
+func runHealth(t *testing.T, checker *health.Checker) (int, health.Report) {
+	rec := httptest.NewRecorder()
+	health.WriteReport(rec, checker.Run(context.Background()))
+
+	var report health.Report
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func TestHealthCriticalFailureIsDown(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", healthDown)
+	checker.Optional("s3", healthUp)
+
+	code, report := runHealth(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["postgres"].Error)
+	assert.Equal(t, health.StatusUp, report.Checks["s3"].Status)
+}
+
+func TestHealthOptionalFailureIsDegraded(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", healthUp)
+	checker.Optional("smtp", healthDown)
+
+	code, report := runHealth(t, checker)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, float64(1), report.Checks["postgres"].Details["version"])
+	assert.False(t, report.Checks["smtp"].Critical)
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	checker := health.NewChecker(10 * time.Millisecond)
+	checker.Critical("s3", func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	code, report := runHealth(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["s3"].Error)
+	assert.GreaterOrEqual(t, report.Checks["s3"].LatencyMs, float64(10))
+}
+
+func TestHealthCheckerCachesReport(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.CacheFor(time.Minute)
+	runs := 0
+	checker.Critical("postgres", func(ctx context.Context) (map[string]interface{}, error) {
+		runs++
+		return nil, nil
+	})
+
+	first := checker.Run(context.Background())
+	second := checker.Run(context.Background())
+	assert.Equal(t, 1, runs, "dependencies are checked once per ttl")
+	assert.Equal(t, first.CheckedAt, second.CheckedAt)
+}
+
+func TestHealthReportWithAndSummary(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", healthUp)
+	report := checker.Run(context.Background())
+
+	down := report.With("lifecycle", health.Result{Status: health.StatusDown, Critical: true})
+	assert.Equal(t, health.StatusDown, down.Status)
+	assert.Len(t, down.Checks, 2)
+	assert.Len(t, report.Checks, 1, "the original report is left alone")
+
+	summary := down.Summary()
+	assert.Equal(t, health.StatusDown, summary.Status)
+	assert.Nil(t, summary.Checks)
+}
+
+// newHealthRouter serves the real routes with METRICS_TOKEN set to "scrape"
+func newHealthRouter(t *testing.T) (http.Handler, sqlmock.Sqlmock, *lifecycle.Manager) {
+	setConfigEnv(t)
+	t.Setenv("METRICS_TOKEN", "scrape")
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	lc := lifecycle.New()
+	r := server.NewRouter(cfg, server.Services{
+		DB:         database.New(db),
+		Auth:       auth.NewAuth(auth.NewAuthConfig(cfg, database.New(db))),
+		Email:      email.NewService(cfg.Email),
+		EmailQueue: email.NewQueue(1),
+		Limiter:    ratelimit.New(cfg.RateLimit, ratelimit.NewMemoryStore(10)),
+	}, lc)
+	return r, mock, lc
+}
+
+func getHealth(r http.Handler, target, token string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&body)
+	return rec.Code, body
+}
+
+func TestReadinessDetailsRequireMetricsToken(t *testing.T) {
+	r, mock, lc := newHealthRouter(t)
+	lc.MarkReady()
+	// checked once, the other requests are served from the cache
+	mock.ExpectQuery(`SELECT MAX\(version\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(database.SchemaVersion))
+
+	code, body := getHealth(r, "/health/ready", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "degraded", body["status"], "s3 and smtp aren't configured")
+	assert.NotContains(t, body, "checks")
+
+	code, body = getHealth(r, "/health/ready", "scrape")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Contains(t, body, "checks") {
+		assert.Contains(t, body["checks"], "postgres")
+	}
+
+	_, body = getHealth(r, "/health", "forged")
+	assert.NotContains(t, body, "checks")
+	_, body = getHealth(r, "/health", "scrape")
+	assert.NotContains(t, body, "checks", "the legacy route never has checks")
+
+	// readiness isn't cached, draining shows right away
+	assert.NoError(t, lc.Shutdown(time.Second))
+	code, body = getHealth(r, "/health/ready", "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "down", body["status"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSchemaVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT MAX\(version\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(database.SchemaVersion))
+
+	version, err := database.New(db).GetSchemaVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, database.SchemaVersion, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var adminColumns = []string{"id", "created_at", "updated_at", "deleted_at", "name", "email", "position", "status"}
+
+func newMockService(t *testing.T) (database.Service, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return database.New(db), mock
+}
+
+func TestResolveAdminLoginPrefersIdentity(t *testing.T) {
+	s, mock := newMockService(t)
+	now := time.Now()
+
+	// the admin changed their email, the provider ID still matches
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("google", "google-123").
+		WillReturnRows(sqlmock.NewRows(adminColumns).
+			AddRow("admin-1", now, now, nil, "Alice", "alice@school.edu", "Member", "active"))
+	mock.ExpectExec("UPDATE admin_identities SET last_login_at").
+		WithArgs(sqlmock.AnyArg(), "alice.new@gmail.com", "google", "google-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	admin, err := s.ResolveAdminLogin(context.Background(), "google", "google-123", "alice.new@gmail.com", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", admin.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveAdminLoginFallsBackToEmail(t *testing.T) {
+	s, mock := newMockService(t)
+	now := time.Now()
+
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("google", "google-123").
+		WillReturnRows(sqlmock.NewRows(adminColumns))
+	mock.ExpectQuery("NOT EXISTS").
+		WithArgs("alice@gmail.com").
+		WillReturnRows(sqlmock.NewRows(adminColumns).
+			AddRow("admin-1", now, now, nil, "Alice", "alice@gmail.com", "Member", "active"))
+	// the account is linked so the next login no longer depends on the email
+	mock.ExpectExec("INSERT INTO admin_identities").
+		WithArgs("google", "google-123", "admin-1", "alice@gmail.com", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE admin_identities SET last_login_at").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	admin, err := s.ResolveAdminLogin(context.Background(), "google", "google-123", "Alice@gmail.com", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", admin.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveAdminLoginRequiresVerifiedEmail(t *testing.T) {
+	s, mock := newMockService(t)
+
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("oidc", "oidc-9").
+		WillReturnRows(sqlmock.NewRows(adminColumns))
+
+	_, err := s.ResolveAdminLogin(context.Background(), "oidc", "oidc-9", "alice@gmail.com", false)
+	assert.EqualError(t, err, "admin not found")
+	assert.NoError(t, mock.ExpectationsWereMet(), "an unverified email is never looked up")
+}
+
+func TestResolveAdminLoginRefusesEmailOfLinkedAdmin(t *testing.T) {
+	s, mock := newMockService(t)
+
+	// alice already has an account linked, the NOT EXISTS filters her out
+	mock.ExpectQuery("FROM admin_identities i").
+		WithArgs("google", "google-999").
+		WillReturnRows(sqlmock.NewRows(adminColumns))
+	mock.ExpectQuery("NOT EXISTS").
+		WithArgs("alice@gmail.com").
+		WillReturnRows(sqlmock.NewRows(adminColumns))
+
+	_, err := s.ResolveAdminLogin(context.Background(), "google", "google-999", "alice@gmail.com", true)
+	assert.EqualError(t, err, "admin not found")
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is linked")
+}
+
+func TestLinkAdminIdentityOwnedByAnotherAdmin(t *testing.T) {
+	s, mock := newMockService(t)
+
+	mock.ExpectExec("INSERT INTO admin_identities").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := s.LinkAdminIdentity(context.Background(), newIdentity("admin-2"))
+	assert.EqualError(t, err, "identity already linked to another admin")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnlinkLastAdminIdentity(t *testing.T) {
+	s, mock := newMockService(t)
+
+	mock.ExpectExec("DELETE FROM admin_identities").
+		WithArgs("admin-1", "google", "google-123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("admin-1", "google", "google-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err := s.UnlinkAdminIdentity(context.Background(), "admin-1", "google", "google-123")
+	assert.EqualError(t, err, "cannot unlink the last identity")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func newIdentity(adminID string) models.AdminIdentity {
+	return models.AdminIdentity{AdminID: adminID, Provider: "google", ProviderUserID: "google-123", Email: "alice@gmail.com"}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/s3service"
+	"backend/internal/s3service/s3fake"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+const testItemID = "22222222-2222-2222-2222-222222222222"
+
+var inventoryItemColumns = []string{"id", "created_at", "updated_at", "name", "category", "condition", "condition_notes", "photo_key", "checked_out"}
+
+func expectInventoryItem(mock sqlmock.Sqlmock, photoKey string) {
+	now := time.Now()
+	mock.ExpectQuery("FROM inventory_items i").
+		WithArgs(testItemID).
+		WillReturnRows(sqlmock.NewRows(inventoryItemColumns).
+			AddRow(testItemID, now, now, "Red lion head", "lion_head", "good", "", photoKey, false))
+}
+
+func TestInventoryPhotoIsRecordedAfterUpload(t *testing.T) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := database.New(db)
+	deps := &handlers.HandlerDependencies{S3Service: s3service.NewService(cfg)}
+
+	r := chi.NewRouter()
+	r.Post("/inventory/{id}/photo", deps.GetInventoryPhotoUploadURLHandler(s))
+	r.Put("/inventory/{id}/photo", deps.ConfirmInventoryPhotoHandler(s))
+	target := "/inventory/" + testItemID + "/photo?file=head.jpg"
+	key := s3service.InventoryPhotoKey(testItemID, "head.jpg")
+
+	// issuing the upload url leaves the item alone
+	expectInventoryItem(mock, "")
+	rec := serve(r, http.MethodPost, target, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var upload map[string]string
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&upload))
+	assert.Equal(t, key, upload["key"])
+	assert.NoError(t, mock.ExpectationsWereMet(), "no update before the upload")
+
+	// an abandoned upload is never referenced
+	expectInventoryItem(mock, "")
+	rec = serve(r, http.MethodPut, target, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	fake.PutObject(testBucket, key, []byte("jpeg"))
+	expectInventoryItem(mock, "")
+	mock.ExpectExec("UPDATE inventory_items SET").
+		WithArgs(sqlmock.AnyArg(), "Red lion head", "lion_head", "good", "", key, testItemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	rec = serve(r, http.MethodPut, target, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), key)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// newInventoryRouter serves the real routes to a token with inventory access
+func newInventoryRouter(t *testing.T) (http.Handler, sqlmock.Sqlmock) {
+	setConfigEnv(t)
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, mock, _ := newAppRouter(t, cfg)
+	return r, mock
+}
+
+func inventoryRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	return req
+}
+
+func TestCheckOutAndCheckInItem(t *testing.T) {
+	r, mock := newInventoryRouter(t)
+	due := time.Now().Add(48 * time.Hour).Format(time.RFC3339)
+	body := `{"member_id": "33333333-3333-3333-3333-333333333333", "due_at": "` + due + `", "notes": "parade"}`
+
+	expectTokenLookup(mock, "inventory:write")
+	mock.ExpectQuery("INSERT INTO item_checkouts").
+		WithArgs(testItemID, nil, "33333333-3333-3333-3333-333333333333", "admin-1", sqlmock.AnyArg(), sqlmock.AnyArg(), "parade").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("checkout-1"))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, inventoryRequest(http.MethodPost, "/api/v1/inventory/"+testItemID+"/checkout", body))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "checkout-1")
+
+	// the open checkout index rejects a second one, reported by pgx
+	expectTokenLookup(mock, "inventory:write")
+	mock.ExpectQuery("INSERT INTO item_checkouts").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_item_checkouts_open"})
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, inventoryRequest(http.MethodPost, "/api/v1/inventory/"+testItemID+"/checkout", body))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "item is already checked out", strings.TrimSpace(rec.Body.String()))
+
+	expectTokenLookup(mock, "inventory:write")
+	mock.ExpectQuery("INSERT INTO item_checkouts").
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, inventoryRequest(http.MethodPost, "/api/v1/inventory/"+testItemID+"/checkout", body))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid performance, member or admin id", strings.TrimSpace(rec.Body.String()))
+
+	// checking in closes the checkout and records the condition
+	expectTokenLookup(mock, "inventory:write")
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE item_checkouts SET").
+		WithArgs(sqlmock.AnyArg(), "", testItemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE inventory_items SET").
+		WithArgs(sqlmock.AnyArg(), "needs_repair", "torn mane", testItemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, inventoryRequest(http.MethodPost, "/api/v1/inventory/"+testItemID+"/checkin", `{"condition": "needs_repair", "condition_notes": "torn mane"}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// a second check in finds nothing open
+	expectTokenLookup(mock, "inventory:write")
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE item_checkouts SET").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, inventoryRequest(http.MethodPost, "/api/v1/inventory/"+testItemID+"/checkin", `{}`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "item is not checked out", strings.TrimSpace(rec.Body.String()))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
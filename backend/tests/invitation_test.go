@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/tokens"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockInvitation(t *testing.T, expiresAt time.Time, acceptedAt, revokedAt *time.Time) (database.Service, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, email, name, position, expires_at, accepted_at, revoked_at").
+		WithArgs("token-hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "position", "expires_at", "accepted_at", "revoked_at"}).
+			AddRow("invitation-1", "new.admin@gmail.com", "New Admin", "Treasurer", expiresAt, acceptedAt, revokedAt))
+
+	return database.New(db), mock
+}
+
+func TestAcceptAdminInvitation(t *testing.T) {
+	s, mock := mockInvitation(t, time.Now().Add(time.Hour), nil, nil)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO admins").
+		WithArgs(sqlmock.AnyArg(), "New Admin", "new.admin@gmail.com", "Treasurer", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("admin-1", now, now))
+	mock.ExpectExec("UPDATE admin_invitations SET").
+		WithArgs(sqlmock.AnyArg(), "admin-1", "invitation-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	admin, err := s.AcceptAdminInvitation(context.Background(), "token-hash", "New.Admin@gmail.com", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", admin.ID)
+	assert.Equal(t, "new.admin@gmail.com", admin.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptAdminInvitationBindsOtherAccount(t *testing.T) {
+	s, mock := mockInvitation(t, time.Now().Add(time.Hour), nil, nil)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO admins").
+		WithArgs(sqlmock.AnyArg(), "New Admin", "personal@gmail.com", "Treasurer", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("admin-1", now, now))
+	mock.ExpectExec("UPDATE admin_invitations SET").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	admin, err := s.AcceptAdminInvitation(context.Background(), "token-hash", "personal@gmail.com", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "personal@gmail.com", admin.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptAdminInvitationRejected(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	cases := []struct {
+		name       string
+		expiresAt  time.Time
+		acceptedAt *time.Time
+		revokedAt  *time.Time
+		email      string
+		want       string
+	}{
+		{"email mismatch", time.Now().Add(time.Hour), nil, nil, "someone@gmail.com", "account email does not match invitation"},
+		{"expired", past, nil, nil, "new.admin@gmail.com", "invitation has expired"},
+		{"already accepted", time.Now().Add(time.Hour), &past, nil, "new.admin@gmail.com", "invitation has already been accepted"},
+		{"revoked", time.Now().Add(time.Hour), nil, &past, "new.admin@gmail.com", "invitation has been revoked"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, mock := mockInvitation(t, tc.expiresAt, tc.acceptedAt, tc.revokedAt)
+			mock.ExpectRollback()
+
+			_, err := s.AcceptAdminInvitation(context.Background(), "token-hash", tc.email, false)
+			assert.EqualError(t, err, tc.want)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestNewInvitationToken(t *testing.T) {
+	_, err := auth.NewInvitationToken("")
+	assert.Error(t, err)
+
+	token, err := auth.NewInvitationToken("test-secret")
+	assert.NoError(t, err)
+	payload, err := tokens.Verify("test-secret", token)
+	assert.NoError(t, err)
+	assert.Contains(t, payload, "invitation:")
+}
+
+// admins can't skip the invitation flow by creating an admin directly
+func TestCreateAdminRequiresFounder(t *testing.T) {
+	r, mock := newConfiguredRouter(t)
+	body := `{"name": "Mei", "email": "mei@gmail.com", "position": "Member", "status": "active"}`
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admins", strings.NewReader(body))
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	now := time.Now()
+	expectTokenLookup(mock, "admins:write")
+	mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+		WithArgs("admin-1").
+		WillReturnRows(sqlmock.NewRows(adminColumns).AddRow("admin-1", now, now, nil, "Tuan", "tuan@gmail.com", "Treasurer", "active"))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, tokenRequest(http.MethodPost, "/api/v1/admins", body))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	expectTokenLookup(mock, "admins:write")
+	mock.ExpectQuery("SELECT id, created_at, updated_at, deleted_at, name, email, position, status").
+		WithArgs("admin-1").
+		WillReturnRows(sqlmock.NewRows(adminColumns).AddRow("admin-1", now, now, nil, "Jiating", "jiating.lion.dragon@gmail.com", "Founder", "permanent"))
+	mock.ExpectQuery("INSERT INTO admins").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("admin-2"))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, tokenRequest(http.MethodPost, "/api/v1/admins", body))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAdminRequiresSignIn(t *testing.T) {
+	r, mock := newConfiguredRouter(t)
+
+	for _, target := range []string{"/api/v1/admins/11111111-1111-1111-1111-111111111111", "/api/admins/11111111-1111-1111-1111-111111111111"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(`{"name": "Someone", "email": "jiating.lion.dragon@gmail.com", "position": "Member", "status": "active"}`))
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, target)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is updated")
+}
+
+// demoting the founder would let any admin delete them afterwards
+func TestUpdateAdminRefusesToDemoteFounder(t *testing.T) {
+	r, mock := newConfiguredRouter(t)
+	founderID := "11111111-1111-1111-1111-111111111111"
+
+	expectTokenLookup(mock, "admins:write")
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT email, position, status FROM admins").
+		WithArgs(founderID).
+		WillReturnRows(sqlmock.NewRows([]string{"email", "position", "status"}).
+			AddRow("jiating.lion.dragon@gmail.com", "Founder", "permanent"))
+	mock.ExpectRollback()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, tokenRequest(http.MethodPut, "/api/v1/admins/"+founderID,
+		`{"name": "Jiating", "email": "jiating.lion.dragon@gmail.com", "position": "Founder", "status": "active"}`))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "cannot change a permanent admin\n", rec.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is updated")
+}
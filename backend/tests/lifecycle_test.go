@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"backend/internal/email"
+	"backend/internal/lifecycle"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleShutdownOrder(t *testing.T) {
+	lc := lifecycle.New()
+
+	var order []string
+	for _, name := range []string{"database", "email queue", "http server"} {
+		name := name
+		lc.OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+	workerStopped := make(chan struct{})
+	lc.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(workerStopped)
+		return ctx.Err()
+	})
+
+	assert.NoError(t, lc.Shutdown(time.Second))
+	assert.Equal(t, []string{"http server", "email queue", "database"}, order)
+	assert.False(t, lc.Ready())
+	<-workerStopped
+}
+
+func TestLifecycleRunStopsOnSignal(t *testing.T) {
+	lc := lifecycle.New()
+	stopped := false
+	lc.OnShutdown("hook", func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+
+	// caught here too, so a signal sent before Run listens doesn't kill the test
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGTERM)
+	defer signal.Stop(caught)
+
+	done := make(chan error)
+	go func() { done <- lc.Run(time.Second) }()
+
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+			assert.True(t, stopped)
+			return
+		case <-tick.C:
+			assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+		}
+	}
+}
+
+func TestLifecycleReadyOnlyOnceMarked(t *testing.T) {
+	lc := lifecycle.New()
+	assert.False(t, lc.Ready(), "not ready before the listener is bound")
+	lc.MarkReady()
+	assert.True(t, lc.Ready())
+}
+
+func TestLifecycleShutdownDrainsBeforeHooks(t *testing.T) {
+	lc := lifecycle.New()
+	lc.SetDrainDelay(50 * time.Millisecond)
+	lc.MarkReady()
+
+	var readyAtHook bool
+	var drained time.Duration
+	start := time.Now()
+	lc.OnShutdown("http server", func(ctx context.Context) error {
+		readyAtHook = lc.Ready()
+		drained = time.Since(start)
+		return nil
+	})
+
+	assert.NoError(t, lc.Shutdown(time.Second))
+	assert.False(t, readyAtHook, "readiness flips before the listener closes")
+	assert.GreaterOrEqual(t, drained, 50*time.Millisecond)
+}
+
+func TestLifecycleRunStopsOnWorkerFailure(t *testing.T) {
+	lc := lifecycle.New()
+	lc.Go("http server", func(ctx context.Context) error {
+		return errors.New("address already in use")
+	})
+
+	err := lc.Run(time.Second)
+	assert.EqualError(t, err, "http server: address already in use")
+}
+
+func TestEmailQueueDrainsOnClose(t *testing.T) {
+	q := email.NewQueue(2)
+
+	ran := 0
+	job := email.Job{Name: "test", Run: func(ctx context.Context) error {
+		ran++
+		return nil
+	}}
+	assert.NoError(t, q.Enqueue(job))
+	assert.NoError(t, q.Enqueue(job))
+	assert.ErrorIs(t, q.Enqueue(job), email.ErrQueueFull)
+	assert.Equal(t, 2, q.Len())
+
+	go q.Run(context.Background())
+	assert.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, 2, ran)
+	assert.ErrorIs(t, q.Enqueue(job), email.ErrQueueClosed)
+}
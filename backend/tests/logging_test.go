@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"backend/loggers"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs sends JSON logs to a buffer for the duration of the test
+func captureLogs(t *testing.T, level slog.Level, zone *time.Location) *bytes.Buffer {
+	var buf bytes.Buffer
+	loggers.Setup(&buf, loggers.Options{Level: level, Format: "json", Zone: zone})
+	t.Cleanup(func() {
+		loggers.Setup(os.Stderr, loggers.Options{Level: slog.LevelInfo, Format: "logfmt"})
+	})
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestRequestLogsCarryRequestAttributes(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo, nil)
+
+	r := chi.NewRouter()
+	r.Use(loggers.RequestMiddleware)
+	r.Get("/api/events/{id}", func(w http.ResponseWriter, r *http.Request) {
+		loggers.SetAdminID(r.Context(), "admin-1")
+		loggers.Debug.Ctx(r.Context()).Println("hidden at info level")
+		loggers.Error.Ctx(r.Context()).Printf("Error getting event: %v", "boom")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events/42", nil)
+	req.Header.Set(loggers.RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "req-123", rec.Header().Get(loggers.RequestIDHeader))
+
+	lines := logLines(t, buf)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, "ERROR", lines[0]["level"])
+		assert.Equal(t, "Error getting event: boom", lines[0]["msg"])
+		assert.Equal(t, "req-123", lines[0]["request_id"])
+		assert.Equal(t, "/api/events/{id}", lines[0]["route"])
+		assert.Equal(t, "admin-1", lines[0]["admin_id"])
+		assert.Contains(t, lines[0]["source"], "logging_test.go:")
+
+		// the access log line of the request
+		assert.Equal(t, "request", lines[1]["msg"])
+		assert.Equal(t, float64(http.StatusInternalServerError), lines[1]["status"])
+		assert.Equal(t, "req-123", lines[1]["request_id"])
+	}
+}
+
+func TestRequestIDIsGenerated(t *testing.T) {
+	captureLogs(t, slog.LevelInfo, nil)
+
+	handler := loggers.RequestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, loggers.RequestID(r.Context()))
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(t, rec.Header().Get(loggers.RequestIDHeader), 24)
+}
+
+func TestLogLevelAndZone(t *testing.T) {
+	zone := time.FixedZone("TEST", 2*60*60)
+	buf := captureLogs(t, slog.LevelDebug, zone)
+
+	loggers.Performance.Printf("GetYears took %s", time.Millisecond)
+
+	lines := logLines(t, buf)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "DEBUG", lines[0]["level"])
+		assert.Equal(t, "performance", lines[0]["category"])
+		assert.True(t, strings.HasSuffix(lines[0]["time"].(string), "+02:00"))
+	}
+}
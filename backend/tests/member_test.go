@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeMemberInput(t *testing.T) {
+	emptyAdminID := ""
+	member := models.Member{
+		Name:        "  Tuan  ",
+		ChineseName: "阿端",
+		Role:        " Drummer ",
+		AdminID:     &emptyAdminID,
+	}
+
+	err := database.SanitizeMemberInput(&member)
+	assert.NoError(t, err)
+	assert.Equal(t, "Tuan", member.Name)
+	assert.Equal(t, "drummer", member.Role)
+	assert.False(t, member.JoinDate.IsZero(), "join date defaults to today")
+	assert.Nil(t, member.AdminID, "empty admin id is not linked")
+}
+
+func TestSanitizeMemberInputInvalid(t *testing.T) {
+	err := database.SanitizeMemberInput(&models.Member{Name: "no role", Role: "dancer"})
+	assert.EqualError(t, err, "invalid member role")
+
+	err = database.SanitizeMemberInput(&models.Member{Name: "   ", Role: "head"})
+	assert.EqualError(t, err, "missing name")
+}
+
+// constraint violations come from pgx, the driver the server runs with
+func TestCreateMemberConstraintViolations(t *testing.T) {
+	for code, want := range map[string]struct {
+		message string
+		status  int
+	}{
+		"23505": {"admin already linked to a member", http.StatusConflict},
+		"23503": {"invalid admin id", http.StatusBadRequest},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+		}
+		mock.ExpectQuery("INSERT INTO members").
+			WillReturnError(&pgconn.PgError{Code: code, Message: "constraint violated"})
+
+		rec := httptest.NewRecorder()
+		body := `{"name": "Mei", "role": "head", "admin_id": "11111111-1111-1111-1111-111111111111"}`
+		handlers.CreateMemberHandler(database.New(db)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/members", strings.NewReader(body)))
+
+		assert.Equal(t, want.status, rec.Code, code)
+		assert.Equal(t, want.message, strings.TrimSpace(rec.Body.String()), code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
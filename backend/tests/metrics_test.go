@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"backend/internal/metrics"
+	"backend/internal/s3service"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsLabelRequestsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(metrics.Middleware)
+	r.Route("/api/metrics-test", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "event not found", http.StatusNotFound)
+		})
+	})
+
+	for _, id := range []string{"a", "b"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/metrics-test/"+id, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `jiating_http_requests_total{method="GET",route="/api/metrics-test/{id}",status="404"} 2`)
+	assert.Contains(t, body, `jiating_http_request_duration_seconds_count{method="GET",route="/api/metrics-test/{id}"} 2`)
+	assert.Contains(t, body, `route="unmatched"`)
+	assert.NotContains(t, body, `/api/metrics-test/a`)
+}
+
+func TestMetricsRecordS3Errors(t *testing.T) {
+	mockS3Client := new(MockS3Client)
+	mockS3Client.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).
+		Return((*s3.ListObjectsV2Output)(nil), errors.New("access denied"))
+
+	// other tests ping storage too, so only this call is counted
+	errorsBefore := metricValue(t, scrapeMetrics(t), `jiating_s3_errors_total{method="Ping"}`)
+	countBefore := metricValue(t, scrapeMetrics(t), `jiating_s3_request_duration_seconds_count{method="Ping"}`)
+
+	s3Service := s3service.NewMockService(mockS3Client, new(MockPresigner), testBucket)
+	assert.Error(t, s3Service.Ping(context.Background()))
+
+	body := scrapeMetrics(t)
+	assert.Equal(t, errorsBefore+1, metricValue(t, body, `jiating_s3_errors_total{method="Ping"}`))
+	assert.Equal(t, countBefore+1, metricValue(t, body, `jiating_s3_request_duration_seconds_count{method="Ping"}`))
+}
+
+// metricValue returns the value of a series in a scrape, 0 if it is missing
+func metricValue(t *testing.T, body, series string) float64 {
+	for _, line := range strings.Split(body, "\n") {
+		if value, found := strings.CutPrefix(line, series+" "); found {
+			v, err := strconv.ParseFloat(value, 64)
+			assert.NoError(t, err)
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"backend/internal/config"
+	"backend/internal/email"
+	"backend/internal/models"
+	"backend/internal/tokens"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/gomail.v2"
+)
+
+type fakeSendCloser struct {
+	sent   *[]string
+	fail   string
+	closed *int
+}
+
+func (f fakeSendCloser) Send(from string, to []string, msg io.WriterTo) error {
+	if to[0] == f.fail {
+		return errors.New("mailbox unavailable")
+	}
+	*f.sent = append(*f.sent, to[0])
+	return nil
+}
+
+func (f fakeSendCloser) Close() error {
+	*f.closed++
+	return nil
+}
+
+func TestRenderEventNewsletter(t *testing.T) {
+	event := models.Event{
+		EventTitle:  "Lunar New Year <Show>",
+		Date:        time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		Description: "Lion dance at the park",
+	}
+
+	newsletter, err := email.RenderEventNewsletter(event, "https://example.com/events/lny")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jiating: Lunar New Year <Show>", newsletter.Subject)
+	assert.Contains(t, newsletter.HTML, "Lunar New Year &lt;Show&gt;")
+	assert.Contains(t, newsletter.Text, "Saturday, February 10, 2024")
+	assert.Contains(t, newsletter.Text, "https://example.com/events/lny")
+}
+
+func TestNewsletterMessagesUnsubscribeHeaders(t *testing.T) {
+	messages := email.NewsletterMessages("news@example.com", email.Newsletter{Subject: "Hi", Text: "t", HTML: "h"}, []email.NewsletterRecipient{
+		{Email: "a@example.com", UnsubscribeURL: "https://api.example.com/unsubscribe?token=a"},
+	})
+
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []string{"<https://api.example.com/unsubscribe?token=a>"}, messages[0].GetHeader("List-Unsubscribe"))
+	assert.Equal(t, []string{"List-Unsubscribe=One-Click"}, messages[0].GetHeader("List-Unsubscribe-Post"))
+}
+
+func TestSendInBatches(t *testing.T) {
+	var recipients []email.NewsletterRecipient
+	for _, addr := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		recipients = append(recipients, email.NewsletterRecipient{Email: addr, UnsubscribeURL: "https://example.com/u"})
+	}
+	messages := email.NewsletterMessages("news@example.com", email.Newsletter{Subject: "Hi"}, recipients)
+
+	var sent []string
+	dials, closed := 0, 0
+	dial := func() (gomail.SendCloser, error) {
+		dials++
+		return fakeSendCloser{sent: &sent, fail: "c@example.com", closed: &closed}, nil
+	}
+
+	n, err := email.SendInBatches(context.Background(), dial, messages, 2, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, 3, dials)
+	assert.Equal(t, 3, closed)
+	assert.Equal(t, "a@example.com,b@example.com,d@example.com,e@example.com", strings.Join(sent, ","))
+}
+
+func TestSendInBatchesDialError(t *testing.T) {
+	messages := email.NewsletterMessages("news@example.com", email.Newsletter{Subject: "Hi"}, []email.NewsletterRecipient{{Email: "a@example.com"}})
+	dial := func() (gomail.SendCloser, error) { return nil, errors.New("connection refused") }
+
+	n, err := email.SendInBatches(context.Background(), dial, messages, 10, 0)
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestUnsubscribeLinkOnlyChangesStateOnPost(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("NEWSLETTER_SECRET", "newsletter-secret")
+	t.Setenv("FRONTEND_URL", "https://jiating.example.com")
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, mock, _ := newAppRouter(t, cfg)
+	link := "/api/v1/newsletter/unsubscribe?token=" + url.QueryEscape(tokens.Sign("newsletter-secret", "unsubscribe:a@example.com"))
+
+	// scanners and previews fetching the link unsubscribe nobody
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, link, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `<form method="post"`)
+	assert.Contains(t, rec.Body.String(), "a@example.com")
+	assert.NoError(t, mock.ExpectationsWereMet(), "no query for a GET")
+
+	// the button of the page
+	mock.ExpectExec("UPDATE subscribers SET").
+		WithArgs(sqlmock.AnyArg(), "a@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	req := httptest.NewRequest(http.MethodPost, link, strings.NewReader("from=page"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "https://jiating.example.com/newsletter/unsubscribed", rec.Header().Get("Location"))
+
+	// one-click unsubscribe of mail clients, RFC 8058
+	mock.ExpectExec("UPDATE subscribers SET").
+		WithArgs(sqlmock.AnyArg(), "a@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	req = httptest.NewRequest(http.MethodPost, link, strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Unsubscribed successfully")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/newsletter/unsubscribe?token=forged.token", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
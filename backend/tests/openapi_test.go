@@ -0,0 +1,454 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/email"
+	"backend/internal/health"
+	"backend/internal/lifecycle"
+	"backend/internal/models"
+	"backend/internal/openapi"
+	"backend/internal/ratelimit"
+	"backend/internal/server"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+// specTypes are the Go types behind the schemas of the specification, the
+// request and response structs of the handlers
+var specTypes = map[string]reflect.Type{
+	"Admin":                 reflect.TypeOf(models.Admin{}),
+	"AdminList":             reflect.TypeOf(models.AdminList{}),
+	"EventAuthor":           reflect.TypeOf(models.EventAuthor{}),
+	"Event":                 reflect.TypeOf(models.Event{}),
+	"CreateEventRequest":    reflect.TypeOf(models.CreateEventRequest{}),
+	"EventImage":            reflect.TypeOf(models.EventImage{}),
+	"Performance":           reflect.TypeOf(models.Performance{}),
+	"Member":                reflect.TypeOf(models.Member{}),
+	"PracticeSession":       reflect.TypeOf(models.PracticeSession{}),
+	"AttendanceRecord":      reflect.TypeOf(models.AttendanceRecord{}),
+	"AttendanceSummary":     reflect.TypeOf(models.AttendanceSummary{}),
+	"InventoryItem":         reflect.TypeOf(models.InventoryItem{}),
+	"ItemCheckout":          reflect.TypeOf(models.ItemCheckout{}),
+	"CheckInRequest":        reflect.TypeOf(models.CheckInRequest{}),
+	"SubscribeRequest":      reflect.TypeOf(models.SubscribeRequest{}),
+	"ContactFormRequest":    reflect.TypeOf(models.ContactFormRequest{}),
+	"AdminIdentity":         reflect.TypeOf(models.AdminIdentity{}),
+	"APIToken":              reflect.TypeOf(models.APIToken{}),
+	"CreateAPITokenRequest": reflect.TypeOf(models.CreateAPITokenRequest{}),
+	"AdminInvitation":       reflect.TypeOf(models.AdminInvitation{}),
+	"HealthReport":          reflect.TypeOf(health.Report{}),
+	"HealthResult":          reflect.TypeOf(health.Result{}),
+}
+
+// untypedSchemas are written by handlers as maps
+var untypedSchemas = []string{"Message"}
+
+var (
+	message = reflect.TypeOf(map[string]string{})
+	object  = reflect.TypeOf(map[string]interface{}{})
+)
+
+// operationTypes are the Go types each operation decodes from its JSON request
+// body and encodes in its JSON success response, nil for none. Operations
+// without JSON bodies, pages and redirects, aren't listed.
+var operationTypes = map[string]struct{ request, response reflect.Type }{
+	"GET /":                  {nil, message},
+	"GET /api/openapi.json":  {nil, object},
+	"GET /auth/csrf-token":   {nil, message},
+	"GET /auth/session-info": {nil, object},
+	"GET /health":            {nil, reflect.TypeOf(health.Report{})},
+	"GET /health/live":       {nil, reflect.TypeOf(health.Report{})},
+	"GET /health/ready":      {nil, reflect.TypeOf(health.Report{})},
+
+	"POST /api/v1/admins":                                             {reflect.TypeOf(models.Admin{}), message},
+	"GET /api/v1/admins":                                              {nil, reflect.TypeOf(models.AdminList{})},
+	"GET /api/v1/admins/count":                                        {nil, reflect.TypeOf(map[string]int{})},
+	"GET /api/v1/admins/except-founder":                               {nil, reflect.TypeOf(models.AdminList{})},
+	"GET /api/v1/admins/{param}":                                      {nil, reflect.TypeOf(models.Admin{})},
+	"PUT /api/v1/admins/{param}":                                      {reflect.TypeOf(models.Admin{}), message},
+	"DELETE /api/v1/admins/{param}":                                   {nil, message},
+	"POST /api/v1/admins/{param}/restore":                             {nil, reflect.TypeOf(models.Admin{})},
+	"POST /api/v1/admins/invitations":                                 {reflect.TypeOf(models.AdminInvitation{}), message},
+	"GET /api/v1/admins/invitations":                                  {nil, reflect.TypeOf([]models.AdminInvitation{})},
+	"DELETE /api/v1/admins/invitations/{id}":                          {nil, message},
+	"GET /api/v1/admins/me/identities":                                {nil, reflect.TypeOf([]models.AdminIdentity{})},
+	"DELETE /api/v1/admins/me/identities/{provider}/{providerUserID}": {nil, message},
+	"POST /api/v1/admins/me/tokens":                                   {reflect.TypeOf(models.CreateAPITokenRequest{}), object},
+	"GET /api/v1/admins/me/tokens":                                    {nil, reflect.TypeOf([]models.APIToken{})},
+	"DELETE /api/v1/admins/me/tokens/{id}":                            {nil, message},
+
+	"GET /api/v1/calendar/feed-url":                 {nil, message},
+	"POST /api/v1/events/create":                    {reflect.TypeOf(models.CreateEventRequest{}), message},
+	"POST /api/v1/events/{id}/newsletter":           {nil, object},
+	"POST /api/v1/newsletter/subscribe":             {reflect.TypeOf(models.SubscribeRequest{}), message},
+	"POST /api/v1/newsletter/unsubscribe":           {nil, message},
+	"POST /api/v1/send-email":                       {reflect.TypeOf(models.ContactFormRequest{}), message},
+	"GET /api/v1/photoshoots/years":                 {nil, reflect.TypeOf([]string{})},
+	"GET /api/v1/photoshoots/events/{year}":         {nil, reflect.TypeOf([]string{})},
+	"GET /api/v1/photoshoots/list/{year}/{event}":   {nil, reflect.TypeOf([]string{})},
+	"GET /api/v1/photoshoots/photos/{year}/{event}": {nil, reflect.TypeOf([]string{})},
+
+	"POST /api/v1/inventory":               {reflect.TypeOf(models.InventoryItem{}), message},
+	"GET /api/v1/inventory":                {nil, reflect.TypeOf([]models.InventoryItem{})},
+	"GET /api/v1/inventory/overdue":        {nil, reflect.TypeOf([]models.ItemCheckout{})},
+	"GET /api/v1/inventory/{id}":           {nil, reflect.TypeOf(models.InventoryItem{})},
+	"PUT /api/v1/inventory/{id}":           {reflect.TypeOf(models.InventoryItem{}), message},
+	"DELETE /api/v1/inventory/{id}":        {nil, message},
+	"POST /api/v1/inventory/{id}/checkin":  {reflect.TypeOf(models.CheckInRequest{}), message},
+	"POST /api/v1/inventory/{id}/checkout": {reflect.TypeOf(models.ItemCheckout{}), message},
+	"GET /api/v1/inventory/{id}/checkouts": {nil, reflect.TypeOf([]models.ItemCheckout{})},
+	"POST /api/v1/inventory/{id}/photo":    {nil, message},
+	"PUT /api/v1/inventory/{id}/photo":     {nil, reflect.TypeOf(models.InventoryItem{})},
+
+	"GET /api/v1/members":         {nil, reflect.TypeOf([]models.Member{})},
+	"POST /api/v1/members":        {reflect.TypeOf(models.Member{}), message},
+	"GET /api/v1/members/all":     {nil, reflect.TypeOf([]models.Member{})},
+	"GET /api/v1/members/{id}":    {nil, reflect.TypeOf(models.Member{})},
+	"PUT /api/v1/members/{id}":    {reflect.TypeOf(models.Member{}), message},
+	"DELETE /api/v1/members/{id}": {nil, message},
+
+	"POST /api/v1/performances":        {reflect.TypeOf(models.Performance{}), message},
+	"GET /api/v1/performances":         {nil, reflect.TypeOf([]models.Performance{})},
+	"GET /api/v1/performances/{id}":    {nil, reflect.TypeOf(models.Performance{})},
+	"PUT /api/v1/performances/{id}":    {reflect.TypeOf(models.Performance{}), message},
+	"DELETE /api/v1/performances/{id}": {nil, message},
+
+	"POST /api/v1/practices":                  {reflect.TypeOf(models.PracticeSession{}), message},
+	"GET /api/v1/practices":                   {nil, reflect.TypeOf([]models.PracticeSession{})},
+	"GET /api/v1/practices/attendance-report": {nil, reflect.TypeOf([]models.AttendanceSummary{})},
+	"GET /api/v1/practices/{id}":              {nil, reflect.TypeOf(models.PracticeSession{})},
+	"PUT /api/v1/practices/{id}":              {reflect.TypeOf(models.PracticeSession{}), message},
+	"DELETE /api/v1/practices/{id}":           {nil, message},
+	"PUT /api/v1/practices/{id}/attendance":   {reflect.TypeOf([]models.AttendanceRecord{}), message},
+}
+
+func loadSpec(t *testing.T) *openapi.Document {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("parsing openapi.json: %v", err)
+	}
+	return doc
+}
+
+// newSpecRouter builds the real routes, nothing is called while walking them
+func newSpecRouter(t *testing.T) chi.Router {
+	setConfigEnv(t)
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, _ := newAppRouter(t, cfg)
+	return r
+}
+
+// newAppRouter builds the real routes on a stub database, sessions signed
+// with the returned store are accepted by them
+func newAppRouter(t *testing.T, cfg *config.Config) (chi.Router, sqlmock.Sqlmock, *sessions.CookieStore) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	dbService := database.New(db)
+
+	store := sessions.NewCookieStore([]byte("test-session-key"))
+	r := server.NewRouter(cfg, server.Services{
+		DB: dbService,
+		Auth: auth.NewAuth(&auth.AuthConfig{
+			Store:           store,
+			DB:              dbService,
+			CallbackBaseURL: "http://localhost:3000",
+			Providers:       []string{"dev"},
+		}),
+		Email:      email.NewService(cfg.Email),
+		EmailQueue: email.NewQueue(1),
+		Limiter:    ratelimit.New(cfg.RateLimit, ratelimit.NewMemoryStore(10)),
+	}, lifecycle.New())
+	return r, mock, store
+}
+
+// newConfiguredRouter builds the real routes with the test configuration
+func newConfiguredRouter(t *testing.T) (http.Handler, sqlmock.Sqlmock) {
+	setConfigEnv(t)
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, mock, _ := newAppRouter(t, cfg)
+	return r, mock
+}
+
+// tokenRequest authenticates with testAPIToken, see expectTokenLookup
+func tokenRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	return req
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// routeKey ignores trailing slashes and parameter names, chi registers
+// /api/admins as /api/admins/ and OpenAPI forbids two names for one segment
+func routeKey(method, path string) string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return strings.ToUpper(method) + " " + pathParam.ReplaceAllString(path, "{}")
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	doc := loadSpec(t)
+
+	// the deprecated unversioned routes mirror /api/v1 and aren't documented
+	var routes, unversioned []string
+	err := chi.Walk(newSpecRouter(t), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		rest, isAPI := strings.CutPrefix(route, "/api/")
+		switch {
+		case !isAPI, strings.HasPrefix(rest, "v1/"), rest == "openapi.json", rest == "docs":
+			routes = append(routes, routeKey(method, route))
+		default:
+			unversioned = append(unversioned, routeKey(method, "/api/v1/"+rest))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	var documented, v1 []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, routeKey(method, path))
+			if strings.HasPrefix(path, "/api/v1/") {
+				v1 = append(v1, routeKey(method, path))
+			}
+		}
+	}
+	assert.ElementsMatch(t, routes, documented, "routes and openapi.json paths differ")
+	assert.ElementsMatch(t, v1, unversioned, "/api and /api/v1 routes differ")
+}
+
+func TestOpenAPIOperationsAreComplete(t *testing.T) {
+	doc := loadSpec(t)
+
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			name := strings.ToUpper(method) + " " + path
+			assert.NotEmpty(t, op.Summary, name)
+			assert.NotEmpty(t, op.Responses, name)
+
+			// every path segment parameter is declared
+			var declared []string
+			for _, param := range op.Parameters {
+				if param.In == "path" {
+					declared = append(declared, "{"+param.Name+"}")
+				}
+			}
+			assert.ElementsMatch(t, pathParam.FindAllString(path, -1), declared, name)
+
+			// every referenced schema exists
+			var schemas []*openapi.Schema
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					schemas = append(schemas, media.Schema)
+				}
+			}
+			for _, response := range op.Responses {
+				for _, media := range response.Content {
+					schemas = append(schemas, media.Schema)
+				}
+			}
+			for _, schema := range schemas {
+				for schema.Items != nil {
+					schema = schema.Items
+				}
+				if ref := schema.RefName(); ref != "" {
+					assert.Contains(t, doc.Components.Schemas, ref, name)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPISchemasMatchModels(t *testing.T) {
+	doc := loadSpec(t)
+
+	for name := range doc.Components.Schemas {
+		_, typed := specTypes[name]
+		assert.True(t, typed || contains(untypedSchemas, name), "schema %s has no Go type in specTypes", name)
+	}
+
+	for name, typ := range specTypes {
+		schema, ok := doc.Components.Schemas[name]
+		if !assert.True(t, ok, "%s is missing from openapi.json", name) {
+			continue
+		}
+
+		fields := map[string]reflect.StructField{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+			fields[jsonName] = field
+		}
+
+		for jsonName, field := range fields {
+			property, ok := schema.Properties[jsonName]
+			if !assert.True(t, ok, "%s.%s is missing from the schema %s", typ.Name(), field.Name, name) {
+				continue
+			}
+			assert.NoError(t, matchSchema(field.Type, property), "%s.%s", name, jsonName)
+		}
+		for property := range schema.Properties {
+			_, ok := fields[property]
+			assert.True(t, ok, "schema %s has %s, %s doesn't", name, property, typ)
+		}
+	}
+}
+
+func TestOpenAPIOperationsMatchHandlers(t *testing.T) {
+	doc := loadSpec(t)
+
+	documented := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			name := strings.ToUpper(method) + " " + path
+			documented[name] = true
+
+			var request, response *openapi.Schema
+			if op.RequestBody != nil {
+				if media := op.RequestBody.Content["application/json"]; media != nil {
+					request = media.Schema
+				}
+			}
+			for code, r := range op.Responses {
+				if media := r.Content["application/json"]; strings.HasPrefix(code, "2") && media != nil {
+					response = media.Schema
+				}
+			}
+			if request == nil && response == nil {
+				continue
+			}
+
+			types, ok := operationTypes[name]
+			if !assert.True(t, ok, "%s has no Go types in operationTypes", name) {
+				continue
+			}
+			assert.NoError(t, matchBody(types.request, request), "%s request", name)
+			assert.NoError(t, matchBody(types.response, response), "%s response", name)
+		}
+	}
+	for name := range operationTypes {
+		assert.True(t, documented[name], "%s is missing from openapi.json", name)
+	}
+}
+
+// matchBody checks a request or response body, maps written by handlers
+// match untyped schemas and inline objects
+func matchBody(typ reflect.Type, schema *openapi.Schema) error {
+	switch {
+	case typ == nil && schema == nil:
+		return nil
+	case typ == nil:
+		return fmt.Errorf("documented as %s, the handler has no body", describeSchema(schema))
+	case schema == nil:
+		return fmt.Errorf("the handler uses a %s, it isn't documented", typ)
+	case contains(untypedSchemas, schema.RefName()), schema.Ref == "" && schema.Type == "object":
+		if typ.Kind() != reflect.Map {
+			return fmt.Errorf("%s is a %s, not a map", describeSchema(schema), typ)
+		}
+		return nil
+	}
+	return matchSchema(typ, schema)
+}
+
+func describeSchema(schema *openapi.Schema) string {
+	if ref := schema.RefName(); ref != "" {
+		return ref
+	}
+	return schema.Type
+}
+
+// matchSchema checks that values of typ are encoded the way schema says
+func matchSchema(typ reflect.Type, schema *openapi.Schema) error {
+	if ref := schema.RefName(); ref != "" {
+		if specTypes[ref] != typ {
+			return fmt.Errorf("%s is a %s, not a %s", ref, specTypes[ref], typ)
+		}
+		return nil
+	}
+
+	nullable := typ.Kind() == reflect.Pointer
+	if nullable != schema.Nullable {
+		return fmt.Errorf("%s: nullable is %v", typ, schema.Nullable)
+	}
+	if nullable {
+		typ = typ.Elem()
+	}
+
+	expected := map[reflect.Kind]string{
+		reflect.String: "string", reflect.Bool: "boolean",
+		reflect.Int: "integer", reflect.Int64: "integer",
+		reflect.Float64: "number",
+		reflect.Slice:   "array", reflect.Map: "object",
+	}[typ.Kind()]
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		if schema.Type != "string" || schema.Format != "date-time" {
+			return fmt.Errorf("time.Time must be a date-time string, not %s %s", schema.Type, schema.Format)
+		}
+	case typ.Kind() == reflect.Interface:
+		// anything goes
+	case expected == "":
+		return fmt.Errorf("%s has no schema equivalent, add a component for it", typ)
+	case schema.Type != expected:
+		return fmt.Errorf("%s must be a %s, not a %s", typ, expected, schema.Type)
+	case typ.Kind() == reflect.Slice:
+		return matchSchema(typ.Elem(), schema.Items)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	router := newSpecRouter(t)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `<span class="path">/api/v1/admins/{param}/restore</span>`)
+	assert.Contains(t, rr.Body.String(), `<h3 id="schema-Admin">Admin</h3>`)
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/ratelimit"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+var contactPolicy = config.RateLimitPolicy{Requests: 2, Window: time.Minute}
+
+func newRateLimitedRouter(cfg config.RateLimit) http.Handler {
+	limiter := ratelimit.New(cfg, ratelimit.NewMemoryStore(cfg.MaxKeys))
+	r := chi.NewRouter()
+	r.With(limiter.Limit("contact")).Post("/api/send-email", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
+func contactRequest(remoteAddr, forwardedFor string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/send-email", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return req
+}
+
+func TestRateLimitPerClient(t *testing.T) {
+	router := newRateLimitedRouter(config.RateLimit{
+		MaxKeys:  10,
+		Policies: map[string]config.RateLimitPolicy{"contact": contactPolicy},
+	})
+
+	for i, remaining := range []string{"1", "0"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, contactRequest("203.0.113.1:5000", ""))
+		assert.Equal(t, http.StatusOK, rr.Code, "request %d", i)
+		assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, remaining, rr.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", rr.Header().Get("RateLimit-Policy"))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, contactRequest("203.0.113.1:5001", ""))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	// a token is back every 30 seconds
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "60", rr.Header().Get("RateLimit-Reset"))
+
+	// another client isn't affected
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, contactRequest("198.51.100.7:5000", ""))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	router := newRateLimitedRouter(config.RateLimit{
+		MaxKeys:  10,
+		Policies: map[string]config.RateLimitPolicy{"contact": contactPolicy},
+	})
+
+	// a spammer rotating the header is still limited by its address
+	for i, forwarded := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, contactRequest("203.0.113.1:5000", forwarded))
+		if i < 2 {
+			assert.Equal(t, http.StatusOK, rr.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	for _, tc := range []struct {
+		name, remoteAddr, forwardedFor, expected string
+	}{
+		{"direct", "203.0.113.1:5000", "", "203.0.113.1"},
+		{"untrusted peer", "203.0.113.1:5000", "198.51.100.7", "203.0.113.1"},
+		{"trusted proxy", "10.0.0.2:5000", "198.51.100.7", "198.51.100.7"},
+		{"spoofed entry left of the client", "10.0.0.2:5000", "1.1.1.1, 198.51.100.7, 10.0.0.3", "198.51.100.7"},
+		{"only proxies", "10.0.0.2:5000", "10.0.0.4, 10.0.0.3", "10.0.0.4"},
+		{"garbage", "10.0.0.2:5000", "198.51.100.7, not-an-ip", "10.0.0.2"},
+		{"ipv6", "[2001:db8::1]:5000", "", "2001:db8::1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ip := ratelimit.ClientIP(contactRequest(tc.remoteAddr, tc.forwardedFor), trusted)
+			assert.Equal(t, tc.expected, ip.String())
+		})
+	}
+}
+
+func TestMemoryStoreRefillsAndEvicts(t *testing.T) {
+	store := ratelimit.NewMemoryStore(2)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		d, _ := store.Take(ctx, "a", contactPolicy, now)
+		assert.True(t, d.Allowed)
+	}
+	d, _ := store.Take(ctx, "a", contactPolicy, now)
+	assert.False(t, d.Allowed)
+	// half a window later one token is back
+	d, _ = store.Take(ctx, "a", contactPolicy, now.Add(30*time.Second))
+	assert.True(t, d.Allowed)
+
+	// b is the least recently used when c arrives, a keeps its empty bucket
+	store.Take(ctx, "b", contactPolicy, now)
+	store.Take(ctx, "a", contactPolicy, now.Add(30*time.Second))
+	store.Take(ctx, "c", contactPolicy, now)
+	assert.Equal(t, 2, store.Len())
+	d, _ = store.Take(ctx, "a", contactPolicy, now.Add(30*time.Second))
+	assert.False(t, d.Allowed)
+	d, _ = store.Take(ctx, "b", contactPolicy, now)
+	assert.Equal(t, 1, d.Remaining)
+}
+
+func TestPostgresStoreTakesToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO rate_limit_buckets`).
+		WithArgs("contact:ip:203.0.113.1", 2.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// empty 15 seconds ago, half a token refilled since
+	mock.ExpectQuery(`SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = \$1 FOR UPDATE`).
+		WithArgs("contact:ip:203.0.113.1").
+		WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(0.0, now.Add(-15*time.Second)))
+	mock.ExpectExec(`UPDATE rate_limit_buckets SET tokens`).
+		WithArgs("contact:ip:203.0.113.1", 0.5, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	cfg := config.RateLimit{Policies: map[string]config.RateLimitPolicy{"contact": contactPolicy}}
+	store := ratelimit.NewPostgresStore(database.New(db), cfg)
+	d, err := store.Take(context.Background(), "contact:ip:203.0.113.1", contactPolicy, now)
+	assert.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 15*time.Second, d.RetryAfter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoadConfigRateLimits(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("RATE_LIMITS", "contact=3/1h")
+	t.Setenv("RATE_LIMIT_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, config.RateLimitPolicy{Requests: 3, Window: time.Hour}, cfg.RateLimit.Policies["contact"])
+	// defaults are kept for policies not overridden
+	assert.Equal(t, 5, cfg.RateLimit.Policies["subscribe"].Requests)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.10/32"),
+	}, cfg.RateLimit.TrustedProxies)
+
+	t.Setenv("RATE_LIMITS", "contact=lots,signup=1/1m")
+	_, err = config.Load(nil)
+	assert.Equal(t, config.Problems{
+		`RATE_LIMITS: contact must be requests/window like 5/10m, got "lots"`,
+		`RATE_LIMITS: unknown policy "signup"`,
+	}, err)
+}
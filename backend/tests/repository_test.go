@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAdminRouter serves the admin routes from the in-memory repositories
+func newAdminRouter(repos database.Repositories) chi.Router {
+	r := chi.NewRouter()
+	r.Post("/api/admins", handlers.CreateAdminHandler(repos.Admins))
+	r.Get("/api/admins", handlers.ListAdminsHandler(repos.Admins))
+	r.Get("/api/admins/{param}", handlers.GetAdminHandler(repos.Admins))
+	r.Delete("/api/admins/{param}", handlers.DeleteAdminHandler(repos.Admins))
+	r.Post("/api/admins/{param}/restore", handlers.RestoreAdminHandler(repos.Admins))
+	r.Post("/api/events/create", handlers.CreateEventHandler(repos.Events))
+	return r
+}
+
+// signedIn serves r as if AuthMiddleware had authenticated adminID
+func signedIn(r http.Handler, adminID string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.ServeHTTP(w, req.WithContext(auth.WithAdminID(req.Context(), adminID)))
+	})
+}
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminHandlersWithMemoryRepository(t *testing.T) {
+	testAdminHandlers(t, database.NewMemory())
+}
+
+func TestAdminHandlersWithPostgres(t *testing.T) {
+	testAdminHandlers(t, database.NewRepositories(pgtest.NewDatabase(t)))
+}
+
+func testAdminHandlers(t *testing.T, repos database.Repositories) {
+	r := newAdminRouter(repos)
+	const admin = `{"name": "Mei", "email": "mei@gmail.com", "position": "Treasurer", "status": "active"}`
+
+	rec := serve(r, http.MethodPost, "/api/admins", admin)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = serve(r, http.MethodPost, "/api/admins", admin)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(r, http.MethodGet, "/api/admins/mei@gmail.com", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var got models.Admin
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "treasurer", got.Position)
+
+	rec = serve(r, http.MethodDelete, "/api/admins/jiating.lion.dragon@gmail.com", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(r, http.MethodDelete, "/api/admins/mei@gmail.com", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(r, http.MethodGet, "/api/admins/mei@gmail.com", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(r, http.MethodGet, "/api/admins?deleted=only", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "deleted admins are only listed to admins")
+	rec = serve(signedIn(r, "admin-1"), http.MethodGet, "/api/admins?deleted=only", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var list models.AdminList
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	if assert.Len(t, list.Admins, 1) {
+		assert.Equal(t, got.ID, list.Admins[0].ID)
+	}
+	assert.Equal(t, 1, list.TotalCount, "the total is of deleted admins only")
+
+	rec = serve(r, http.MethodGet, "/api/admins", "")
+	list = models.AdminList{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	assert.Len(t, list.Admins, 1)
+	assert.Equal(t, 1, list.TotalCount, "only the founder is left")
+
+	rec = serve(signedIn(r, "admin-1"), http.MethodGet, "/api/admins?deleted=include", "")
+	list = models.AdminList{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	assert.Equal(t, 2, list.TotalCount)
+
+	rec = serve(r, http.MethodPost, "/api/admins/"+got.ID+"/restore", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(r, http.MethodGet, "/api/admins/"+got.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCreateEventHandlerWithMemoryRepository(t *testing.T) {
+	repos := database.NewMemory()
+	r := newAdminRouter(repos)
+	founder, err := repos.Admins.GetAdmin(context.Background(), "email", "jiating.lion.dragon@gmail.com")
+	if err != nil {
+		t.Fatalf("getting founder: %v", err)
+	}
+
+	rec := serve(r, http.MethodPost, "/api/events/create", `{
+		"event_title": "CNY Parade", "meta_title": "CNY Parade", "slug": "cny-parade",
+		"date": "2026-02-17T18:00:00Z", "author_id": "`+founder.ID+`",
+		"images": [{"image_url": "https://bucket/parade.jpg", "alt_text": "lion", "is_display": true}]
+	}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created map[string]string
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+
+	authors, err := repos.Authors.GetEventAuthors(context.Background(), created["id"])
+	assert.NoError(t, err)
+	if assert.Len(t, authors, 1) {
+		assert.Equal(t, founder.ID, authors[0].ID)
+	}
+	images, err := repos.Images.GetEventImages(context.Background(), created["id"])
+	assert.NoError(t, err)
+	if assert.Len(t, images, 1) {
+		assert.True(t, images[0].IsDisplay)
+	}
+
+	rec = serve(r, http.MethodPost, "/api/events/create", `{"slug": "cny-parade", "author_id": "`+founder.ID+`"}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "duplicate slugs are rejected")
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"backend/internal/config"
 	"backend/internal/s3service"
 	"context"
 	"os"
@@ -14,6 +15,18 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+const testBucket = "test-bucket"
+
+// integrationS3Config points the integration tests at the bucket from the environment
+func integrationS3Config() config.S3 {
+	return config.S3{
+		Region:          os.Getenv("AWS_REGION"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Bucket:          os.Getenv("S3_BUCKET_NAME"),
+	}
+}
+
 // MockS3Client is a mock of the S3ClientAPI
 type MockS3Client struct {
 	mock.Mock
@@ -24,21 +37,26 @@ func (m *MockS3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV
 	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
 }
 
+func (m *MockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	args := m.Called(ctx, input, opts)
+	return args.Get(0).(*s3.HeadObjectOutput), args.Error(1)
+}
+
 type MockPresigner struct {
 	mock.Mock
 }
 
-func (m *MockPresigner) GetObject(bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error) {
+func (m *MockPresigner) GetObject(ctx context.Context, bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error) {
 	args := m.Called(bucketName, objectKey, lifetimeSecs)
 	return args.Get(0).(*v4.PresignedHTTPRequest), args.Error(1)
 }
 
-func (m *MockPresigner) PutObject(bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error) {
+func (m *MockPresigner) PutObject(ctx context.Context, bucketName string, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error) {
 	args := m.Called(bucketName, objectKey, lifetimeSecs)
 	return args.Get(0).(*v4.PresignedHTTPRequest), args.Error(1)
 }
 
-func (m *MockPresigner) DeleteObject(bucketName string, objectKey string) (*v4.PresignedHTTPRequest, error) {
+func (m *MockPresigner) DeleteObject(ctx context.Context, bucketName string, objectKey string) (*v4.PresignedHTTPRequest, error) {
 	args := m.Called(bucketName, objectKey)
 	return args.Get(0).(*v4.PresignedHTTPRequest), args.Error(1)
 }
@@ -47,7 +65,7 @@ func TestGetPhotoshootYears(t *testing.T) {
 	mockS3Client := new(MockS3Client)
 	mockPresigner := new(MockPresigner)
 
-	s3Service := s3service.NewMockService(mockS3Client, mockPresigner)
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
 
 	expectedYears := []string{"2020-2021", "2021-2022", "2022-2023", "2023-2024"}
 	mockS3Client.On("ListObjectsV2", mock.Anything, mock.AnythingOfType("*s3.ListObjectsV2Input"), mock.Anything).Return(&s3.ListObjectsV2Output{
@@ -72,7 +90,7 @@ func TestGetPhotoshootYearsIntegration(t *testing.T) {
 		t.Skip("Skipping integration test")
 	}
 
-	s3Service := s3service.NewService()
+	s3Service := s3service.NewService(integrationS3Config())
 
 	years, err := s3Service.GetPhotoshootYears(context.Background())
 	assert.NoError(t, err)
@@ -87,7 +105,7 @@ func TestGetPhotoshootEvents(t *testing.T) {
 	mockS3Client := new(MockS3Client)
 	mockPresigner := new(MockPresigner)
 
-	s3Service := s3service.NewMockService(mockS3Client, mockPresigner)
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
 
 	// define mock response
 	mockS3Client.On("ListObjectsV2", mock.Anything, mock.AnythingOfType("*s3.ListObjectsV2Input"), mock.Anything).Return(&s3.ListObjectsV2Output{
@@ -110,7 +128,7 @@ func TestGetPhotoshootEventsIntegration(t *testing.T) {
 		t.Skip("Skipping integration test")
 	}
 
-	s3Service := s3service.NewService()
+	s3Service := s3service.NewService(integrationS3Config())
 
 	expectedYear := "2020-2021"
 	expectedEvents := []string{"AASA 2020", "CNY 2021"}
@@ -128,7 +146,7 @@ func TestListPhotoshootPhotos(t *testing.T) {
 	mockS3Client := new(MockS3Client)
 	mockPresigner := new(MockPresigner)
 
-	s3Service := s3service.NewMockService(mockS3Client, mockPresigner)
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
 
 	mockS3Client.On("ListObjectsV2", mock.Anything, mock.AnythingOfType("*s3.ListObjectsV2Input"), mock.Anything).Return(&s3.ListObjectsV2Output{
 		Contents: []types.Object{
@@ -149,7 +167,7 @@ func TestListPhotoshootPhotosIntegration(t *testing.T) {
 		t.Skip("Skipping integration test")
 	}
 
-	s3Service := s3service.NewService()
+	s3Service := s3service.NewService(integrationS3Config())
 
 	expectedYear := "2020-2021"
 	expectedEvent := "AASA 2020"
@@ -168,11 +186,11 @@ func TestGetPhotoshootPhotos(t *testing.T) {
 	mockS3Client := new(MockS3Client)
 	mockPresigner := new(MockPresigner)
 
-	s3Service := s3service.NewMockService(mockS3Client, mockPresigner)
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
 
 	year := "2020-2021"
 	event := "AASA 2020"
-	bucket := os.Getenv("S3_BUCKET_NAME")
+	bucket := testBucket
 
 	// mock s3 response
 	mockS3Client.On("ListObjectsV2", mock.Anything, mock.AnythingOfType("*s3.ListObjectsV2Input"), mock.Anything).Return(&s3.ListObjectsV2Output{
@@ -200,7 +218,7 @@ func TestGetPhotoshootPhotosIntegration(t *testing.T) {
 		t.Skip("Skipping integration test")
 	}
 
-	s3Service := s3service.NewService()
+	s3Service := s3service.NewService(integrationS3Config())
 
 	expectedYear := "2020-2021"
 	expectedEvent := "AASA 2020"
@@ -220,11 +238,11 @@ func TestGenerateEventImageUploadURL(t *testing.T) {
 	mockS3Client := new(MockS3Client)
 	mockPresigner := new(MockPresigner)
 
-	s3Service := s3service.NewMockService(mockS3Client, mockPresigner)
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
 
 	eventID := "123"
 	filename := "photo.jpg"
-	bucket := os.Getenv("S3_BUCKET_NAME")
+	bucket := testBucket
 	objectKey := "events/123/photo.jpg"
 	lifetimeSecs := int64(900)
 
@@ -234,7 +252,7 @@ func TestGenerateEventImageUploadURL(t *testing.T) {
 	mockPresigner.On("PutObject", bucket, objectKey, lifetimeSecs).Return(&v4.PresignedHTTPRequest{URL: expectedURL}, nil)
 
 	// call function to test
-	url, err := s3Service.GenerateEventImageUploadURL(eventID, filename, lifetimeSecs)
+	url, err := s3Service.GenerateEventImageUploadURL(context.Background(), eventID, filename, lifetimeSecs)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedURL, url)
 
@@ -246,13 +264,13 @@ func TestGenerateEventImageUploadURLIntegration(t *testing.T) {
 		t.Skip("Skipping integration test")
 	}
 
-	s3Service := s3service.NewService()
+	s3Service := s3service.NewService(integrationS3Config())
 
 	expectedEventID := "123"
 	expectedFilename := "photo.jpg"
 	expectedLifetimeSecs := int64(900)
 
-	url, err := s3Service.GenerateEventImageUploadURL(expectedEventID, expectedFilename, expectedLifetimeSecs)
+	url, err := s3Service.GenerateEventImageUploadURL(context.Background(), expectedEventID, expectedFilename, expectedLifetimeSecs)
 	assert.NoError(t, err)
 
 	// checking if url is not empty
@@ -260,3 +278,29 @@ func TestGenerateEventImageUploadURLIntegration(t *testing.T) {
 	// checking values
 	assert.Contains(t, url, "https://")
 }
+
+func TestGenerateInventoryPhotoUploadURL(t *testing.T) {
+	mockS3Client := new(MockS3Client)
+	mockPresigner := new(MockPresigner)
+
+	s3Service := s3service.NewMockService(mockS3Client, mockPresigner, testBucket)
+
+	itemID := "item-123"
+	filename := "head.jpg"
+	bucket := testBucket
+	objectKey := "inventory/item-123/head.jpg"
+	lifetimeSecs := int64(900)
+
+	expectedURL := "https://presigned.url/head.jpg"
+
+	// mock presigner response
+	mockPresigner.On("PutObject", bucket, objectKey, lifetimeSecs).Return(&v4.PresignedHTTPRequest{URL: expectedURL}, nil)
+
+	// call function to test
+	url, err := s3Service.GenerateInventoryPhotoUploadURL(context.Background(), itemID, filename, lifetimeSecs)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedURL, url)
+	assert.Equal(t, objectKey, s3service.InventoryPhotoKey(itemID, filename))
+
+	mockPresigner.AssertExpectations(t)
+}
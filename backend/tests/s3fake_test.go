@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"backend/internal/s3service"
+	"backend/internal/s3service/s3fake"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeService points s3service at a fake holding the given objects
+func fakeService(t *testing.T, keys ...string) (*s3fake.Server, s3service.Service) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	for _, key := range keys {
+		fake.PutObject(testBucket, key, []byte("photo of "+key))
+	}
+	return fake, s3service.NewService(cfg)
+}
+
+func httpDo(t *testing.T, method, target string, body []byte) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func TestFakeS3PhotoshootListings(t *testing.T) {
+	fake, svc := fakeService(t,
+		"photoshoots/2023/cny/a.jpg",
+		"photoshoots/2024/cny/a.jpg",
+		"photoshoots/2024/cny/b.jpg",
+		"photoshoots/2024/cny/raw/b.cr2",
+		"photoshoots/2024/moon festival/c.jpg",
+		"photoshoots/2024/notes.txt",
+		"photoshoots/readme.txt",
+		"events/1/cover.jpg",
+	)
+	// pages of two make every listing below span several requests
+	fake.MaxKeys = 2
+	ctx := context.Background()
+
+	years, err := svc.GetPhotoshootYears(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2023", "2024"}, years)
+
+	events, err := svc.GetPhotoshootEvents(ctx, "2024")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cny", "moon festival"}, events)
+
+	photos, err := svc.ListPhotoshootPhotos(ctx, "2024", "cny")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.jpg", "b.jpg"}, photos, "nested folders aren't photos")
+
+	urls, err := svc.GetPhotoshootPhotos(ctx, "2024", "moon festival")
+	assert.NoError(t, err)
+	if assert.Len(t, urls, 1) {
+		status, body := httpDo(t, http.MethodGet, urls[0], nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "photo of photoshoots/2024/moon festival/c.jpg", body)
+	}
+}
+
+func TestFakeS3ListingPagination(t *testing.T) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	for i := 0; i < 25; i++ {
+		fake.PutObject(testBucket, fmt.Sprintf("photoshoots/%d/event/photo.jpg", 2000+i), nil)
+	}
+	for i := 0; i < 5; i++ {
+		fake.PutObject(testBucket, fmt.Sprintf("photoshoots/%d.jpg", i), nil)
+	}
+	client, err := s3service.NewClient(cfg)
+	assert.NoError(t, err)
+
+	var pages int
+	var prefixes, keys []string
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(testBucket),
+		Prefix:    aws.String("photoshoots/"),
+		Delimiter: aws.String("/"),
+		MaxKeys:   aws.Int32(7),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+		pages++
+		assert.LessOrEqual(t, len(page.CommonPrefixes)+len(page.Contents), 7)
+		for _, p := range page.CommonPrefixes {
+			prefixes = append(prefixes, *p.Prefix)
+		}
+		for _, c := range page.Contents {
+			keys = append(keys, *c.Key)
+		}
+	}
+	assert.Equal(t, 5, pages)
+	assert.Len(t, prefixes, 25)
+	assert.Equal(t, "photoshoots/2000/", prefixes[0])
+	assert.Equal(t, "photoshoots/2024/", prefixes[24])
+	assert.Equal(t, []string{"photoshoots/0.jpg", "photoshoots/1.jpg", "photoshoots/2.jpg", "photoshoots/3.jpg", "photoshoots/4.jpg"}, keys)
+}
+
+func TestFakeS3PresignedUpload(t *testing.T) {
+	fake, svc := fakeService(t)
+
+	target, err := svc.GenerateEventImageUploadURL(context.Background(), "42", "lion dance+1.jpg", 60)
+	assert.NoError(t, err)
+	status, _ := httpDo(t, http.MethodPut, target, []byte("jpeg bytes"))
+	assert.Equal(t, http.StatusOK, status)
+	data, ok := fake.Object(testBucket, "events/42/lion dance+1.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, "jpeg bytes", string(data))
+
+	target, err = svc.GetInventoryPhotoURL(context.Background(), "events/42/lion dance+1.jpg", 60)
+	assert.NoError(t, err)
+	status, body := httpDo(t, http.MethodGet, target, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "jpeg bytes", body)
+
+	// a url signed for one key can't be used for another
+	u, _ := url.Parse(target)
+	u.Path = "/" + testBucket + "/events/42/other.jpg"
+	status, body = httpDo(t, http.MethodGet, u.String(), nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "SignatureDoesNotMatch")
+
+	// nor for another method
+	status, _ = httpDo(t, http.MethodPut, target, []byte("overwritten"))
+	assert.Equal(t, http.StatusForbidden, status)
+	data, _ = fake.Object(testBucket, "events/42/lion dance+1.jpg")
+	assert.Equal(t, "jpeg bytes", string(data))
+}
+
+func TestFakeS3RejectsBadSignatures(t *testing.T) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	fake.PutObject(testBucket, "events/1/cover.jpg", []byte("cover"))
+	svc := s3service.NewService(cfg)
+	target, err := svc.GetPresignedURL(context.Background(), testBucket, "events/1/cover.jpg", 60)
+	assert.NoError(t, err)
+
+	tampered := strings.Replace(target, "X-Amz-Expires=60", "X-Amz-Expires=600", 1)
+	status, body := httpDo(t, http.MethodGet, tampered, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "SignatureDoesNotMatch")
+
+	unsigned, _, _ := strings.Cut(target, "?")
+	status, body = httpDo(t, http.MethodGet, unsigned, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "AccessDenied")
+
+	cfg.SecretAccessKey = "wrong"
+	forged, err := s3service.NewService(cfg).GetPresignedURL(context.Background(), testBucket, "events/1/cover.jpg", 60)
+	assert.NoError(t, err)
+	status, body = httpDo(t, http.MethodGet, forged, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "SignatureDoesNotMatch")
+
+	fake.Now = func() time.Time { return time.Now().Add(61 * time.Second) }
+	status, body = httpDo(t, http.MethodGet, target, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "Request has expired")
+}
+
+func TestFakeS3ObjectLifecycle(t *testing.T) {
+	fake, cfg := s3fake.Start(t, testBucket)
+	client, err := s3service.NewClient(cfg)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(testBucket),
+		Key:         aws.String("inventory/drum/front.png"),
+		Body:        bytes.NewReader([]byte("png")),
+		ContentType: aws.String("image/png"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"inventory/drum/front.png"}, fake.Keys(testBucket))
+
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("inventory/drum/front.png")})
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(out.Body)
+		out.Body.Close()
+		assert.Equal(t, "png", string(data))
+		assert.Equal(t, "image/png", *out.ContentType)
+	}
+
+	_, err = client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(testBucket), Key: aws.String("inventory/drum/front.png")})
+	assert.NoError(t, err)
+	_, err = client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("inventory/drum/front.png")})
+	var noSuchKey *types.NoSuchKey
+	assert.True(t, errors.As(err, &noSuchKey), "got %v", err)
+
+	assert.NoError(t, s3service.NewService(cfg).Ping(ctx))
+	cfg.Bucket = "missing"
+	assert.Error(t, s3service.NewService(cfg).Ping(ctx))
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"backend/internal/database"
+	"backend/internal/database/pgtest"
+	"backend/internal/s3service"
+	"backend/internal/s3service/s3fake"
+	"backend/misc"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fixturesFile = "../misc/testdata/fixtures.json"
+
+func loadFixtures(t *testing.T) *misc.Fixtures {
+	t.Helper()
+	fixtures, err := misc.LoadFixtures(fixturesFile)
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	return fixtures
+}
+
+func TestFixturesAreGeneratedFromTheDefaultSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	assert.NoError(t, misc.Generate(misc.DefaultOptions()).WriteFile(path))
+	generated, err := misc.LoadFixtures(path)
+	assert.NoError(t, err)
+	assert.Equal(t, loadFixtures(t), generated, "regenerate with go run ./cmd/seed -out misc/testdata/fixtures.json")
+
+	opts := misc.DefaultOptions()
+	opts.Seed = 2
+	assert.NotEqual(t, generated.Admins, misc.Generate(opts).Admins)
+}
+
+func TestApplyFixturesToMemoryRepository(t *testing.T) {
+	testApplyFixtures(t, database.NewMemory())
+}
+
+func TestApplyFixturesToPostgres(t *testing.T) {
+	db := pgtest.NewDatabase(t)
+	repos := database.NewRepositories(db)
+	testApplyFixtures(t, repos)
+
+	// wiping leaves the founder, so the same fixtures apply again
+	assert.NoError(t, database.Wipe(context.Background(), db))
+	count, err := repos.Admins.GetAdminCount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, loadFixtures(t).Apply(context.Background(), repos))
+}
+
+func testApplyFixtures(t *testing.T, repos database.Repositories) {
+	ctx := context.Background()
+	fixtures := loadFixtures(t)
+	if !assert.NoError(t, fixtures.Apply(ctx, repos)) {
+		return
+	}
+
+	count, err := repos.Admins.GetAdminCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fixtures.Admins)+1, count)
+
+	for _, want := range fixtures.Events {
+		event, err := repos.Events.GetEventByID(ctx, want.ID)
+		if !assert.NoError(t, err, want.Slug) {
+			continue
+		}
+		assert.Equal(t, want.Slug, event.Slug)
+
+		authors, err := repos.Authors.GetEventAuthors(ctx, want.ID)
+		assert.NoError(t, err)
+		assert.Len(t, authors, len(want.AuthorEmails), want.Slug)
+
+		images, err := repos.Images.GetEventImages(ctx, want.ID)
+		assert.NoError(t, err)
+		assert.Len(t, images, len(want.Images), want.Slug)
+		for _, image := range images {
+			assert.Equal(t, image.ID == want.Images[0].ID, image.IsDisplay, "the first image is displayed")
+		}
+	}
+
+	// the same fixtures can't be applied twice without wiping
+	assert.Error(t, fixtures.Apply(ctx, repos))
+}
+
+func TestUploadFixturePhotoshoots(t *testing.T) {
+	ctx := context.Background()
+	fixtures := loadFixtures(t)
+	fake, cfg := s3fake.Start(t, testBucket)
+	client, err := s3service.NewClient(cfg)
+	assert.NoError(t, err)
+	fake.PutObject(testBucket, "events/1/cover.jpg", nil)
+
+	assert.NoError(t, fixtures.Upload(ctx, client, testBucket))
+	years, err := s3service.NewService(cfg).GetPhotoshootYears(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, years)
+	assert.Len(t, fake.Keys(testBucket), len(fixtures.Photoshoots)+1)
+
+	deleted, err := misc.WipePhotoshoots(ctx, client, testBucket)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fixtures.Photoshoots), deleted)
+	assert.Equal(t, []string{"events/1/cover.jpg"}, fake.Keys(testBucket), "only photoshoots are wiped")
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/s3service"
+	"backend/internal/s3service/s3fake"
+	"backend/internal/tracing"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var testTracing = config.Tracing{SampleRatio: 1, ServiceName: "jiating-api-test"}
+
+// keptSpans keeps the exported spans on shutdown, the in-memory exporter
+// would reset them
+type keptSpans struct{ *tracetest.InMemoryExporter }
+
+func (keptSpans) Shutdown(context.Context) error { return nil }
+
+// resetTracing restores the no-op tracer provider after the test
+func resetTracing(t *testing.T) {
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+}
+
+func TestTracingNestsDatabaseSpansUnderRoute(t *testing.T) {
+	resetTracing(t)
+	// Setup installs the propagator, the in-memory provider then replaces its own
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "none"})
+	assert.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown := tracing.Install(keptSpans{exporter}, testTracing)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`SELECT MAX\(version\)`).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+	s := database.New(db)
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/api/schema/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.GetSchemaVersion(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/schema/1", nil)
+	// continue the trace of the caller
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.NoError(t, shutdown(context.Background()))
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		dbSpan, routeSpan := spans[0], spans[1]
+		assert.Equal(t, "database.GetSchemaVersion", dbSpan.Name)
+		assert.Equal(t, "GET /api/schema/{id}", routeSpan.Name)
+		assert.Equal(t, routeSpan.SpanContext.SpanID(), dbSpan.Parent.SpanID())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", routeSpan.SpanContext.TraceID().String())
+	}
+}
+
+func TestTracingWithoutExporterStillAssignsTraceIDs(t *testing.T) {
+	resetTracing(t)
+	cfg := testTracing
+	cfg.Exporter = "none"
+	shutdown, err := tracing.Setup(context.Background(), cfg)
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	ctx, span := tracing.Start(context.Background(), "s3service.Ping")
+	defer span.End()
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid(), "log lines carry a trace id")
+}
+
+func TestTracingSpansPresignedURLs(t *testing.T) {
+	resetTracing(t)
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown := tracing.Install(keptSpans{exporter}, testTracing)
+
+	_, cfg := s3fake.Start(t, testBucket)
+	service := s3service.NewService(cfg)
+	ctx, route := tracing.Start(context.Background(), "GET /api/inventory/{id}")
+	_, err := service.GetInventoryPhotoURL(ctx, "inventory/item-1/photo.jpg", 60)
+	assert.NoError(t, err)
+	route.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "s3service.GetInventoryPhotoURL", spans[0].Name)
+		assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	}
+}
+
+func TestTracingExportsToOTLPCollector(t *testing.T) {
+	resetTracing(t)
+
+	// stands in for an OpenTelemetry collector
+	var mu sync.Mutex
+	var paths []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path+" "+r.Header.Get("Content-Type"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	cfg := testTracing
+	cfg.Exporter = "otlp"
+	cfg.OTLPEndpoint = collector.URL
+	shutdown, err := tracing.Setup(context.Background(), cfg)
+	assert.NoError(t, err)
+
+	_, span := tracing.Start(context.Background(), "s3service.GetPhotoshootYears")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"/v1/traces application/x-protobuf"}, paths)
+}
+
+func TestTracingRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
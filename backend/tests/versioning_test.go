@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"backend/internal/versioning"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecatedRouteHeaders(t *testing.T) {
+	deprecation := versioning.Deprecation{
+		Since:     time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+		Successor: versioning.ReplacePrefix("/api/", "/api/v1/"),
+	}
+	r := chi.NewRouter()
+	r.With(deprecation.Middleware).Get("/api/members", func(w http.ResponseWriter, r *http.Request) {})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/members", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "@1792368000", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/members>; rel="successor-version"`, rr.Header().Get("Link"))
+}
+
+func TestUnversionedRoutesAreDeprecated(t *testing.T) {
+	router := newSpecRouter(t)
+
+	// both reject the anonymous request the same way
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/calendar/feed-url", nil))
+	legacy := rr.Result()
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/calendar/feed-url", nil))
+	current := rr.Result()
+
+	assert.Equal(t, current.StatusCode, legacy.StatusCode)
+	assert.NotEmpty(t, legacy.Header.Get("Sunset"))
+	assert.Equal(t, `</api/v1/calendar/feed-url>; rel="successor-version"`, legacy.Header.Get("Link"))
+	assert.Empty(t, current.Header.Get("Deprecation"))
+	assert.Empty(t, current.Header.Get("Sunset"))
+}
+
+func TestTrimVersion(t *testing.T) {
+	assert.Equal(t, "events/1", versioning.TrimVersion("v1/events/1"))
+	assert.Equal(t, "events/1", versioning.TrimVersion("v12/events/1"))
+	assert.Equal(t, "events/1", versioning.TrimVersion("events/1"))
+	assert.Equal(t, "vip/1", versioning.TrimVersion("vip/1"))
+}
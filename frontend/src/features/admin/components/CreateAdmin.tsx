@@ -1,6 +1,7 @@
 import React, { useState } from 'react';
 import { Button } from '../../../components';
 import { styles } from '../../../styles';
+import { getCsrfHeaders } from '../../../services/authService';
 
 type Admin = {
   name: string;
@@ -57,6 +58,7 @@ function CreateAdmin() {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          ...(await getCsrfHeaders()),
         },
         body: JSON.stringify(admin),
         credentials: 'include',
@@ -1,5 +1,6 @@
 import React, { useState } from 'react';
 import { Button, Modal } from '../../../components';
+import { getCsrfHeaders } from '../../../services/authService';
 
 function DeleteAdmin() {
   const [email, setEmail] = useState('');
@@ -31,6 +32,7 @@ function DeleteAdmin() {
     try {
       const response = await fetch(`http://localhost:3000/api/admins/${encodedEmail}`, {
         method: 'DELETE',
+        headers: await getCsrfHeaders(),
         credentials: 'include',
       });
 
@@ -10,4 +10,18 @@ export const logoutGoogleUser = async () => {
 export const loginGoogleUser = async () => {
     // redirect to google login page
     window.location.href = 'http://localhost:3000/auth/google'
-}
\ No newline at end of file
+}
+// the backend rejects POST/PUT/DELETE made with the session cookie unless
+// they carry the session's csrf token in the X-CSRF-Token header
+let csrfToken: string | null = null;
+
+export const getCsrfHeaders = async (): Promise<Record<string, string>> => {
+    if (!csrfToken) {
+        const resp = await fetch('http://localhost:3000/auth/csrf-token', { credentials: 'include' });
+        if (!resp.ok) {
+            throw new Error('Failed to get csrf token');
+        }
+        csrfToken = (await resp.json()).csrfToken;
+    }
+    return { 'X-CSRF-Token': csrfToken as string };
+}
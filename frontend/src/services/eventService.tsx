@@ -1,3 +1,4 @@
+import { getCsrfHeaders } from './authService';
 import {
   CreateEventRequest,
   EventData,
@@ -48,7 +49,7 @@ const createEvent = async (
 
     const resp = await fetch('http://localhost:3000/api/event/create', {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', ...(await getCsrfHeaders()) },
       credentials: 'include',
       body: JSON.stringify(createData),
     });
@@ -86,7 +87,7 @@ const updateEvent = async (
       `http://localhost:3000/api/event/update/${eventData.id}`,
       {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...(await getCsrfHeaders()) },
         credentials: 'include',
         body: JSON.stringify(updateData),
       },